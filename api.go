@@ -1,37 +1,28 @@
 package main
 
 import (
-	"bytes"
-	"database/sql"
-	"encoding/gob"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
-	"os"
+	"strconv"
+	"time"
 
+	"github.com/jheredos/langgen/lexicon"
+	"github.com/jheredos/langgen/morphology"
 	"github.com/jheredos/langgen/phonology"
 	"github.com/jheredos/langgen/phonotactics"
+	"github.com/jheredos/langgen/presets"
+	"github.com/jheredos/langgen/storage"
+	"github.com/jheredos/langgen/typology"
 	"github.com/julienschmidt/httprouter"
-	_ "github.com/lib/pq"
 	uuid "github.com/satori/go.uuid"
 )
 
-// Pool represents the database connection pool
-var Pool *sql.DB
-
-// ConnectToDB establishes a new connection with the database
-func ConnectToDB() func() {
-	uri := os.Getenv("DATABASE_URL")
-	var err error
-	Pool, err = sql.Open("postgres", uri) // using := causes a null pointer error for Pool???
-
-	if err != nil {
-		panic(err)
-	}
-
-	return func() {
-		Pool.Close()
-	}
+// API holds the dependencies of the handlers that read and write languages,
+// so that they can run against any storage backend
+type API struct {
+	Store storage.LanguageStore
 }
 
 // GetLanguageID checks the user's cookies to see if they have an already
@@ -63,7 +54,7 @@ func CreateNewLanguage(w http.ResponseWriter, r *http.Request, _ httprouter.Para
 
 	data, err := json.Marshal(id)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, internalError(err))
 		return
 	}
 
@@ -85,69 +76,78 @@ func Ping(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
 	w.Write(data)
 }
 
-// UnmarshalBinary decodes a gob ([]byte) into the data type provided in the second arg, which must be a pointer
-func UnmarshalBinary(source []byte, destination interface{}) error {
-	buf := bytes.NewBuffer(source)
-	return gob.NewDecoder(buf).Decode(destination)
-}
-
-// MarshalBinary encodes the value in source to gob
-func MarshalBinary(source interface{}) ([]byte, error) {
-	buf := bytes.Buffer{}
-	err := gob.NewEncoder(&buf).Encode(source)
-	return buf.Bytes(), err
-}
-
 // GetInventory ...
-func GetInventory(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+func (api *API) GetInventory(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
 	fmt.Println("getInventory")
 
 	id := ps.ByName("id")
-	row := Pool.QueryRow(`SELECT consonants, vowels FROM languages WHERE lang_id=$1`, id)
-	if row.Err() == sql.ErrNoRows {
-		http.Error(w, fmt.Sprintf("No language with id \"%s\" found.", id), http.StatusBadRequest)
+	inv, err := api.Store.GetInventory(id)
+	if err != nil {
+		writeError(w, storeError(err, id))
 		return
 	}
-
-	csb, vsb := []byte{}, []byte{}
-	err := row.Scan(&csb, &vsb)
+	h, err := api.Store.GetHierarchies(id)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, storeError(err, id))
 		return
 	}
 
-	cs, vs := []phonology.Consonant{}, []phonology.Vowel{}
-
-	err = UnmarshalBinary(csb, &cs)
+	data, err := json.Marshal(&struct {
+		Consonants  []phonology.Consonant     `json:"consonants"`
+		Vowels      []phonology.Vowel         `json:"vowels"`
+		Diphthongs  []phonology.Diphthong     `json:"diphthongs"`
+		Segments    []phonology.CustomSegment `json:"segments"`
+		Consistency storage.ConsistencyReport `json:"consistency"`
+		Typology    typology.Report           `json:"typology"`
+	}{
+		Consonants:  inv.Consonants,
+		Vowels:      inv.Vowels,
+		Diphthongs:  inv.Diphthongs,
+		Segments:    inv.Segments,
+		Consistency: storage.CheckConsistency(inv, h),
+		Typology:    typology.Analyze(inv),
+	})
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, internalError(err))
 		return
 	}
 
-	err = UnmarshalBinary(vsb, &vs)
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
+
+// GetInventoryChart renders the language's inventory as an IPA chart, in the format given by
+// the format query parameter: "svg", the default, "html", or "markdown"
+func (api *API) GetInventoryChart(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	fmt.Println("GetInventoryChart")
+
+	id := ps.ByName("id")
+	inv, err := api.Store.GetInventory(id)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, storeError(err, id))
 		return
 	}
 
-	data, err := json.Marshal(&struct {
-		Consonants []phonology.Consonant `json:"consonants"`
-		Vowels     []phonology.Vowel     `json:"vowels"`
-	}{
-		Consonants: cs,
-		Vowels:     vs,
-	})
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+	chart := phonology.NewChart(inv)
+	var contentType, body string
+	switch format := r.URL.Query().Get("format"); format {
+	case "", "svg":
+		contentType, body = "image/svg+xml; charset=utf-8", chart.SVG()
+	case "html":
+		contentType, body = "text/html; charset=utf-8", chart.HTML()
+	case "markdown", "md":
+		contentType, body = "text/markdown; charset=utf-8", chart.Markdown()
+	default:
+		writeError(w, invalidInput(fmt.Errorf("unknown chart format %q; use svg, html, or markdown", format)))
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.Write(data)
+	w.Header().Set("Content-Type", contentType)
+	w.Write([]byte(body))
 }
 
 // UpdateConsonantInventory ...
-func UpdateConsonantInventory(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+func (api *API) UpdateConsonantInventory(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
 	fmt.Println("UpdateConsonantInventory")
 
 	var reqData struct {
@@ -157,27 +157,25 @@ func UpdateConsonantInventory(w http.ResponseWriter, r *http.Request, _ httprout
 
 	err := json.NewDecoder(r.Body).Decode(&reqData)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, malformedRequest(err))
 		return
 	}
 	cs := reqData.Data
 	id := reqData.ID
 
-	bs, err := MarshalBinary(cs)
-
-	stmt := `INSERT INTO languages (lang_id, consonants) VALUES ($1, $2) ON CONFLICT (lang_id) DO UPDATE SET consonants=$2 WHERE languages.lang_id=$1;`
-	_, err = Pool.Exec(stmt, id, bs)
+	rev, err := api.Store.Revise(id, "update consonant inventory", func(lang *storage.Language) { lang.SetConsonants(cs) })
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, storeError(err, id))
+		return
 	}
 
-	res, _ := json.Marshal(fmt.Sprintf("Successfully updated consonant inventory for language %s", id))
+	res, _ := json.Marshal(fmt.Sprintf("Successfully updated consonant inventory for language %s (revision %d)", id, rev.Number))
 	w.Header().Set("Content-Type", "application/json")
 	w.Write(res)
 }
 
 // UpdateVowelInventory ...
-func UpdateVowelInventory(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+func (api *API) UpdateVowelInventory(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
 	fmt.Println("UpdateVowelInventory")
 
 	var reqData struct {
@@ -187,27 +185,184 @@ func UpdateVowelInventory(w http.ResponseWriter, r *http.Request, _ httprouter.P
 
 	err := json.NewDecoder(r.Body).Decode(&reqData)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, malformedRequest(err))
 		return
 	}
 	vs := reqData.Data
 	id := reqData.ID
 
-	bs, err := MarshalBinary(vs)
+	rev, err := api.Store.Revise(id, "update vowel inventory", func(lang *storage.Language) { lang.SetVowels(vs) })
+	if err != nil {
+		writeError(w, storeError(err, id))
+		return
+	}
+
+	res, _ := json.Marshal(fmt.Sprintf("Successfully updated vowel inventory for language %s (revision %d)", id, rev.Number))
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(res)
+}
+
+// UpdateDiphthongInventory replaces the diphthongs and triphthongs the language treats
+// as single phonemes
+func (api *API) UpdateDiphthongInventory(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	fmt.Println("UpdateDiphthongInventory")
+
+	var reqData struct {
+		ID   string                `json:"id"`
+		Data []phonology.Diphthong `json:"data"`
+	}
+
+	err := json.NewDecoder(r.Body).Decode(&reqData)
+	if err != nil {
+		writeError(w, malformedRequest(err))
+		return
+	}
+	ds := reqData.Data
+	id := reqData.ID
+
+	for _, d := range ds {
+		if d.Nucleus == (phonology.Vowel{}) {
+			writeError(w, invalidInput(errors.New("every diphthong needs a nucleus")))
+			return
+		}
+	}
+
+	rev, err := api.Store.Revise(id, "update diphthong inventory", func(lang *storage.Language) { lang.SetDiphthongs(ds) })
+	if err != nil {
+		writeError(w, storeError(err, id))
+		return
+	}
+
+	res, _ := json.Marshal(fmt.Sprintf("Successfully updated diphthong inventory for language %s (revision %d)", id, rev.Number))
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(res)
+}
+
+// UpdateSegmentInventory replaces the language's custom segments
+func (api *API) UpdateSegmentInventory(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	fmt.Println("UpdateSegmentInventory")
+
+	var reqData struct {
+		ID   string                    `json:"id"`
+		Data []phonology.CustomSegment `json:"data"`
+	}
+
+	err := json.NewDecoder(r.Body).Decode(&reqData)
+	if err != nil {
+		writeError(w, malformedRequest(err))
+		return
+	}
+	ss := reqData.Data
+	id := reqData.ID
+
+	symbols := map[string]bool{}
+	for _, s := range ss {
+		if err := s.Validate(); err != nil {
+			writeError(w, invalidInput(err))
+			return
+		}
+		if symbols[s.Symbol] {
+			writeError(w, invalidInput(fmt.Errorf("custom segment symbol \"%s\" is used twice", s.Symbol)))
+			return
+		}
+		symbols[s.Symbol] = true
+	}
 
-	stmt := `INSERT INTO languages (lang_id, vowels) VALUES ($1, $2) ON CONFLICT (lang_id) DO UPDATE SET vowels=$2 WHERE languages.lang_id=$1;`
-	_, err = Pool.Exec(stmt, id, bs)
+	rev, err := api.Store.Revise(id, "update custom segments", func(lang *storage.Language) { lang.SetSegments(ss) })
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, storeError(err, id))
+		return
 	}
 
-	res, _ := json.Marshal(fmt.Sprintf("Successfully updated vowel inventory for language %s", id))
+	res, _ := json.Marshal(fmt.Sprintf("Successfully updated custom segments for language %s (revision %d)", id, rev.Number))
 	w.Header().Set("Content-Type", "application/json")
 	w.Write(res)
 }
 
+// GetNaturalClass converts between a natural class of the language's inventory and the
+// feature bundle picking it out. Given phonemes, it returns the minimal bundle specifying
+// them, and given a bundle like "[+voice -son]", it returns the phonemes it picks out
+func (api *API) GetNaturalClass(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	fmt.Println("GetNaturalClass")
+
+	var reqData struct {
+		ID   string `json:"id"`
+		Data struct {
+			Phonemes []string `json:"phonemes"`
+			Features string   `json:"features"`
+		} `json:"data"`
+	}
+
+	err := json.NewDecoder(r.Body).Decode(&reqData)
+	if err != nil {
+		writeError(w, malformedRequest(err))
+		return
+	}
+	id := reqData.ID
+
+	inv, err := api.Store.GetInventory(id)
+	if err != nil {
+		writeError(w, storeError(err, id))
+		return
+	}
+	inventory := inv.Phonemes()
+
+	var bundle phonology.FeatureBundle
+	if reqData.Data.Features != "" {
+		bundle, err = phonology.ParseFeatureBundle(reqData.Data.Features)
+		if err != nil {
+			writeError(w, invalidInput(err))
+			return
+		}
+	} else {
+		// phonemes are looked up by IPA as given, so that diphthongs and custom segments can be
+		// found, and otherwise as parsed
+		byIPA := map[string]phonology.Phoneme{}
+		for _, p := range inventory {
+			byIPA[p.ToIPA()] = p
+		}
+		class := []phonology.Phoneme{}
+		for _, ipa := range reqData.Data.Phonemes {
+			p, found := byIPA[ipa]
+			if word, err := phonology.ParseWord(ipa); !found && err == nil && len(word) == 1 {
+				p, found = byIPA[word[0].ToIPA()]
+			}
+			if !found {
+				writeError(w, invalidInput(fmt.Errorf("/%s/ is not in the inventory", ipa)))
+				return
+			}
+			class = append(class, p)
+		}
+		bundle, err = phonology.MinimalSpecification(class, inventory)
+		if err != nil {
+			writeError(w, invalidInput(err))
+			return
+		}
+	}
+
+	members := []string{}
+	for _, p := range bundle.Select(inventory) {
+		members = append(members, p.ToIPA())
+	}
+
+	data, err := json.Marshal(&struct {
+		Features string   `json:"features"`
+		Phonemes []string `json:"phonemes"`
+	}{
+		Features: bundle.String(),
+		Phonemes: members,
+	})
+	if err != nil {
+		writeError(w, internalError(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
+
 // UpdateConsonantHierarchy ...
-func UpdateConsonantHierarchy(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+func (api *API) UpdateConsonantHierarchy(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
 	fmt.Println("UpdateConsonantHierarchy")
 	var reqData struct {
 		ID   string                          `json:"id"`
@@ -215,35 +370,35 @@ func UpdateConsonantHierarchy(w http.ResponseWriter, r *http.Request, _ httprout
 	}
 	err := json.NewDecoder(r.Body).Decode(&reqData)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, malformedRequest(err))
 		return
 	}
 	ch := reqData.Data
 	id := reqData.ID
 
-	bs, err := MarshalBinary(ch)
-
-	stmt := `INSERT INTO languages (lang_id, onset_clusters) VALUES ($1, $2) ON CONFLICT (lang_id) DO UPDATE SET onset_clusters=$2 WHERE languages.lang_id=$1;`
-	if !ch.Onset {
-		stmt = `INSERT INTO languages (lang_id, coda_clusters) VALUES ($1, $2) ON CONFLICT (lang_id) DO UPDATE SET coda_clusters=$2 WHERE languages.lang_id=$1;`
-
+	summary := "update coda hierarchy"
+	if ch.Onset {
+		summary = "update onset hierarchy"
 	}
-	result, err := Pool.Exec(stmt, id, bs)
-	affected, _ := result.RowsAffected()
+	rev, err := api.Store.Revise(id, summary, func(lang *storage.Language) {
+		if ch.Onset {
+			lang.Onsets = ch
+		} else {
+			lang.Codas = ch
+		}
+	})
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-	}
-	if affected == 0 {
-		http.Error(w, "Failed to update consonant clusters", http.StatusInternalServerError)
+		writeError(w, storeError(err, id))
+		return
 	}
 
-	res, _ := json.Marshal(fmt.Sprintf("Successfully updated consonant clusters for language %s", id))
+	res, _ := json.Marshal(fmt.Sprintf("Successfully updated consonant clusters for language %s (revision %d)", id, rev.Number))
 	w.Header().Set("Content-Type", "application/json")
 	w.Write(res)
 }
 
 // UpdateNucleusHierarchy ...
-func UpdateNucleusHierarchy(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+func (api *API) UpdateNucleusHierarchy(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
 	fmt.Println("UpdateNucleusHierarchy")
 	var reqData struct {
 		ID   string                        `json:"id"`
@@ -251,89 +406,850 @@ func UpdateNucleusHierarchy(w http.ResponseWriter, r *http.Request, _ httprouter
 	}
 	err := json.NewDecoder(r.Body).Decode(&reqData)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, malformedRequest(err))
 		return
 	}
 	nh := reqData.Data
 	id := reqData.ID
 
-	bs, err := MarshalBinary(nh)
+	rev, err := api.Store.Revise(id, "update nucleus hierarchy", func(lang *storage.Language) { lang.Nuclei = nh })
+	if err != nil {
+		writeError(w, storeError(err, id))
+		return
+	}
+
+	data, _ := json.Marshal(fmt.Sprintf("Successfully updated nucleus clusters for language %s (revision %d)", id, rev.Number))
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
 
-	stmt := `INSERT INTO languages (lang_id, nucleus_clusters) VALUES ($1, $2) ON CONFLICT (lang_id) DO UPDATE SET nucleus_clusters=$2 WHERE languages.lang_id=$1;`
-	res, err := Pool.Exec(stmt, id, bs)
-	affected, _ := res.RowsAffected()
+// UpdatePhonotacticOptions ...
+func (api *API) UpdatePhonotacticOptions(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	fmt.Println("UpdatePhonotacticOptions")
+	var reqData struct {
+		ID   string                          `json:"id"`
+		Data phonotactics.PhonotacticOptions `json:"data"`
+	}
+	err := json.NewDecoder(r.Body).Decode(&reqData)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, malformedRequest(err))
+		return
+	}
+	options := reqData.Data
+	id := reqData.ID
+
+	if options.LengthModel == phonotactics.HistogramLM {
+		if _, err := phonotactics.HistogramWordLengths(options.LengthHistogram); err != nil {
+			writeError(w, invalidInput(err))
+			return
+		}
 	}
-	if affected == 0 {
-		http.Error(w, "Failed to update consonant clusters", http.StatusInternalServerError)
+
+	err = api.Store.PutOptions(id, options)
+	if err != nil {
+		writeError(w, storeError(err, id))
+		return
 	}
 
-	data, _ := json.Marshal(fmt.Sprintf("Successfully updated vowel inventory for language %s", id))
+	data, _ := json.Marshal(fmt.Sprintf("Successfully updated phonotactic options for language %s", id))
 	w.Header().Set("Content-Type", "application/json")
 	w.Write(data)
 }
 
-// func CreatePhonotacticRules(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
-// 	fmt.Println("CreatePhonotacticRules")
+// GetWordLengthDistribution returns the probability mass function of word lengths for a language,
+// where pmf[i] is the probability of a word of i syllables, or of i morae if its unit is morae
+func (api *API) GetWordLengthDistribution(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	fmt.Println("GetWordLengthDistribution")
+	id := ps.ByName("id")
 
-// }
+	options, err := api.Store.GetOptions(id)
+	if err != nil {
+		writeError(w, storeError(err, id))
+		return
+	}
 
-// func CreateAllophonies(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
-// 	fmt.Println("CreateAllophonies")
+	lengths, err := phonotactics.NewWordLengthDistribution(options)
+	if err != nil {
+		writeError(w, invalidLanguage(err))
+		return
+	}
 
-// }
+	data, err := json.Marshal(&struct {
+		ID   string                  `json:"id"`
+		PMF  []float64               `json:"pmf"`
+		Mean float64                 `json:"mean"`
+		Unit phonotactics.LengthUnit `json:"unit"`
+	}{
+		ID:   id,
+		PMF:  lengths.PMF,
+		Mean: lengths.Mean(),
+		Unit: options.LengthUnit,
+	})
+	if err != nil {
+		writeError(w, internalError(err))
+		return
+	}
 
-// GetNewWords ...
-func GetNewWords(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
-	fmt.Println("GetNewWords")
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
+
+// GetPhonotacticRules returns the rules and OCP constraints applied to a language's tree
+func (api *API) GetPhonotacticRules(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	fmt.Println("GetPhonotacticRules")
 	id := ps.ByName("id")
 
-	var onsets phonotactics.ConsonantHierarchy
-	var nuclei phonotactics.NucleusHierarchy
-	var codas phonotactics.ConsonantHierarchy
-	var ob, nb, cb []byte
-	row := Pool.QueryRow(`SELECT onset_clusters, nucleus_clusters, coda_clusters FROM languages WHERE lang_id=$1`, id)
-	if row.Err() == sql.ErrNoRows {
-		http.Error(w, fmt.Sprintf("No language with id \"%s\" found.", id), http.StatusBadRequest)
+	rules, err := api.Store.GetRules(id)
+	if err != nil {
+		writeError(w, storeError(err, id))
+		return
+	}
+
+	data, err := json.Marshal(rules)
+	if err != nil {
+		writeError(w, internalError(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
+
+// UpdatePhonotacticRules replaces the rules and OCP constraints applied to a language's tree
+func (api *API) UpdatePhonotacticRules(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	fmt.Println("UpdatePhonotacticRules")
+	var reqData struct {
+		ID   string                        `json:"id"`
+		Data phonotactics.PhonotacticRules `json:"data"`
+	}
+	err := json.NewDecoder(r.Body).Decode(&reqData)
+	if err != nil {
+		writeError(w, malformedRequest(err))
 		return
 	}
+	id := reqData.ID
 
-	err := row.Scan(&ob, &nb, &cb)
+	err = api.Store.PutRules(id, reqData.Data)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, storeError(err, id))
 		return
 	}
 
-	err = UnmarshalBinary(ob, &onsets)
+	data, _ := json.Marshal(fmt.Sprintf("Successfully updated phonotactic rules for language %s", id))
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
+
+// ExportLanguage returns everything stored for a language as one versioned JSON document,
+// for backing up, sharing, or version-controlling it
+func (api *API) ExportLanguage(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	fmt.Println("ExportLanguage")
+	id := ps.ByName("id")
+
+	lang, err := api.Store.GetLanguage(id)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, storeError(err, id))
 		return
 	}
-	err = UnmarshalBinary(nb, &nuclei)
+
+	data, err := json.MarshalIndent(storage.NewDocument(lang), "", "  ")
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, internalError(err))
 		return
 	}
-	err = UnmarshalBinary(cb, &codas)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s.json\"", id))
+	w.Write(data)
+}
+
+// ImportLanguage creates a new language from a document written by ExportLanguage, and
+// returns its id. The document is validated, its hierarchies may only hold phonemes of its
+// inventory, and they must build a phonotactic tree if it has any nuclei. Phonemes still to
+// be placed in the hierarchies are imported as they are
+func (api *API) ImportLanguage(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	fmt.Println("ImportLanguage")
+	doc, err := storage.DecodeDocument(r.Body)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, invalidInput(err))
 		return
 	}
 
-	root, err := phonotactics.NewPhonotacticTree(onsets, nuclei, codas)
-	root.SetHiatus(phonotactics.NeverRF)
-	wordGen := phonotactics.NewWordGenerator(root)
+	lang := doc.Language
+	lang.ID = uuid.NewV4().String()
+	if report := lang.Consistency(); len(report.Stale) > 0 {
+		writeError(w, invalidLanguage(report))
+		return
+	}
+	if lang.Nuclei.HasNuclei() {
+		if _, err := NewLanguage(lang); err != nil {
+			writeError(w, invalidLanguage(err))
+			return
+		}
+	}
 
-	words := []string{}
-	for i := 0; i < 30; i++ {
-		length := phonotactics.GetWordLength(phonotactics.MonosyllabicWL, phonotactics.ShortWL, phonotactics.MediumWL)
-		words = append(words, wordGen.NewWord(length))
+	_, err = api.Store.Revise(lang.ID, "import", func(stored *storage.Language) { *stored = lang })
+	if err != nil {
+		writeError(w, storeError(err, lang.ID))
+		return
 	}
 
-	data, err := json.Marshal(words)
+	data, _ := json.Marshal(lang.ID)
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
+
+// ListRevisions lists a language's revisions, oldest first, without their snapshots
+func (api *API) ListRevisions(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	fmt.Println("ListRevisions")
+	id := ps.ByName("id")
+
+	revisions, err := api.Store.ListRevisions(id)
+	if err != nil {
+		writeError(w, storeError(err, id))
+		return
+	}
+
+	type revisionInfo struct {
+		Number  int       `json:"number"`
+		Summary string    `json:"summary"`
+		Created time.Time `json:"created"`
+	}
+	res := []revisionInfo{}
+	for _, rev := range revisions {
+		res = append(res, revisionInfo{rev.Number, rev.Summary, rev.Created})
+	}
+
+	data, err := json.Marshal(res)
+	if err != nil {
+		writeError(w, internalError(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
+
+// DiffRevisions compares the two revisions of a language given by the from and to query parameters
+func (api *API) DiffRevisions(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	fmt.Println("DiffRevisions")
+	id := ps.ByName("id")
+
+	revs := []storage.Revision{}
+	for _, param := range []string{"from", "to"} {
+		number, err := strconv.Atoi(r.URL.Query().Get(param))
+		if err != nil || number < 1 {
+			writeError(w, invalidInput(fmt.Errorf("%s must be a revision number", param)))
+			return
+		}
+		rev, err := api.Store.GetRevision(id, number)
+		if err != nil {
+			writeError(w, storeError(err, id))
+			return
+		}
+		revs = append(revs, rev)
+	}
+
+	data, err := json.Marshal(storage.Diff(revs[0], revs[1]))
+	if err != nil {
+		writeError(w, internalError(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
+
+// RollbackLanguage restores the whole language, including parts updated since without a new
+// revision, to an earlier revision. The rollback is itself a new revision, so it can be undone
+func (api *API) RollbackLanguage(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	fmt.Println("RollbackLanguage")
+	var reqData struct {
+		ID       string `json:"id"`
+		Revision int    `json:"revision"`
+	}
+	err := json.NewDecoder(r.Body).Decode(&reqData)
+	if err != nil {
+		writeError(w, malformedRequest(err))
+		return
+	}
+	id := reqData.ID
+
+	old, err := api.Store.GetRevision(id, reqData.Revision)
+	if err != nil {
+		writeError(w, storeError(err, id))
+		return
+	}
+	rev, err := api.Store.Revise(id, fmt.Sprintf("roll back to revision %d", old.Number), func(lang *storage.Language) { *lang = old.Language })
+	if err != nil {
+		writeError(w, storeError(err, id))
+		return
+	}
+
+	data, _ := json.Marshal(fmt.Sprintf("Successfully rolled back language %s to revision %d (revision %d)", id, old.Number, rev.Number))
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
+
+// ForkLanguage copies a language, as it is now or at the given revision, to a new id that
+// links back to it, and returns the new id
+func (api *API) ForkLanguage(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	fmt.Println("ForkLanguage")
+	var reqData struct {
+		ID       string `json:"id"`
+		Revision int    `json:"revision"` // 0 for the current language
+	}
+	err := json.NewDecoder(r.Body).Decode(&reqData)
+	if err != nil {
+		writeError(w, malformedRequest(err))
+		return
+	}
+	id := reqData.ID
+
+	var source storage.Revision
+	if reqData.Revision == 0 {
+		source.Language, err = api.Store.GetLanguage(id)
+		if err == nil {
+			var revisions []storage.Revision
+			revisions, err = api.Store.ListRevisions(id)
+			if len(revisions) > 0 {
+				source.Number = revisions[len(revisions)-1].Number
+			}
+		}
+	} else {
+		source, err = api.Store.GetRevision(id, reqData.Revision)
+	}
+	if err != nil {
+		writeError(w, storeError(err, id))
+		return
+	}
+
+	fork := source.Language
+	fork.ID = uuid.NewV4().String()
+	fork.Parent = &storage.Parent{ID: id, Revision: source.Number}
+	_, err = api.Store.Revise(fork.ID, fmt.Sprintf("fork of %s at revision %d", id, source.Number), func(lang *storage.Language) { *lang = fork })
+	if err != nil {
+		writeError(w, storeError(err, fork.ID))
+		return
+	}
+
+	data, _ := json.Marshal(fork.ID)
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
+
+// ListPresets returns the library of starter language presets
+func ListPresets(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	fmt.Println("ListPresets")
+	data, err := json.Marshal(presets.All())
+	if err != nil {
+		writeError(w, internalError(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
+
+// InstantiatePreset creates a new language from a preset, or from a blend of two presets
+// if blend is given, and returns its id
+func (api *API) InstantiatePreset(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	fmt.Println("InstantiatePreset")
+	var reqData struct {
+		Preset string `json:"preset"`
+		Blend  string `json:"blend"` // optional, a second preset to blend with the first
+	}
+	err := json.NewDecoder(r.Body).Decode(&reqData)
+	if err != nil {
+		writeError(w, malformedRequest(err))
+		return
+	}
+
+	p, found := presets.Find(reqData.Preset)
+	if !found {
+		writeError(w, presetNotFound(reqData.Preset))
+		return
+	}
+	summary := fmt.Sprintf("created from preset %s", p.ID)
+	if reqData.Blend != "" {
+		b, found := presets.Find(reqData.Blend)
+		if !found {
+			writeError(w, presetNotFound(reqData.Blend))
+			return
+		}
+		summary = fmt.Sprintf("created from presets %s and %s", p.ID, b.ID)
+		p = presets.Blend(p, b)
+	}
+
+	created, err := presetLanguage(p, uuid.NewV4().String())
+	if err != nil {
+		writeError(w, invalidLanguage(err))
+		return
+	}
+	_, err = api.Store.Revise(created.ID, summary, func(lang *storage.Language) { *lang = created })
+	if err != nil {
+		writeError(w, storeError(err, created.ID))
+		return
+	}
+
+	data, _ := json.Marshal(created.ID)
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
+
+// presetLanguage builds a preset, or a blend of two, into a language with the id, checking that
+// it is consistent and builds a phonotactic tree before anything is stored
+func presetLanguage(p presets.Preset, id string) (storage.Language, error) {
+	lang, err := p.Language(id)
+	if err != nil {
+		return lang, err
+	}
+	_, err = NewLanguage(lang)
+	return lang, err
+}
+
+// func CreateAllophonies(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+// 	fmt.Println("CreateAllophonies")
+
+// }
+
+// GetLexicon returns the words stored for a language
+func (api *API) GetLexicon(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	fmt.Println("GetLexicon")
+	id := ps.ByName("id")
+
+	lex, err := api.Store.GetLexicon(id)
+	if err != nil {
+		writeError(w, storeError(err, id))
+		return
+	}
+	if lex.Entries == nil {
+		lex.Entries = []lexicon.Entry{}
+	}
+
+	data, err := json.Marshal(lex)
+	if err != nil {
+		writeError(w, internalError(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
+
+// UpdateLexicon replaces the words stored for a language. Each form must be valid IPA
+func (api *API) UpdateLexicon(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	fmt.Println("UpdateLexicon")
+	var reqData struct {
+		ID   string          `json:"id"`
+		Data []lexicon.Entry `json:"data"`
+	}
+	err := json.NewDecoder(r.Body).Decode(&reqData)
+	if err != nil {
+		writeError(w, malformedRequest(err))
+		return
+	}
+	id := reqData.ID
+
+	// forms are read with the language's custom segments, if it exists yet
+	inv, err := api.Store.GetInventory(id)
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		writeError(w, storeError(err, id))
+		return
+	}
+	for _, entry := range reqData.Data {
+		if _, err := phonology.ParseWordWithSegments(entry.Form, inv.Segments); err != nil {
+			writeError(w, invalidInput(err))
+			return
+		}
+	}
+
+	err = api.Store.PutLexicon(id, lexicon.Lexicon{Entries: reqData.Data})
+	if err != nil {
+		writeError(w, storeError(err, id))
+		return
+	}
+
+	data, _ := json.Marshal(fmt.Sprintf("Successfully updated lexicon for language %s", id))
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
+
+// languagePhonotactics holds the parts of a language needed to build its phonotactic tree,
+// and the custom segments needed to read its words
+type languagePhonotactics struct {
+	Onsets   phonotactics.ConsonantHierarchy
+	Nuclei   phonotactics.NucleusHierarchy
+	Codas    phonotactics.ConsonantHierarchy
+	Options  phonotactics.PhonotacticOptions
+	Rules    phonotactics.PhonotacticRules
+	Segments []phonology.CustomSegment
+}
+
+// loadPhonotactics fetches a language's hierarchies, options, rules, and custom segments from
+// the store
+func (api *API) loadPhonotactics(id string) (languagePhonotactics, error) {
+	var lp languagePhonotactics
+	inv, err := api.Store.GetInventory(id)
+	if err != nil {
+		return lp, err
+	}
+	lp.Segments = inv.Segments
+
+	h, err := api.Store.GetHierarchies(id)
+	if err != nil {
+		return lp, err
+	}
+	lp.Onsets, lp.Nuclei, lp.Codas = h.Onset, h.Nucleus, h.Coda
+
+	lp.Options, err = api.Store.GetOptions(id)
+	if err != nil {
+		return lp, err
+	}
+	lp.Rules, err = api.Store.GetRules(id)
+	return lp, err
+}
+
+// parseWord reads a word given in IPA, with the language's custom segments as single phonemes
+func (lp languagePhonotactics) parseWord(s string) ([]phonology.Phoneme, error) {
+	return phonology.ParseWordWithSegments(s, lp.Segments)
+}
+
+// tree builds the phonotactic tree for the language and applies its rules. Hiatus is
+// forbidden unless the rules say otherwise
+func (lp languagePhonotactics) tree() (*phonotactics.PhonotacticTreeNode, error) {
+	if !lp.Nuclei.HasNuclei() {
+		return nil, errors.New("language has no nuclei, so no syllables can be built")
+	}
+
+	root, err := phonotactics.NewPhonotacticTree(lp.Onsets, lp.Nuclei, lp.Codas)
+	if err != nil {
+		return nil, err
+	}
+	rules := lp.Rules
+	if rules.Hiatus == phonotactics.UnspecifiedRF {
+		rules.Hiatus = phonotactics.NeverRF
+	}
+	rules.Apply(root)
+	return root, nil
+}
+
+// syllabifier creates a phonotactics.Syllabifier from the language's hierarchies and options
+func (lp languagePhonotactics) syllabifier() *phonotactics.Syllabifier {
+	s := phonotactics.NewSyllabifier(lp.Onsets, lp.Nuclei, lp.Codas)
+	s.SetOptions(lp.Options)
+	return s
+}
+
+// inflector creates a morphology.Inflector for the language's tree. The epenthetic vowel is
+// given in IPA, and defaults to the first monophthong in the language's nucleus hierarchy
+func (lp languagePhonotactics) inflector(root *phonotactics.PhonotacticTreeNode, epenthetic string) (morphology.Inflector, error) {
+	v, err := lp.epentheticVowel(epenthetic)
+	return morphology.Inflector{Tree: root, Epenthetic: v, Segments: lp.Segments}, err
+}
+
+// epentheticVowel parses the epenthetic vowel given in IPA, defaulting to the language's
+// first monophthong if it is empty
+func (lp languagePhonotactics) epentheticVowel(epenthetic string) (phonology.Vowel, error) {
+	if epenthetic != "" {
+		return phonology.NewVowelFromIPA(epenthetic)
+	}
+	if len(lp.Nuclei.Monophthongs) > 0 {
+		return lp.Nuclei.Monophthongs[0], nil
+	}
+	return phonology.Vowel{}, nil
+}
+
+// GetNewWords ...
+func (api *API) GetNewWords(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	fmt.Println("GetNewWords")
+	id := ps.ByName("id")
+
+	stored, err := api.Store.GetLanguage(id)
+	if err != nil {
+		writeError(w, storeError(err, id))
+		return
+	}
+	if report := stored.Consistency(); !report.Consistent {
+		writeError(w, invalidLanguage(report))
+		return
+	}
+
+	lengths, err := phonotactics.NewWordLengthDistribution(stored.Options)
+	if err != nil {
+		writeError(w, invalidLanguage(err))
+		return
+	}
+
+	lang, err := NewLanguage(stored)
+	if err != nil {
+		writeError(w, invalidLanguage(err))
+		return
+	}
+
+	// lengths the constraints leave no word for are skipped, unless they leave none at all
+	words := []string{}
+	for i := 0; i < 30; i++ {
+		word, genErr := lang.WordGenerator.NewWordOfLength(lengths.Sample())
+		if genErr != nil {
+			err = genErr
+			continue
+		}
+		words = append(words, word)
+	}
+	if len(words) == 0 {
+		writeError(w, invalidLanguage(err))
+		return
+	}
+
+	data, err := json.Marshal(words)
+	if err != nil {
+		writeError(w, internalError(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
+
+// InflectRoot produces the full inflection table of a root in a paradigm, adjusting morpheme
+// boundaries to fit the language's phonotactics. The epenthetic vowel defaults to the first
+// monophthong in the language's nucleus hierarchy
+func (api *API) InflectRoot(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	fmt.Println("InflectRoot")
+	var reqData struct {
+		ID         string              `json:"id"`
+		Root       string              `json:"root"`
+		Epenthetic string              `json:"epenthetic"`
+		Paradigm   morphology.Paradigm `json:"paradigm"`
+	}
+	err := json.NewDecoder(r.Body).Decode(&reqData)
+	if err != nil {
+		writeError(w, malformedRequest(err))
+		return
+	}
+
+	lp, err := api.loadPhonotactics(reqData.ID)
+	if err != nil {
+		writeError(w, storeError(err, reqData.ID))
+		return
+	}
+	root, err := lp.tree()
+	if err != nil {
+		writeError(w, invalidLanguage(err))
+		return
+	}
+
+	inflector, err := lp.inflector(root, reqData.Epenthetic)
+	if err != nil {
+		writeError(w, invalidInput(err))
+		return
+	}
+
+	table, err := inflector.Inflect(reqData.Root, reqData.Paradigm)
+	if err != nil {
+		writeError(w, invalidInput(err))
+		return
+	}
+
+	data, err := json.Marshal(table)
+	if err != nil {
+		writeError(w, internalError(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
+
+// SuggestAffixes generates a set of affixes for a list of grammatical categories from the
+// language's phonotactics, with categories listed from most to least frequent
+func (api *API) SuggestAffixes(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	fmt.Println("SuggestAffixes")
+	var reqData struct {
+		ID         string               `json:"id"`
+		Categories []string             `json:"categories"`
+		Type       morphology.AffixType `json:"type"`
+	}
+	err := json.NewDecoder(r.Body).Decode(&reqData)
+	if err != nil {
+		writeError(w, malformedRequest(err))
+		return
+	}
+
+	lp, err := api.loadPhonotactics(reqData.ID)
+	if err != nil {
+		writeError(w, storeError(err, reqData.ID))
+		return
+	}
+	root, err := lp.tree()
+	if err != nil {
+		writeError(w, invalidLanguage(err))
+		return
+	}
+
+	affixes, err := morphology.AffixGenerator{Root: root, Segments: lp.Segments}.Suggest(reqData.Categories, reqData.Type)
+	if err != nil {
+		writeError(w, invalidInput(err))
+		return
+	}
+
+	data, err := json.Marshal(affixes)
+	if err != nil {
+		writeError(w, internalError(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
+
+// CreateCompound joins two or more roots given in IPA into a compound, repairing any junction
+// the language's phonotactics forbid, and lists the repairs it made
+func (api *API) CreateCompound(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	fmt.Println("CreateCompound")
+	var reqData struct {
+		ID         string   `json:"id"`
+		Forms      []string `json:"forms"`
+		Epenthetic string   `json:"epenthetic"`
+	}
+	err := json.NewDecoder(r.Body).Decode(&reqData)
+	if err != nil {
+		writeError(w, malformedRequest(err))
+		return
+	}
+
+	lp, err := api.loadPhonotactics(reqData.ID)
+	if err != nil {
+		writeError(w, storeError(err, reqData.ID))
+		return
+	}
+	root, err := lp.tree()
+	if err != nil {
+		writeError(w, invalidLanguage(err))
+		return
+	}
+	inflector, err := lp.inflector(root, reqData.Epenthetic)
+	if err != nil {
+		writeError(w, invalidInput(err))
+		return
+	}
+
+	compound, err := inflector.Compound(reqData.Forms)
+	if err != nil {
+		writeError(w, invalidInput(err))
+		return
+	}
+
+	data, err := json.Marshal(compound)
+	if err != nil {
+		writeError(w, internalError(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
+
+// RepairWord finds the cheapest edits that make a word given in IPA legal in the language, along
+// with the alternative repairs considered. Edit costs of 0 use the defaults, and the max cost
+// is capped at phonotactics.MaxRepairCost
+func (api *API) RepairWord(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	fmt.Println("RepairWord")
+	var reqData struct {
+		ID                 string `json:"id"`
+		Word               string `json:"word"`
+		Epenthetic         string `json:"epenthetic"`
+		EpenthesisCost     int    `json:"epenthesisCost"`
+		DeletionCost       int    `json:"deletionCost"`
+		SimplificationCost int    `json:"simplificationCost"`
+		MaxCost            int    `json:"maxCost"`
+	}
+	err := json.NewDecoder(r.Body).Decode(&reqData)
+	if err != nil {
+		writeError(w, malformedRequest(err))
+		return
+	}
+
+	lp, err := api.loadPhonotactics(reqData.ID)
+	if err != nil {
+		writeError(w, storeError(err, reqData.ID))
+		return
+	}
+	word, err := lp.parseWord(reqData.Word)
+	if err != nil {
+		writeError(w, invalidInput(err))
+		return
+	}
+	root, err := lp.tree()
+	if err != nil {
+		writeError(w, invalidLanguage(err))
+		return
+	}
+	epenthetic, err := lp.epentheticVowel(reqData.Epenthetic)
+	if err != nil {
+		writeError(w, invalidInput(err))
+		return
+	}
+
+	result := root.Repair(word, phonotactics.RepairOptions{
+		Epenthetic:         epenthetic,
+		EpenthesisCost:     reqData.EpenthesisCost,
+		DeletionCost:       reqData.DeletionCost,
+		SimplificationCost: reqData.SimplificationCost,
+		MaxCost:            reqData.MaxCost,
+	})
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		writeError(w, internalError(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
+
+// SyllabifyWord splits a word given in IPA into syllables according to the language's
+// hierarchies, marking the weight and stress of each. The principle, if specified,
+// overrides the language's own
+func (api *API) SyllabifyWord(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	fmt.Println("SyllabifyWord")
+	var reqData struct {
+		ID        string                                `json:"id"`
+		Word      string                                `json:"word"`
+		Principle phonotactics.SyllabificationPrinciple `json:"principle"`
+	}
+	err := json.NewDecoder(r.Body).Decode(&reqData)
+	if err != nil {
+		writeError(w, malformedRequest(err))
+		return
+	}
+
+	lp, err := api.loadPhonotactics(reqData.ID)
+	if err != nil {
+		writeError(w, storeError(err, reqData.ID))
+		return
+	}
+	word, err := lp.parseWord(reqData.Word)
+	if err != nil {
+		writeError(w, invalidInput(err))
+		return
+	}
+	syllabifier := lp.syllabifier()
+	if reqData.Principle != phonotactics.UnspecifiedSYP {
+		syllabifier.Principle = reqData.Principle
+	}
+
+	syllabification, err := syllabifier.Syllabify(word)
+	if err != nil {
+		writeError(w, invalidInput(err))
+		return
+	}
+
+	data, err := json.Marshal(syllabification)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, internalError(err))
 		return
 	}
 
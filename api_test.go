@@ -0,0 +1,567 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jheredos/langgen/lexicon"
+	"github.com/jheredos/langgen/phonology"
+	"github.com/jheredos/langgen/phonotactics"
+	"github.com/jheredos/langgen/presets"
+	"github.com/jheredos/langgen/storage"
+)
+
+// errStoreDown is returned by every method of brokenStore
+var errStoreDown = errors.New("store unreachable")
+
+// brokenStore is a LanguageStore whose backend has failed, for testing how handlers report
+// errors other than storage.ErrNotFound
+type brokenStore struct{}
+
+var _ storage.LanguageStore = brokenStore{}
+
+func (brokenStore) GetLanguage(id string) (storage.Language, error) {
+	return storage.Language{}, errStoreDown
+}
+func (brokenStore) PutLanguage(lang storage.Language) error { return errStoreDown }
+func (brokenStore) Revise(id string, summary string, change func(*storage.Language)) (storage.Revision, error) {
+	return storage.Revision{}, errStoreDown
+}
+func (brokenStore) ListRevisions(id string) ([]storage.Revision, error) { return nil, errStoreDown }
+func (brokenStore) GetRevision(id string, number int) (storage.Revision, error) {
+	return storage.Revision{}, errStoreDown
+}
+func (brokenStore) GetInventory(id string) (phonology.Inventory, error) {
+	return phonology.Inventory{}, errStoreDown
+}
+func (brokenStore) PutConsonants(id string, cs []phonology.Consonant) error { return errStoreDown }
+func (brokenStore) PutVowels(id string, vs []phonology.Vowel) error         { return errStoreDown }
+func (brokenStore) GetHierarchies(id string) (storage.Hierarchies, error) {
+	return storage.Hierarchies{}, errStoreDown
+}
+func (brokenStore) PutConsonantHierarchy(id string, h phonotactics.ConsonantHierarchy) error {
+	return errStoreDown
+}
+func (brokenStore) PutNucleusHierarchy(id string, h phonotactics.NucleusHierarchy) error {
+	return errStoreDown
+}
+func (brokenStore) GetOptions(id string) (phonotactics.PhonotacticOptions, error) {
+	return phonotactics.PhonotacticOptions{}, errStoreDown
+}
+func (brokenStore) PutOptions(id string, options phonotactics.PhonotacticOptions) error {
+	return errStoreDown
+}
+func (brokenStore) GetRules(id string) (phonotactics.PhonotacticRules, error) {
+	return phonotactics.PhonotacticRules{}, errStoreDown
+}
+func (brokenStore) PutRules(id string, rules phonotactics.PhonotacticRules) error {
+	return errStoreDown
+}
+func (brokenStore) GetLexicon(id string) (lexicon.Lexicon, error) {
+	return lexicon.Lexicon{}, errStoreDown
+}
+func (brokenStore) PutLexicon(id string, lex lexicon.Lexicon) error { return errStoreDown }
+
+// serve sends the request through the API's router, returning the response status and the
+// code of the error it reports, if any
+func serve(t *testing.T, store storage.LanguageStore, method, path, body string) (int, ErrorCode) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	newRouter(&API{Store: store}).ServeHTTP(rec, httptest.NewRequest(method, path, strings.NewReader(body)))
+
+	var res struct {
+		Error *APIError `json:"error"`
+	}
+	if rec.Code < 400 {
+		return rec.Code, ""
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &res); err != nil || res.Error == nil {
+		t.Fatalf("%s %s: error response %q is not {\"error\": {...}}", method, path, rec.Body.String())
+	}
+	return rec.Code, res.Error.Code
+}
+
+func TestHandlerErrors(t *testing.T) {
+	type request struct {
+		method, path, body string
+	}
+	for _, tc := range []struct {
+		name string
+		// a request for the language "missing", which must be 404 against an empty store,
+		// or succeed for handlers that create the language, and 500 against a broken one
+		request request
+		creates bool
+		// a request that must be rejected with a 400 and the code, if the handler takes input,
+		// sent once the language exists, as some input can only be read with the language
+		malformed request
+		code      ErrorCode
+	}{
+		{
+			name:    "GetInventory",
+			request: request{"GET", "/phonology/missing", ""},
+		},
+		{
+			name:    "GetInventoryChart",
+			request: request{"GET", "/phonology/missing/chart", ""},
+		},
+		{
+			name:      "UpdateConsonantInventory",
+			request:   request{"POST", "/phonology/consonants", `{"id": "missing", "data": []}`},
+			creates:   true,
+			malformed: request{"POST", "/phonology/consonants", `{"id": `}, code: MalformedRequestEC,
+		},
+		{
+			name:      "UpdateVowelInventory",
+			request:   request{"POST", "/phonology/vowels", `{"id": "missing", "data": []}`},
+			creates:   true,
+			malformed: request{"POST", "/phonology/vowels", `{"data": 3}`}, code: MalformedRequestEC,
+		},
+		{
+			name:      "UpdateDiphthongInventory",
+			request:   request{"POST", "/phonology/diphthongs", `{"id": "missing", "data": []}`},
+			creates:   true,
+			malformed: request{"POST", "/phonology/diphthongs", `{"id": "missing", "data": [{}]}`}, code: InvalidInputEC,
+		},
+		{
+			name:      "UpdateSegmentInventory",
+			request:   request{"POST", "/phonology/segments", `{"id": "missing", "data": []}`},
+			creates:   true,
+			malformed: request{"POST", "/phonology/segments", `[]`}, code: MalformedRequestEC,
+		},
+		{
+			name:      "GetNaturalClass",
+			request:   request{"POST", "/phonology/natural-class", `{"id": "missing", "data": {"features": "[+voice]"}}`},
+			malformed: request{"POST", "/phonology/natural-class", `{`}, code: MalformedRequestEC,
+		},
+		{
+			name:      "UpdateConsonantHierarchy",
+			request:   request{"POST", "/phonotactics/consonant-hierarchy", `{"id": "missing", "data": {"onset": true}}`},
+			creates:   true,
+			malformed: request{"POST", "/phonotactics/consonant-hierarchy", `{"data": []}`}, code: MalformedRequestEC,
+		},
+		{
+			name:      "UpdateNucleusHierarchy",
+			request:   request{"POST", "/phonotactics/nucleus-hierarchy", `{"id": "missing", "data": {}}`},
+			creates:   true,
+			malformed: request{"POST", "/phonotactics/nucleus-hierarchy", `{"id": 3}`}, code: MalformedRequestEC,
+		},
+		{
+			name:      "UpdatePhonotacticOptions",
+			request:   request{"POST", "/phonotactics/options", `{"id": "missing", "data": {}}`},
+			creates:   true,
+			malformed: request{"POST", "/phonotactics/options", `not json`}, code: MalformedRequestEC,
+		},
+		{
+			name:    "GetWordLengthDistribution",
+			request: request{"GET", "/phonotactics/word-lengths/missing", ""},
+		},
+		{
+			name:      "UpdatePhonotacticRules",
+			request:   request{"POST", "/phonotactics/rules", `{"id": "missing", "data": {}}`},
+			creates:   true,
+			malformed: request{"POST", "/phonotactics/rules", `{"data": "sometimes"}`}, code: MalformedRequestEC,
+		},
+		{
+			name:    "GetPhonotacticRules",
+			request: request{"GET", "/phonotactics/rules/missing", ""},
+		},
+		{
+			name:      "RepairWord",
+			request:   request{"POST", "/phonotactics/repair", `{"id": "missing", "word": "pa"}`},
+			malformed: request{"POST", "/phonotactics/repair", `{"id": "missing", "word": "@pa"}`}, code: InvalidInputEC,
+		},
+		{
+			name:      "SyllabifyWord",
+			request:   request{"POST", "/phonotactics/syllabify", `{"id": "missing", "word": "pa"}`},
+			malformed: request{"POST", "/phonotactics/syllabify", `{"word": ["pa"]}`}, code: MalformedRequestEC,
+		},
+		{
+			name:    "GetNewWords",
+			request: request{"GET", "/lexicon/new-words/missing", ""},
+		},
+		{
+			name:    "GetLexicon",
+			request: request{"GET", "/lexicon/entries/missing", ""},
+		},
+		{
+			name:      "UpdateLexicon",
+			request:   request{"POST", "/lexicon/entries", `{"id": "missing", "data": []}`},
+			creates:   true,
+			malformed: request{"POST", "/lexicon/entries", `{"id": "missing", "data": [{"form": "@pa"}]}`}, code: InvalidInputEC,
+		},
+		{
+			name:      "InflectRoot",
+			request:   request{"POST", "/morphology/inflect", `{"id": "missing", "root": "pa"}`},
+			malformed: request{"POST", "/morphology/inflect", `{"root": 1}`}, code: MalformedRequestEC,
+		},
+		{
+			name:      "SuggestAffixes",
+			request:   request{"POST", "/morphology/affixes", `{"id": "missing", "categories": ["plural"]}`},
+			malformed: request{"POST", "/morphology/affixes", `{"categories": "plural"}`}, code: MalformedRequestEC,
+		},
+		{
+			name:      "CreateCompound",
+			request:   request{"POST", "/morphology/compound", `{"id": "missing", "forms": ["pa", "ta"]}`},
+			malformed: request{"POST", "/morphology/compound", `{"forms": "pata"}`}, code: MalformedRequestEC,
+		},
+		{
+			name:    "ExportLanguage",
+			request: request{"GET", "/languages/missing/export", ""},
+		},
+		{
+			name:      "ImportLanguage",
+			request:   request{"POST", "/languages/import", `{"version": 1}`},
+			creates:   true,
+			malformed: request{"POST", "/languages/import", `{"version": 99}`}, code: InvalidInputEC,
+		},
+		{
+			name:    "ListRevisions",
+			request: request{"GET", "/languages/missing/revisions", ""},
+		},
+		{
+			name:      "DiffRevisions",
+			request:   request{"GET", "/languages/missing/diff?from=1&to=2", ""},
+			malformed: request{"GET", "/languages/missing/diff?from=first&to=2", ""}, code: InvalidInputEC,
+		},
+		{
+			name:      "RollbackLanguage",
+			request:   request{"POST", "/languages/rollback", `{"id": "missing", "revision": 1}`},
+			malformed: request{"POST", "/languages/rollback", `{"revision": "1"}`}, code: MalformedRequestEC,
+		},
+		{
+			name:      "ForkLanguage",
+			request:   request{"POST", "/languages/fork", `{"id": "missing"}`},
+			malformed: request{"POST", "/languages/fork", `{"id": "missing",}`}, code: MalformedRequestEC,
+		},
+		{
+			name:      "InstantiatePreset",
+			request:   request{"POST", "/presets/instantiate", `{"preset": "hawaiian"}`},
+			creates:   true,
+			malformed: request{"POST", "/presets/instantiate", `{"preset": hawaiian}`}, code: MalformedRequestEC,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			req := tc.request
+			status, code := serve(t, storage.NewMemoryStore(), req.method, req.path, req.body)
+			if tc.creates {
+				if status != http.StatusOK {
+					t.Errorf("against an empty store got %d %s, want 200", status, code)
+				}
+			} else if status != http.StatusNotFound || code != LanguageNotFoundEC {
+				t.Errorf("against an empty store got %d %s, want 404 %s", status, code, LanguageNotFoundEC)
+			}
+
+			if status, code := serve(t, brokenStore{}, req.method, req.path, req.body); status != http.StatusInternalServerError || code != InternalEC {
+				t.Errorf("against a broken store got %d %s, want 500 %s", status, code, InternalEC)
+			}
+
+			if bad := tc.malformed; bad.method != "" {
+				store := storage.NewMemoryStore()
+				if err := store.PutLanguage(storage.Language{ID: "missing"}); err != nil {
+					t.Fatal(err)
+				}
+				if status, code := serve(t, store, bad.method, bad.path, bad.body); status != http.StatusBadRequest || code != tc.code {
+					t.Errorf("malformed request got %d %s, want 400 %s", status, code, tc.code)
+				}
+			}
+		})
+	}
+}
+
+func TestPresetErrors(t *testing.T) {
+	for _, tc := range []struct {
+		body   string
+		status int
+		code   ErrorCode
+	}{
+		{`{"preset": "klingon"}`, http.StatusNotFound, PresetNotFoundEC},
+		{`{"preset": "hawaiian", "blend": "klingon"}`, http.StatusNotFound, PresetNotFoundEC},
+	} {
+		if status, code := serve(t, storage.NewMemoryStore(), "POST", "/presets/instantiate", tc.body); status != tc.status || code != tc.code {
+			t.Errorf("%s: got %d %s, want %d %s", tc.body, status, code, tc.status, tc.code)
+		}
+	}
+}
+
+func TestBlendedPresets(t *testing.T) {
+	for _, a := range presets.All() {
+		for _, b := range presets.All() {
+			body := `{"preset": "` + a.ID + `", "blend": "` + b.ID + `"}`
+			if status, code := serve(t, storage.NewMemoryStore(), "POST", "/presets/instantiate", body); status != http.StatusOK {
+				t.Errorf("%s: got %d %s, want 200", body, status, code)
+			}
+		}
+	}
+}
+
+func TestInvalidPresetLanguage(t *testing.T) {
+	for _, p := range []presets.Preset{
+		{ID: "unreadable", Consonants: []string{"@"}, Vowels: []string{"a"}},
+		{ID: "undeclared", Vowels: []string{"a"}, Onsets: presets.Tiers{NoCluster: []string{"p"}}, Nucleus: presets.Nucleus{Monophthongs: []string{"a"}}},
+		{ID: "unplaced", Consonants: []string{"p"}, Vowels: []string{"a"}, Onsets: presets.Tiers{NoCluster: []string{"p"}}},
+		// consistent, but without a nucleus no tree can be built
+		{ID: "no-nuclei", Consonants: []string{"p"}, Onsets: presets.Tiers{NoCluster: []string{"p"}}},
+	} {
+		if _, err := presetLanguage(p, "x"); err == nil {
+			t.Errorf("%s: built a language, want an error", p.ID)
+		}
+	}
+}
+
+// phonemes parses IPA into consonants and vowels for building test languages
+func phonemes(t *testing.T, ipa ...string) ([]phonology.Consonant, []phonology.Vowel) {
+	t.Helper()
+	cs, vs := []phonology.Consonant{}, []phonology.Vowel{}
+	for _, s := range ipa {
+		word, err := phonology.ParseWord(s)
+		if err != nil || len(word) != 1 {
+			t.Fatalf("/%s/ is not one phoneme: %v", s, err)
+		}
+		switch p := word[0].(type) {
+		case phonology.Consonant:
+			cs = append(cs, p)
+		case phonology.Vowel:
+			vs = append(vs, p)
+		}
+	}
+	return cs, vs
+}
+
+func TestSyllabifyWord(t *testing.T) {
+	// onsets climb from /s/ through the stops to /l/, and only /s/ and /l/ are codas
+	fricatives, _ := phonemes(t, "s")
+	stops, _ := phonemes(t, "p", "t")
+	liquids, vowels := phonemes(t, "l", "a", "i")
+	// with a diphthong, and a custom segment ranked with the stops
+	ai, err := phonology.NewDiphthongFromIPA("ai̯")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ch := phonology.CustomSegment{Symbol: "ch", Name: "affricate"}
+	store := storage.NewMemoryStore()
+	err = store.PutLanguage(storage.Language{
+		ID:         "lang",
+		Consonants: append(append(fricatives, stops...), liquids...),
+		Vowels:     vowels,
+		Diphthongs: []phonology.Diphthong{ai},
+		Segments:   []phonology.CustomSegment{ch},
+		Onsets: phonotactics.ConsonantHierarchy{
+			Onset:       true,
+			Tiers:       [][]phonology.Consonant{fricatives, stops, liquids},
+			CustomTiers: [][]phonology.CustomSegment{{}, {ch}},
+		},
+		Nuclei:  phonotactics.NucleusHierarchy{Monophthongs: vowels, Diphthongs: []phonology.Diphthong{ai}},
+		Codas:   phonotactics.ConsonantHierarchy{NoCluster: append(fricatives, liquids...)},
+		Options: phonotactics.PhonotacticOptions{StressType: phonotactics.PenultimateST},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		body  string
+		ipa   string
+		legal bool
+	}{
+		{`{"id": "lang", "word": "patla"}`, "ˈpa.tla", true},
+		{`{"id": "lang", "word": "pastla"}`, "ˈpa.stla", true},
+		// the principle overrides the language's own: by sonority, /t/ starts the onset
+		{`{"id": "lang", "word": "pastla", "principle": 2}`, "ˈpas.tla", true},
+		{`{"id": "lang", "word": "pai̯"}`, "pai̯", true},
+		{`{"id": "lang", "word": "chai̯schla"}`, "ˈchai̯.schla", true},
+		// syllable breaks in the input are ignored, and a cluster no division allows is a best guess
+		{`{"id": "lang", "word": "pal.tpa"}`, "ˈpalt.pa", false},
+	} {
+		rec := httptest.NewRecorder()
+		newRouter(&API{Store: store}).ServeHTTP(rec, httptest.NewRequest("POST", "/phonotactics/syllabify", strings.NewReader(tc.body)))
+		var res struct {
+			IPA   string `json:"ipa"`
+			Legal bool   `json:"legal"`
+		}
+		if rec.Code != http.StatusOK {
+			t.Errorf("%s: got %d %s", tc.body, rec.Code, rec.Body.String())
+		} else if err := json.Unmarshal(rec.Body.Bytes(), &res); err != nil || res.IPA != tc.ipa || res.Legal != tc.legal {
+			t.Errorf("%s: got %s, want %s, legal %t", tc.body, rec.Body.String(), tc.ipa, tc.legal)
+		}
+	}
+}
+
+// serveJSON sends a request that must succeed to a router over the store, and decodes its response into res
+func serveJSON(t *testing.T, store storage.LanguageStore, method, path, body string, res interface{}) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	newRouter(&API{Store: store}).ServeHTTP(rec, httptest.NewRequest(method, path, strings.NewReader(body)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("%s %s: got %d %s", method, path, rec.Code, rec.Body.String())
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), res); err != nil {
+		t.Fatalf("%s %s: cannot decode %s: %v", method, path, rec.Body.String(), err)
+	}
+}
+
+func TestExportImport(t *testing.T) {
+	cs, vs := phonemes(t, "p", "t", "s", "a", "i")
+	lang := storage.Language{
+		ID:         "lang",
+		Consonants: cs,
+		Vowels:     vs,
+		Onsets:     phonotactics.ConsonantHierarchy{Onset: true, NoCluster: cs},
+		Nuclei:     phonotactics.NucleusHierarchy{Nuclei: vs, Monophthongs: vs},
+		Codas:      phonotactics.ConsonantHierarchy{NoCluster: cs[2:]},
+		Options:    phonotactics.PhonotacticOptions{StressType: phonotactics.PenultimateST},
+		Lexicon:    lexicon.Lexicon{Entries: []lexicon.Entry{{Form: "ˈpa.tis", Gloss: "stone"}}},
+	}
+	store := storage.NewMemoryStore()
+	if err := store.PutLanguage(lang); err != nil {
+		t.Fatal(err)
+	}
+
+	var doc storage.Document
+	serveJSON(t, store, "GET", "/languages/lang/export", "", &doc)
+	data, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var id string
+	serveJSON(t, store, "POST", "/languages/import", string(data), &id)
+
+	// the import is the same language under a new id
+	imported, err := store.GetLanguage(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id == lang.ID {
+		t.Errorf("imported under the exported language's id")
+	}
+	imported.ID = lang.ID
+	want, _ := json.Marshal(lang)
+	got, _ := json.Marshal(imported)
+	if string(got) != string(want) {
+		t.Errorf("exported %s, imported %s", want, got)
+	}
+}
+
+func TestInconsistentLanguages(t *testing.T) {
+	cs, vs := phonemes(t, "p", "t", "a")
+	k, _ := phonemes(t, "k")
+	placed := storage.Language{
+		ID:         "placed",
+		Consonants: cs,
+		Vowels:     vs,
+		Onsets:     phonotactics.ConsonantHierarchy{Onset: true, NoCluster: cs},
+		Nuclei:     phonotactics.NucleusHierarchy{Nuclei: vs, Monophthongs: vs},
+	}
+	// /k/ was added to the inventory, but not yet placed in a hierarchy
+	unplaced := placed
+	unplaced.ID = "unplaced"
+	unplaced.Consonants = append(append([]phonology.Consonant{}, cs...), k...)
+	// and /k/ is in the onsets, but was dropped from the inventory behind the store's back
+	stale := placed
+	stale.ID = "stale"
+	stale.Onsets = phonotactics.ConsonantHierarchy{Onset: true, NoCluster: unplaced.Consonants}
+
+	store := storage.NewMemoryStore()
+	for _, lang := range []storage.Language{placed, unplaced, stale} {
+		if err := store.PutLanguage(lang); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// only a consistent language makes words
+	var words []interface{}
+	serveJSON(t, store, "GET", "/lexicon/new-words/placed", "", &words)
+	for _, id := range []string{"unplaced", "stale"} {
+		if code, ec := serve(t, store, "GET", "/lexicon/new-words/"+id, ""); code != http.StatusBadRequest || ec != InvalidLanguageEC {
+			t.Errorf("new words for %s: got %d %s", id, code, ec)
+		}
+	}
+
+	// the inventory reports what to fix
+	var inv struct {
+		Consistency storage.ConsistencyReport `json:"consistency"`
+	}
+	serveJSON(t, store, "GET", "/phonology/unplaced", "", &inv)
+	if inv.Consistency.Consistent || strings.Join(inv.Consistency.NeedsPlacement, " ") != "k" {
+		t.Errorf("reported %+v for an unplaced /k/", inv.Consistency)
+	}
+
+	// an import may leave phonemes to place, but not hold phonemes the inventory lacks
+	var id string
+	for _, lang := range []storage.Language{unplaced, stale} {
+		data, err := json.Marshal(storage.NewDocument(lang))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if lang.ID == "unplaced" {
+			serveJSON(t, store, "POST", "/languages/import", string(data), &id)
+		} else if code, ec := serve(t, store, "POST", "/languages/import", string(data)); code != http.StatusBadRequest || ec != InvalidLanguageEC {
+			t.Errorf("import of a stale language: got %d %s", code, ec)
+		}
+	}
+}
+
+func TestRevisionHandlers(t *testing.T) {
+	store := storage.NewMemoryStore()
+	var msg string
+	// two updates make two revisions
+	for _, ipa := range [][]string{{"p", "t"}, {"t", "k"}} {
+		cs, _ := phonemes(t, ipa...)
+		data, _ := json.Marshal(cs)
+		serveJSON(t, store, "POST", "/phonology/consonants", `{"id": "lang", "data": `+string(data)+`}`, &msg)
+	}
+
+	var diff storage.LanguageDiff
+	serveJSON(t, store, "GET", "/languages/lang/diff?from=1&to=2", "", &diff)
+	if strings.Join(diff.ConsonantsAdded, " ") != "k" || strings.Join(diff.ConsonantsRemoved, " ") != "p" {
+		t.Errorf("diffed revisions 1 and 2 as %+v", diff)
+	}
+	if code, ec := serve(t, store, "GET", "/languages/lang/diff?from=1&to=9", ""); code != http.StatusNotFound {
+		t.Errorf("diff to a missing revision: got %d %s", code, ec)
+	}
+
+	// rolling back is a revision of its own, so it can be undone
+	serveJSON(t, store, "POST", "/languages/rollback", `{"id": "lang", "revision": 1}`, &msg)
+	var revisions []struct {
+		Number  int    `json:"number"`
+		Summary string `json:"summary"`
+	}
+	serveJSON(t, store, "GET", "/languages/lang/revisions", "", &revisions)
+	if len(revisions) != 3 || revisions[2].Number != 3 || revisions[2].Summary != "roll back to revision 1" {
+		t.Fatalf("listed revisions %+v after rolling back", revisions)
+	}
+	inv, err := store.GetInventory("lang")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(inv.Consonants) != 2 || inv.Consonants[0].ToIPA() != "p" {
+		t.Errorf("rolled back to %+v, want /p t/", inv.Consonants)
+	}
+
+	// a fork of the current language records the revision it was taken from
+	var forkID string
+	serveJSON(t, store, "POST", "/languages/fork", `{"id": "lang"}`, &forkID)
+	fork, err := store.GetLanguage(forkID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fork.Parent == nil || *fork.Parent != (storage.Parent{ID: "lang", Revision: 3}) || len(fork.Consonants) != 2 {
+		t.Errorf("forked %+v", fork)
+	}
+	// and a fork of an old revision is that revision
+	serveJSON(t, store, "POST", "/languages/fork", `{"id": "lang", "revision": 2}`, &forkID)
+	fork, err = store.GetLanguage(forkID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fork.Parent == nil || fork.Parent.Revision != 2 || fork.Consonants[1].ToIPA() != "k" {
+		t.Errorf("forked revision 2 as %+v", fork)
+	}
+	serveJSON(t, store, "GET", "/languages/"+forkID+"/revisions", "", &revisions)
+	if len(revisions) != 1 {
+		t.Errorf("a new fork has revisions %+v", revisions)
+	}
+}
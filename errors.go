@@ -0,0 +1,106 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/jheredos/langgen/storage"
+)
+
+// ErrorCode is a stable, machine-readable name for the kind of error an API response
+// reports, so that clients can tell errors apart without parsing their messages
+type ErrorCode string
+
+// ErrorCode values
+const (
+	MalformedRequestEC ErrorCode = "malformed_request"  // the body could not be decoded
+	InvalidInputEC     ErrorCode = "invalid_input"      // the request was decoded, but is not valid
+	InvalidLanguageEC  ErrorCode = "invalid_language"   // the stored language cannot serve the request, e.g. it has no nuclei
+	LanguageNotFoundEC ErrorCode = "language_not_found" // no language has the id
+	RevisionNotFoundEC ErrorCode = "revision_not_found" // the language has no revision with the number
+	PresetNotFoundEC   ErrorCode = "preset_not_found"   // no preset has the id
+	RouteNotFoundEC    ErrorCode = "route_not_found"    // no handler serves the path
+	MethodNotAllowedEC ErrorCode = "method_not_allowed" // handlers serve the path, but not with the method
+	InternalEC         ErrorCode = "internal_error"     // the server failed, e.g. the store is unreachable
+)
+
+// APIError is an error along with the HTTP status and code to report it with.
+// It is written as {"error": {"code": "...", "message": "..."}}
+type APIError struct {
+	Status  int       `json:"-"`
+	Code    ErrorCode `json:"code"`
+	Message string    `json:"message"`
+}
+
+func (e *APIError) Error() string {
+	return e.Message
+}
+
+// malformedRequest reports a request body that could not be decoded
+func malformedRequest(err error) *APIError {
+	return &APIError{http.StatusBadRequest, MalformedRequestEC, err.Error()}
+}
+
+// invalidInput reports a request whose contents are invalid, like a word that is not valid IPA
+func invalidInput(err error) *APIError {
+	return &APIError{http.StatusBadRequest, InvalidInputEC, err.Error()}
+}
+
+// invalidLanguage reports a stored language that is missing or has invalid parts the
+// request depends on
+func invalidLanguage(err error) *APIError {
+	return &APIError{http.StatusBadRequest, InvalidLanguageEC, err.Error()}
+}
+
+// internalError reports a failure of the server itself
+func internalError(err error) *APIError {
+	return &APIError{http.StatusInternalServerError, InternalEC, err.Error()}
+}
+
+// storeError reports an error from the store, as a 404 if the language or revision
+// does not exist, and otherwise as a 500
+func storeError(err error, id string) *APIError {
+	switch {
+	case errors.Is(err, storage.ErrNotFound):
+		return &APIError{http.StatusNotFound, LanguageNotFoundEC, fmt.Sprintf("No language with id \"%s\" found.", id)}
+	case errors.Is(err, storage.ErrRevisionNotFound):
+		return &APIError{http.StatusNotFound, RevisionNotFoundEC, fmt.Sprintf("No such revision of language \"%s\" found.", id)}
+	}
+	return internalError(err)
+}
+
+// presetNotFound reports a preset id that is not in the library
+func presetNotFound(id string) *APIError {
+	return &APIError{http.StatusNotFound, PresetNotFoundEC, fmt.Sprintf("No preset with id \"%s\" found.", id)}
+}
+
+// writeError writes an APIError as the JSON response
+func writeError(w http.ResponseWriter, e *APIError) {
+	data, _ := json.Marshal(struct {
+		Error *APIError `json:"error"`
+	}{e})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(e.Status)
+	w.Write(data)
+}
+
+// routeNotFound is the router's handler for paths no other handler serves
+func routeNotFound(w http.ResponseWriter, r *http.Request) {
+	writeError(w, &APIError{http.StatusNotFound, RouteNotFoundEC, fmt.Sprintf("No route for %s.", r.URL.Path)})
+}
+
+// methodNotAllowed is the router's handler for methods a path is not served with
+func methodNotAllowed(w http.ResponseWriter, r *http.Request) {
+	writeError(w, &APIError{http.StatusMethodNotAllowed, MethodNotAllowedEC, fmt.Sprintf("%s is not allowed for %s.", r.Method, r.URL.Path)})
+}
+
+// recoverPanic is the router's handler for panics in other handlers, reporting them as
+// internal errors rather than dropping the connection
+func recoverPanic(w http.ResponseWriter, r *http.Request, v interface{}) {
+	fmt.Println("Recovered from panic:", v)
+	writeError(w, internalError(fmt.Errorf("internal error handling %s %s", r.Method, r.URL.Path)))
+}
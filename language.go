@@ -2,16 +2,40 @@ package main
 
 import (
 	"github.com/jheredos/langgen/phonotactics"
+	"github.com/jheredos/langgen/storage"
 )
 
 // Language is the top-level data structure wrapping a phonological inventory,
 // the phonotactic tree, and everything else that comprises a language
 type Language struct {
-	ID string
-	// phonology
-	Consonants []string
-	Vowels     []string
+	storage.Language
 	// phonotactics
 	PhonotacticTree *phonotactics.PhonotacticTreeNode
 	WordGenerator   *phonotactics.WordGenerator
 }
+
+// NewLanguage builds the phonotactic tree and word generator for a stored language,
+// failing if its hierarchies cannot make a tree
+func NewLanguage(stored storage.Language) (*Language, error) {
+	lp := languagePhonotactics{
+		Onsets:   stored.Onsets,
+		Nuclei:   stored.Nuclei,
+		Codas:    stored.Codas,
+		Options:  stored.Options,
+		Rules:    stored.Rules,
+		Segments: stored.Segments,
+	}
+	root, err := lp.tree()
+	if err != nil {
+		return nil, err
+	}
+	wordGen := phonotactics.NewWordGenerator(root)
+	wordGen.SetOptions(lp.Options)
+	lp.Rules.ApplyToGenerator(wordGen)
+
+	return &Language{
+		Language:        stored,
+		PhonotacticTree: root,
+		WordGenerator:   wordGen,
+	}, nil
+}
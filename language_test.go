@@ -0,0 +1,39 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/jheredos/langgen/phonotactics"
+	"github.com/jheredos/langgen/presets"
+)
+
+// every word a preset generates must read back, with its diphthongs and custom segments as
+// single phonemes, as a word the preset's own phonotactics accept
+func TestPresetWordsAreAccepted(t *testing.T) {
+	for _, p := range presets.All() {
+		stored, err := p.Language(p.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		lang, err := NewLanguage(stored)
+		if err != nil {
+			t.Fatalf("%s: %v", p.ID, err)
+		}
+		lp := languagePhonotactics{Segments: stored.Segments}
+
+		for i := 0; i < 200; i++ {
+			ipa, err := lang.WordGenerator.NewWord(1 + i%4)
+			if err == phonotactics.ErrNoWord {
+				continue
+			} else if err != nil {
+				t.Fatalf("%s: %v", p.ID, err)
+			}
+			word, err := lp.parseWord(ipa)
+			if err != nil {
+				t.Errorf("%s generated /%s/, which does not parse: %v", p.ID, ipa, err)
+			} else if !lang.PhonotacticTree.Accepts(word) {
+				t.Errorf("%s generated /%s/, which it does not accept", p.ID, ipa)
+			}
+		}
+	}
+}
@@ -0,0 +1,12 @@
+package lexicon
+
+// Entry is a word of a language, with its form in IPA and its meaning
+type Entry struct {
+	Form  string `json:"form" bson:"form"`
+	Gloss string `json:"gloss" bson:"gloss"`
+}
+
+// Lexicon is the list of words a language has coined
+type Lexicon struct {
+	Entries []Entry `json:"entries" bson:"entries"`
+}
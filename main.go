@@ -5,37 +5,73 @@ import (
 	"net/http"
 	"os"
 
+	"github.com/jheredos/langgen/storage"
 	"github.com/julienschmidt/httprouter"
 	"github.com/rs/cors"
 )
 
 func main() {
-	close := ConnectToDB()
+	store, close, err := storage.Open(storage.ConfigFromEnv())
+	if err != nil {
+		panic(err)
+	}
 	defer close()
 
+	handler := cors.New(cors.Options{
+		AllowedOrigins:   []string{"http://localhost:8080", "https://*.herokuapp.com/"},
+		AllowCredentials: true,
+		AllowedMethods:   []string{"GET", "POST", "DELETE"},
+	}).Handler(newRouter(&API{Store: store}))
+
+	port := ":" + os.Getenv("PORT")
+	fmt.Println("Listening on port " + port)
+	http.ListenAndServe(port, handler)
+}
+
+// newRouter routes every path the API serves to its handler
+func newRouter(api *API) *httprouter.Router {
 	router := httprouter.New()
+	router.NotFound = http.HandlerFunc(routeNotFound)
+	router.MethodNotAllowed = http.HandlerFunc(methodNotAllowed)
+	router.PanicHandler = recoverPanic
 
 	router.GET("/", CreateNewLanguage)
-	router.GET("/phonology/:id", GetInventory)
-	router.POST("/phonology/consonants", UpdateConsonantInventory)
-	router.POST("/phonology/vowels", UpdateVowelInventory)
+	router.GET("/phonology/:id", api.GetInventory)
+	router.GET("/phonology/:id/chart", api.GetInventoryChart)
+	router.POST("/phonology/consonants", api.UpdateConsonantInventory)
+	router.POST("/phonology/vowels", api.UpdateVowelInventory)
+	router.POST("/phonology/diphthongs", api.UpdateDiphthongInventory)
+	router.POST("/phonology/segments", api.UpdateSegmentInventory)
+	router.POST("/phonology/natural-class", api.GetNaturalClass)
 
-	router.POST("/phonotactics/consonant-hierarchy", UpdateConsonantHierarchy)
-	router.POST("/phonotactics/nucleus-hierarchy", UpdateNucleusHierarchy)
-	// router.POST("/phonotactics/rules", CreatePhonotacticRules)
+	router.POST("/phonotactics/consonant-hierarchy", api.UpdateConsonantHierarchy)
+	router.POST("/phonotactics/nucleus-hierarchy", api.UpdateNucleusHierarchy)
+	router.POST("/phonotactics/options", api.UpdatePhonotacticOptions)
+	router.GET("/phonotactics/word-lengths/:id", api.GetWordLengthDistribution)
+	router.POST("/phonotactics/rules", api.UpdatePhonotacticRules)
+	router.GET("/phonotactics/rules/:id", api.GetPhonotacticRules)
+	router.POST("/phonotactics/repair", api.RepairWord)
+	router.POST("/phonotactics/syllabify", api.SyllabifyWord)
 	// router.POST("/phonotactics/allophonies", CreateAllophonies)
 
-	router.GET("/lexicon/new-words/:id", GetNewWords)
+	router.GET("/lexicon/new-words/:id", api.GetNewWords)
+	router.GET("/lexicon/entries/:id", api.GetLexicon)
+	router.POST("/lexicon/entries", api.UpdateLexicon)
 
-	router.GET("/ping", Ping)
+	router.POST("/morphology/inflect", api.InflectRoot)
+	router.POST("/morphology/affixes", api.SuggestAffixes)
+	router.POST("/morphology/compound", api.CreateCompound)
 
-	handler := cors.New(cors.Options{
-		AllowedOrigins:   []string{"http://localhost:8080", "https://*.herokuapp.com/"},
-		AllowCredentials: true,
-		AllowedMethods:   []string{"GET", "POST", "DELETE"},
-	}).Handler(router)
+	router.GET("/languages/:id/export", api.ExportLanguage)
+	router.POST("/languages/import", api.ImportLanguage)
+	router.GET("/languages/:id/revisions", api.ListRevisions)
+	router.GET("/languages/:id/diff", api.DiffRevisions)
+	router.POST("/languages/rollback", api.RollbackLanguage)
+	router.POST("/languages/fork", api.ForkLanguage)
 
-	port := ":" + os.Getenv("PORT")
-	fmt.Println("Listening on port " + port)
-	http.ListenAndServe(port, handler)
+	router.GET("/presets", ListPresets)
+	router.POST("/presets/instantiate", api.InstantiatePreset)
+
+	router.GET("/ping", Ping)
+	return router
 }
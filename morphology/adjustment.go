@@ -0,0 +1,26 @@
+package morphology
+
+import (
+	"github.com/jheredos/langgen/phonology"
+	"github.com/jheredos/langgen/phonotactics"
+)
+
+// adjust repairs a word that the phonotactic tree does not accept with the tree's own repairs,
+// limited to its morpheme boundaries: inserting the epenthetic vowel at a boundary, or deleting
+// or simplifying a consonant on either side of one. It makes the cheapest repair, with at most
+// as many edits as there are boundaries, and leaves the word as it is if none makes it legal
+func (inf Inflector) adjust(word []phonology.Phoneme, boundaries []int) ([]phonology.Phoneme, []phonotactics.Edit) {
+	if inf.Tree == nil || len(boundaries) == 0 || inf.Tree.Accepts(word) {
+		return word, []phonotactics.Edit{}
+	}
+
+	result := inf.Tree.Repair(word, phonotactics.RepairOptions{
+		Epenthetic: inf.Epenthetic,
+		MaxCost:    len(boundaries),
+		Boundaries: boundaries,
+	})
+	if !result.Legal {
+		return word, []phonotactics.Edit{}
+	}
+	return result.Best.Word, result.Best.Edits
+}
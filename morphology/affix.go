@@ -0,0 +1,72 @@
+package morphology
+
+import (
+	"errors"
+
+	"github.com/jheredos/langgen/phonology"
+)
+
+// AffixType is where an affix attaches to its base
+type AffixType uint8
+
+// AffixType values
+const (
+	UnspecifiedAT AffixType = iota
+	PrefixAT
+	SuffixAT
+	InfixAT     // inserted before the first vowel of the base, like Tagalog -um-
+	CircumfixAT // a prefix and a suffix attached together, like German ge-...-t
+)
+
+// Affix is a grammatical morpheme whose form is a sequence of phonemes in IPA.
+// Circumfixes use Form for the part before the base and SuffixForm for the part after it
+type Affix struct {
+	Gloss      string    `json:"gloss"` // e.g. PL, GEN
+	Type       AffixType `json:"type"`
+	Form       string    `json:"form"`
+	SuffixForm string    `json:"suffixForm"`
+}
+
+// attach adds the affix to a base, returning the new word along with the indices of the
+// morpheme boundaries it created, in ascending order. The affix's forms are read with the
+// language's custom segments
+func (a Affix) attach(base []phonology.Phoneme, segments []phonology.CustomSegment) ([]phonology.Phoneme, []int, error) {
+	form, err := phonology.ParseWordWithSegments(a.Form, segments)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	word := []phonology.Phoneme{}
+	switch a.Type {
+	case PrefixAT:
+		word = append(append(word, form...), base...)
+		return word, []int{len(form)}, nil
+	case SuffixAT:
+		word = append(append(word, base...), form...)
+		return word, []int{len(base)}, nil
+	case InfixAT:
+		i := firstVowel(base)
+		word = append(append(append(word, base[:i]...), form...), base[i:]...)
+		return word, []int{i, i + len(form)}, nil
+	case CircumfixAT:
+		suffix, err := phonology.ParseWordWithSegments(a.SuffixForm, segments)
+		if err != nil {
+			return nil, nil, err
+		}
+		word = append(append(append(word, form...), base...), suffix...)
+		return word, []int{len(form), len(form) + len(base)}, nil
+	}
+
+	return nil, nil, errors.New("Affix \"" + a.Gloss + "\" has no affix type")
+}
+
+// firstVowel returns the index of the first vowel in a word, or the length of the word if
+// it has none, so that an infix in a word without vowels behaves like a suffix
+func firstVowel(word []phonology.Phoneme) int {
+	for i, p := range word {
+		if p.Match(phonology.Vowel{}) {
+			return i
+		}
+	}
+	return len(word)
+}
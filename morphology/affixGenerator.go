@@ -0,0 +1,210 @@
+package morphology
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/jheredos/langgen/phonology"
+	"github.com/jheredos/langgen/phonotactics"
+)
+
+// AffixGenerator suggests grammatical affixes built from a language's own phonotactic tree.
+// Like natural languages, it favours short affixes made of frequent, unmarked segments
+type AffixGenerator struct {
+	Root     *phonotactics.PhonotacticTreeNode
+	Segments []phonology.CustomSegment // the language's custom segments, for reading back the IPA of the tree's phonemes
+}
+
+// affixSampleSize is the number of words generated to estimate how often each segment occurs
+const affixSampleSize = 500
+
+// affixLengthPenalty is the factor by which each additional segment lowers an affix's score,
+// on top of the score of the segment itself
+const affixLengthPenalty = 0.3
+
+// affixCandidate is a possible affix form along with its score
+type affixCandidate struct {
+	form   []phonology.Phoneme
+	suffix []phonology.Phoneme // the suffix part of a circumfix
+	score  float64
+}
+
+// Suggest returns one affix of the given type for each grammatical category, e.g. "PL" or "GEN".
+// Categories should be listed from most to least frequent, since earlier categories get shorter,
+// less marked affixes. No two categories share a form
+func (g AffixGenerator) Suggest(categories []string, affixType AffixType) ([]Affix, error) {
+	scores := g.segmentScores()
+
+	// single segments that can fill each position on their own
+	onsets := g.segments(phonotactics.OnsetSC, scores, func(edge *phonotactics.PhonotacticTreeEdge) bool {
+		return edge.Boundary == phonotactics.OnsetPC && edge.ChildNode.Constituent == phonotactics.NucleusSC
+	})
+	vowels := g.segments(phonotactics.NucleusSC, scores, func(edge *phonotactics.PhonotacticTreeEdge) bool {
+		return true
+	})
+	codas := g.segments(phonotactics.CodaSC, scores, func(edge *phonotactics.PhonotacticTreeEdge) bool {
+		return edge.Boundary == phonotactics.WordEndPC
+	})
+	// syllabic consonants are nuclei too, but make poor affixes on their own
+	shortVowels, longVowels := []phonology.Phoneme{}, []phonology.Phoneme{}
+	for _, v := range vowels {
+		if v.Match(phonology.Vowel{Length: phonology.ShortVL}) {
+			shortVowels = append(shortVowels, v)
+		} else if v.Match(phonology.Vowel{}) {
+			longVowels = append(longVowels, v)
+		}
+	}
+	if len(shortVowels) == 0 {
+		shortVowels = longVowels
+	}
+
+	var shapes [][][]phonology.Phoneme
+	switch affixType {
+	case PrefixAT:
+		shapes = [][][]phonology.Phoneme{{shortVowels}, {onsets, shortVowels}}
+	case SuffixAT:
+		shapes = [][][]phonology.Phoneme{{shortVowels}, {codas}, {onsets, shortVowels}, {shortVowels, codas}, {onsets, shortVowels, codas}}
+	case InfixAT:
+		shapes = [][][]phonology.Phoneme{{shortVowels, codas}, {onsets, shortVowels}, {shortVowels}}
+	case CircumfixAT:
+		shapes = [][][]phonology.Phoneme{{shortVowels}, {onsets, shortVowels}}
+	default:
+		return nil, errors.New("Cannot suggest affixes without an affix type")
+	}
+
+	candidates := []affixCandidate{}
+	for _, shape := range shapes {
+		candidates = append(candidates, expandShape(shape, scores)...)
+	}
+	if affixType == PrefixAT {
+		// a lone consonant prefix creates a cluster with most roots, so it is a last resort
+		for _, c := range expandShape([][]phonology.Phoneme{onsets}, scores) {
+			c.score *= affixLengthPenalty * affixLengthPenalty
+			candidates = append(candidates, c)
+		}
+	}
+	if affixType == CircumfixAT {
+		// pair each prefix part with a one-segment suffix part
+		suffixes := append(expandShape([][]phonology.Phoneme{shortVowels}, scores), expandShape([][]phonology.Phoneme{codas}, scores)...)
+		pairs := []affixCandidate{}
+		for _, c := range candidates {
+			for _, s := range suffixes {
+				pairs = append(pairs, affixCandidate{form: c.form, suffix: s.form, score: c.score * s.score * affixLengthPenalty})
+			}
+		}
+		candidates = pairs
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].score > candidates[j].score
+	})
+
+	affixes := []Affix{}
+	used := map[string]bool{}
+	for _, c := range candidates {
+		if len(affixes) == len(categories) {
+			break
+		}
+		affix := Affix{
+			Gloss:      categories[len(affixes)],
+			Type:       affixType,
+			Form:       phonology.WordToIPA(c.form),
+			SuffixForm: phonology.WordToIPA(c.suffix),
+		}
+		key := affix.Form + "|" + affix.SuffixForm
+		if used[key] {
+			continue
+		}
+		used[key] = true
+		affixes = append(affixes, affix)
+	}
+
+	if len(affixes) < len(categories) {
+		return affixes, fmt.Errorf("The language's phonotactics only allow %d distinct affixes of this type", len(affixes))
+	}
+	return affixes, nil
+}
+
+// segmentScores estimates how good a choice each segment is for an affix by generating sample
+// words to count how often it occurs, and dividing by how marked it is
+func (g AffixGenerator) segmentScores() map[string]float64 {
+	counts := map[string]float64{}
+	total := 0.0
+
+	wordGen := phonotactics.NewWordGenerator(g.Root)
+	for i := 0; i < affixSampleSize; i++ {
+		sylls, err := wordGen.NewSyllables(2)
+		if err != nil {
+			continue
+		}
+		for _, syll := range sylls {
+			for _, n := range syll.Nodes {
+				if n.Constituent == phonotactics.BoundarySC {
+					continue
+				}
+				counts[n.Val.ToIPA()]++
+				total++
+			}
+		}
+	}
+
+	scores := map[string]float64{}
+	for ipa, count := range counts {
+		p, err := phonology.ParseWordWithSegments(ipa, g.Segments)
+		if err != nil || len(p) != 1 {
+			continue
+		}
+		scores[ipa] = (count / total) / float64(1+phonology.Markedness(p[0]))
+	}
+	return scores
+}
+
+// segments returns the distinct phonemes filling a syllable constituent that have a usable edge,
+// i.e. one that lets them stand alone in that position, ordered from best to worst score
+func (g AffixGenerator) segments(constituent phonotactics.SyllableConstituent, scores map[string]float64, usable func(*phonotactics.PhonotacticTreeEdge) bool) []phonology.Phoneme {
+	segs := []phonology.Phoneme{}
+	seen := map[string]bool{}
+	for _, n := range g.Root.FindConstituent(constituent) {
+		if _, isWordBoundary := n.Val.(phonology.WordBoundary); isWordBoundary {
+			continue
+		}
+		ipa := n.Val.ToIPA()
+		if seen[ipa] {
+			continue
+		}
+		for _, edge := range n.Children {
+			if edge.Weight > 0 && usable(edge) {
+				segs = append(segs, n.Val)
+				seen[ipa] = true
+				break
+			}
+		}
+	}
+
+	sort.SliceStable(segs, func(i, j int) bool {
+		return scores[segs[i].ToIPA()] > scores[segs[j].ToIPA()]
+	})
+	return segs
+}
+
+// expandShape returns every affix made by choosing one segment from each position of a shape,
+// e.g. onsets then vowels for CV, scored by the product of its segments' scores
+func expandShape(shape [][]phonology.Phoneme, scores map[string]float64) []affixCandidate {
+	candidates := []affixCandidate{{form: []phonology.Phoneme{}, score: 1}}
+	for i, position := range shape {
+		next := []affixCandidate{}
+		for _, c := range candidates {
+			for _, p := range position {
+				score := c.score * scores[p.ToIPA()]
+				if i > 0 {
+					score *= affixLengthPenalty
+				}
+				form := append(append([]phonology.Phoneme{}, c.form...), p)
+				next = append(next, affixCandidate{form: form, score: score})
+			}
+		}
+		candidates = next
+	}
+	return candidates
+}
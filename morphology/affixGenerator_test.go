@@ -0,0 +1,145 @@
+package morphology
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jheredos/langgen/phonology"
+	"github.com/jheredos/langgen/phonotactics"
+)
+
+// affixGenerator builds an AffixGenerator for a language of CV syllables from the onsets and
+// vowels. Sampling decides the segments' frequencies, so tests only rely on what sampling
+// cannot change: single segments, or markedness far apart, like /a/ and /y/
+func affixGenerator(t *testing.T, onsets []string, vowels []string) AffixGenerator {
+	t.Helper()
+	cs := []phonology.Consonant{}
+	for _, s := range onsets {
+		c, err := phonology.NewConsonantFromIPA(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cs = append(cs, c)
+	}
+	vs := []phonology.Vowel{}
+	for _, s := range vowels {
+		v, err := phonology.NewVowelFromIPA(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		vs = append(vs, v)
+	}
+
+	root, err := phonotactics.NewPhonotacticTree(
+		phonotactics.ConsonantHierarchy{Onset: true, NoCluster: cs},
+		phonotactics.NucleusHierarchy{Monophthongs: vs},
+		phonotactics.ConsonantHierarchy{},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root.SetInitialNullOnset(phonotactics.NeverRF)
+	root.SetHiatus(phonotactics.NeverRF)
+	return AffixGenerator{Root: root}
+}
+
+// forms lists the affixes' forms, with circumfixes written prefix...suffix
+func forms(affixes []Affix) []string {
+	res := []string{}
+	for _, a := range affixes {
+		if a.Type == CircumfixAT {
+			res = append(res, a.Form+"..."+a.SuffixForm)
+		} else {
+			res = append(res, a.Form)
+		}
+	}
+	return res
+}
+
+func TestSuggestAffixes(t *testing.T) {
+	g := affixGenerator(t, []string{"p", "t"}, []string{"a", "y"})
+	categories := []string{"PL", "GEN", "DAT", "ACC", "LOC", "ABL"}
+
+	for _, tc := range []struct {
+		affixType AffixType
+		// the forms the first categories must get, while later ones depend on sampling
+		first []string
+		// whether earlier categories never get longer affixes. Prefixes of a lone consonant
+		// and circumfixes of marked segments are penalized, but may still beat longer affixes
+		// of unmarked segments
+		shortestFirst bool
+	}{
+		// a lone vowel beats any CV, and unmarked /a/ beats front rounded /y/
+		{SuffixAT, []string{"a", "y"}, true},
+		{PrefixAT, []string{"a", "y"}, false},
+		{InfixAT, []string{"a", "y"}, true},
+		{CircumfixAT, []string{"a...a"}, false},
+	} {
+		affixes, err := g.Suggest(categories, tc.affixType)
+		if err != nil {
+			t.Errorf("%d: %v", tc.affixType, err)
+			continue
+		}
+		got := forms(affixes)
+		if strings.Join(got[:len(tc.first)], " ") != strings.Join(tc.first, " ") {
+			t.Errorf("%d: suggested %v, want it to start with %v", tc.affixType, got, tc.first)
+		}
+
+		seen := map[string]bool{}
+		for i, a := range affixes {
+			if a.Gloss != categories[i] || a.Type != tc.affixType {
+				t.Errorf("%d: affix %d is %+v", tc.affixType, i, a)
+			}
+			if seen[got[i]] {
+				t.Errorf("%d: suggested %s twice", tc.affixType, got[i])
+			}
+			seen[got[i]] = true
+			if tc.shortestFirst && i > 0 && len([]rune(got[i])) < len([]rune(got[i-1])) {
+				t.Errorf("%d: %s is shorter than the earlier %s", tc.affixType, got[i], got[i-1])
+			}
+			// circumfixes always have both parts
+			if (a.SuffixForm != "") != (tc.affixType == CircumfixAT) {
+				t.Errorf("%d: affix %+v", tc.affixType, a)
+			}
+		}
+	}
+}
+
+func TestSuggestTooManyAffixes(t *testing.T) {
+	// with one consonant and one vowel, the only suffixes are /a/ and /pa/
+	g := affixGenerator(t, []string{"p"}, []string{"a"})
+	affixes, err := g.Suggest([]string{"PL", "GEN", "DAT"}, SuffixAT)
+	if err == nil {
+		t.Fatal("suggested three distinct suffixes out of two")
+	}
+	if got := strings.Join(forms(affixes), " "); got != "a pa" || affixes[1].Gloss != "GEN" {
+		t.Errorf("suggested %+v along with the error, want /a/ and /pa/", affixes)
+	}
+
+	if _, err := g.Suggest([]string{"PL"}, UnspecifiedAT); err == nil {
+		t.Error("suggested affixes without an affix type")
+	}
+}
+
+func TestAffixSegmentScores(t *testing.T) {
+	a, err := phonology.NewVowelFromIPA("a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// "ch" would be read as /c/ and /h/ without the language's segments
+	ch := phonology.CustomSegment{Symbol: "ch", Name: "affricate"}
+	root, err := phonotactics.NewPhonotacticTree(
+		phonotactics.ConsonantHierarchy{Onset: true, Tiers: [][]phonology.Consonant{{}}, CustomTiers: [][]phonology.CustomSegment{{ch}}},
+		phonotactics.NucleusHierarchy{Monophthongs: []phonology.Vowel{a}},
+		phonotactics.ConsonantHierarchy{},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	phonotactics.PhonotacticRules{InitialNullOnset: phonotactics.NeverRF, Hiatus: phonotactics.NeverRF}.Apply(root)
+
+	scores := AffixGenerator{Root: root, Segments: []phonology.CustomSegment{ch}}.segmentScores()
+	if scores["ch"] <= 0 || scores["a"] <= 0 {
+		t.Errorf("scored %v", scores)
+	}
+}
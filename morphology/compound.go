@@ -0,0 +1,49 @@
+package morphology
+
+import (
+	"errors"
+
+	"github.com/jheredos/langgen/phonology"
+	"github.com/jheredos/langgen/phonotactics"
+)
+
+// Compound is a word built by joining two or more roots, with the adjustments made at
+// its junctions. Positions of adjustments are indices into the roots joined as they were
+type Compound struct {
+	Roots       []string            `json:"roots"`
+	IPA         string              `json:"ipa"`
+	Adjustments []phonotactics.Edit `json:"adjustments"`
+	Legal       bool                `json:"legal"`
+}
+
+// Compound joins roots given in IPA into a single word. If the word breaks the language's
+// phonotactics, its junctions are repaired as morpheme boundaries are when inflecting, e.g. by
+// inserting the epenthetic vowel between two roots or deleting a segment on either side, as
+// in vowel elision
+func (inf Inflector) Compound(roots []string) (Compound, error) {
+	compound := Compound{
+		Roots:       roots,
+		Adjustments: []phonotactics.Edit{},
+	}
+	if len(roots) < 2 {
+		return compound, errors.New("A compound needs at least two roots")
+	}
+
+	word := []phonology.Phoneme{}
+	junctions := []int{}
+	for i, root := range roots {
+		next, err := phonology.ParseWordWithSegments(root, inf.Segments)
+		if err != nil {
+			return compound, err
+		}
+		if i > 0 {
+			junctions = append(junctions, len(word))
+		}
+		word = append(word, next...)
+	}
+
+	word, compound.Adjustments = inf.adjust(word, junctions)
+	compound.IPA = phonology.WordToIPA(word)
+	compound.Legal = inf.Tree == nil || inf.Tree.Accepts(word)
+	return compound, nil
+}
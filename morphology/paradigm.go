@@ -0,0 +1,115 @@
+package morphology
+
+import (
+	"github.com/jheredos/langgen/phonology"
+	"github.com/jheredos/langgen/phonotactics"
+)
+
+// Dimension is a grammatical category along with its values, e.g. number with
+// the values singular and plural
+type Dimension struct {
+	Name   string   `json:"name"`
+	Values []string `json:"values"`
+}
+
+// Paradigm is a set of grammatical dimensions, like case × number for nouns, with the affixes
+// marking each value, keyed by dimension and then by value. Values without an affix are left
+// unmarked, like the nominative singular in many languages. Affixes attach in the order of
+// the dimensions, so the first dimension's affix is closest to the root
+type Paradigm struct {
+	Name       string                      `json:"name"`
+	Dimensions []Dimension                 `json:"dimensions"`
+	Affixes    map[string]map[string]Affix `json:"affixes"`
+}
+
+// InflectedForm is a cell of an inflection table, with one value per dimension of the paradigm.
+// Legal is false if the form breaks the language's phonotactics even after adjustment
+type InflectedForm struct {
+	Values      []string            `json:"values"`
+	IPA         string              `json:"ipa"`
+	Adjustments []phonotactics.Edit `json:"adjustments"`
+	Legal       bool                `json:"legal"`
+}
+
+// InflectionTable is every inflected form of a root in a paradigm
+type InflectionTable struct {
+	Root     string          `json:"root"`
+	Paradigm string          `json:"paradigm"`
+	Forms    []InflectedForm `json:"forms"`
+}
+
+// Inflector applies affixes and paradigms to roots and joins roots into compounds, adjusting
+// illegal sequences at morpheme boundaries to fit the phonotactic tree. Epenthetic is the vowel
+// inserted to break up illegal clusters. Without a Tree, forms are left as they are. Roots and
+// affixes are read with the language's custom Segments, so that their symbols are single phonemes
+type Inflector struct {
+	Tree       *phonotactics.PhonotacticTreeNode
+	Epenthetic phonology.Vowel
+	Segments   []phonology.CustomSegment
+}
+
+// Inflect produces the full inflection table of a root, given in IPA, for a paradigm
+func (inf Inflector) Inflect(root string, paradigm Paradigm) (InflectionTable, error) {
+	table := InflectionTable{
+		Root:     root,
+		Paradigm: paradigm.Name,
+		Forms:    []InflectedForm{},
+	}
+
+	base, err := phonology.ParseWordWithSegments(root, inf.Segments)
+	if err != nil {
+		return table, err
+	}
+
+	for _, cell := range cells(paradigm.Dimensions) {
+		form := InflectedForm{
+			Values:      cell,
+			Adjustments: []phonotactics.Edit{},
+		}
+		word := base
+		for i, value := range cell {
+			affix, marked := paradigm.Affixes[paradigm.Dimensions[i].Name][value]
+			if !marked {
+				continue
+			}
+			adjustments := []phonotactics.Edit{}
+			word, adjustments, err = inf.Attach(word, affix)
+			if err != nil {
+				return table, err
+			}
+			form.Adjustments = append(form.Adjustments, adjustments...)
+		}
+		form.IPA = phonology.WordToIPA(word)
+		form.Legal = inf.Tree == nil || inf.Tree.Accepts(word)
+		table.Forms = append(table.Forms, form)
+	}
+
+	return table, nil
+}
+
+// Attach adds an affix to a base and adjusts the morpheme boundaries it creates
+func (inf Inflector) Attach(base []phonology.Phoneme, affix Affix) ([]phonology.Phoneme, []phonotactics.Edit, error) {
+	word, boundaries, err := affix.attach(base, inf.Segments)
+	if err != nil {
+		return nil, nil, err
+	}
+	word, adjustments := inf.adjust(word, boundaries)
+	return word, adjustments, nil
+}
+
+// cells returns every combination of values of the dimensions, varying the last dimension
+// fastest, e.g. nom.sg, nom.pl, acc.sg, acc.pl
+func cells(dimensions []Dimension) [][]string {
+	combinations := [][]string{{}}
+	for _, d := range dimensions {
+		next := [][]string{}
+		for _, c := range combinations {
+			for _, v := range d.Values {
+				cell := append(append([]string{}, c...), v)
+				next = append(next, cell)
+			}
+		}
+		combinations = next
+	}
+	return combinations
+}
@@ -0,0 +1,89 @@
+package morphology
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jheredos/langgen/phonology"
+	"github.com/jheredos/langgen/phonotactics"
+)
+
+// openSyllableInflector builds an inflector for a language of CV syllables from /p t k/ and
+// /a i/, with /a/ as the epenthetic vowel
+func openSyllableInflector(t *testing.T) Inflector {
+	t.Helper()
+	cs := []phonology.Consonant{}
+	for _, s := range []string{"p", "t", "k"} {
+		c, err := phonology.NewConsonantFromIPA(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cs = append(cs, c)
+	}
+	vs := []phonology.Vowel{}
+	for _, s := range []string{"a", "i"} {
+		v, err := phonology.NewVowelFromIPA(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		vs = append(vs, v)
+	}
+
+	root, err := phonotactics.NewPhonotacticTree(
+		phonotactics.ConsonantHierarchy{Onset: true, NoCluster: cs},
+		phonotactics.NucleusHierarchy{Monophthongs: vs},
+		phonotactics.ConsonantHierarchy{},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root.SetHiatus(phonotactics.NeverRF)
+	return Inflector{Tree: root, Epenthetic: vs[0]}
+}
+
+func TestInflectRepairsBoundaries(t *testing.T) {
+	inf := openSyllableInflector(t)
+	table, err := inf.Inflect("pat", Paradigm{
+		Name:       "noun",
+		Dimensions: []Dimension{{Name: "number", Values: []string{"SG", "PL"}}},
+		Affixes:    map[string]map[string]Affix{"number": {"PL": {Gloss: "PL", Type: SuffixAT, Form: "ki"}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	plural := table.Forms[1]
+	if plural.IPA != "pataki" || !plural.Legal {
+		t.Errorf("got %s (legal: %v), want pataki", plural.IPA, plural.Legal)
+	}
+	want := []phonotactics.Edit{{Type: phonotactics.EpenthesisET, Position: 3, Segment: "a"}}
+	if !reflect.DeepEqual(plural.Adjustments, want) {
+		t.Errorf("got adjustments %+v, want %+v", plural.Adjustments, want)
+	}
+
+	// the bare root is illegal too, but has no boundary to repair
+	if singular := table.Forms[0]; singular.IPA != "pat" || singular.Legal || len(singular.Adjustments) != 0 {
+		t.Errorf("got %+v, want pat left alone", singular)
+	}
+}
+
+func TestCompoundRepairsEveryJunction(t *testing.T) {
+	inf := openSyllableInflector(t)
+	compound, err := inf.Compound([]string{"pit", "kat", "pa"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if compound.IPA != "pitakatapa" || !compound.Legal {
+		t.Errorf("got %s (legal: %v), want pitakatapa", compound.IPA, compound.Legal)
+	}
+	for _, e := range compound.Adjustments {
+		if e.Position != 3 && e.Position != 6 {
+			t.Errorf("adjustment %+v is not at a junction", e)
+		}
+	}
+
+	// the junction of /pi/ and /ta/ needs no repair
+	if compound, _ := inf.Compound([]string{"pi", "ta"}); compound.IPA != "pita" || len(compound.Adjustments) != 0 {
+		t.Errorf("got %+v, want pita as it is", compound)
+	}
+}
@@ -0,0 +1,111 @@
+package phonology
+
+import "errors"
+
+// Allophony is a rule realizing a phoneme as one of its allophones in some environment,
+// like /t/ → [ɾ] / V_V. Allophone is IPA, and Phoneme is IPA or a feature bundle like
+// "[-son -voice]" for a natural class. Before and After describe the segment on either
+// side: an IPA phoneme, a feature bundle, "V" for any vowel, "C" for any consonant, "#"
+// for a word boundary, or "" for anything
+type Allophony struct {
+	Phoneme   string `json:"phoneme" bson:"phoneme"`
+	Allophone string `json:"allophone" bson:"allophone"`
+	Before    string `json:"before" bson:"before"`
+	After     string `json:"after" bson:"after"`
+}
+
+// Validate returns an error if the allophony's phonemes or environment are not valid IPA
+// or feature bundles
+func (a Allophony) Validate() error {
+	if _, err := parseSegment(a.Allophone); err != nil {
+		return err
+	}
+	if err := validateSegment(a.Phoneme); err != nil {
+		return err
+	}
+	for _, s := range []string{a.Before, a.After} {
+		if s == "" || s == "V" || s == "C" || s == "#" {
+			continue
+		}
+		if err := validateSegment(s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateSegment returns an error if a string is neither a single IPA phoneme nor a
+// feature bundle
+func validateSegment(s string) error {
+	if IsFeatureBundle(s) {
+		_, err := ParseFeatureBundle(s)
+		return err
+	}
+	_, err := parseSegment(s)
+	return err
+}
+
+// ApplyAllophonies returns the surface form of a word, with every phoneme realized as the
+// allophone of the first allophony whose environment it is in. Environments are matched
+// against the underlying word, so the allophonies apply simultaneously
+func ApplyAllophonies(word []Phoneme, allophonies []Allophony) []Phoneme {
+	surface := []Phoneme{}
+	for i, p := range word {
+		realized := p
+		for _, a := range allophonies {
+			if !matchesSegment(p, a.Phoneme) || !inEnvironment(word, i-1, a.Before) || !inEnvironment(word, i+1, a.After) {
+				continue
+			}
+			if allophone, err := parseSegment(a.Allophone); err == nil {
+				realized = allophone
+				break
+			}
+		}
+		surface = append(surface, realized)
+	}
+	return surface
+}
+
+// inEnvironment returns whether the segment at index i of the word, which may be off
+// either end of it, fits an allophony's environment
+func inEnvironment(word []Phoneme, i int, env string) bool {
+	if env == "" {
+		return true
+	}
+	if i < 0 || i >= len(word) {
+		return env == "#"
+	}
+	switch env {
+	case "#":
+		return false
+	case "V":
+		_, isVowel := standIn(word[i]).asVowel()
+		return isVowel
+	case "C":
+		_, isConsonant := standIn(word[i]).asConsonant()
+		return isConsonant
+	}
+	return matchesSegment(word[i], env)
+}
+
+// matchesSegment returns whether a phoneme is the IPA segment, or is in the natural
+// class of the feature bundle
+func matchesSegment(p Phoneme, segment string) bool {
+	if IsFeatureBundle(segment) {
+		b, err := ParseFeatureBundle(segment)
+		return err == nil && b.Matches(p)
+	}
+	return p.ToIPA() == segment
+}
+
+// parseSegment parses a single phoneme from IPA
+func parseSegment(s string) (Phoneme, error) {
+	word, err := ParseWord(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(word) != 1 {
+		return nil, errors.New("\"" + s + "\" is not a single phoneme")
+	}
+	return word[0], nil
+}
@@ -0,0 +1,309 @@
+package phonology
+
+import (
+	"html"
+	"strings"
+)
+
+// Chart lays out an inventory like the IPA chart: pulmonic consonants in a table of
+// place by manner with voiceless and voiced pairs, vowels in a table of height by
+// frontness with unrounded and rounded pairs, and everything else in side lists
+// of non-pulmonic consonants, coarticulated consonants, diphthongs, and custom
+// segments. Only the places and manners the inventory uses are shown, and the
+// cells of the consonant table left empty among them are marked as gaps
+type Chart struct {
+	Consonants ChartTable  `json:"consonants"`
+	Vowels     ChartTable  `json:"vowels"`
+	Lists      []ChartList `json:"lists"`
+}
+
+// ChartTable is a table of a Chart. Each cell holds a pair of phonemes, like the
+// voiceless and voiced consonants of the IPA chart
+type ChartTable struct {
+	Columns []string      `json:"columns"`
+	Rows    []string      `json:"rows"`
+	Cells   [][]ChartCell `json:"cells"` // by row, then column
+}
+
+// ChartCell is one cell of a ChartTable. Left and Right hold the IPA of the phonemes on
+// either side of the cell, with variants like aspirated or long phonemes side by side.
+// Gap marks an empty cell where the inventory could have a phoneme, and Impossible one
+// the IPA chart shades as impossible to articulate
+type ChartCell struct {
+	Left       []string `json:"left"`
+	Right      []string `json:"right"`
+	Gap        bool     `json:"gap"`
+	Impossible bool     `json:"impossible"`
+}
+
+// ChartList is a side list of a Chart, for phonemes that don't fit either table
+type ChartList struct {
+	Title    string   `json:"title"`
+	Phonemes []string `json:"phonemes"`
+}
+
+// chartRow is a row of the consonant table
+type chartRow struct {
+	label   string
+	manner  ConsonantManner
+	lateral bool
+}
+
+// chartRows are the rows of the consonant table, in the order of the IPA chart
+var chartRows = []chartRow{
+	{"stop", StopCM, false},
+	{"nasal", NasalCM, false},
+	{"trill", TrillCM, false},
+	{"tap", TapCM, false},
+	{"lateral tap", TapCM, true},
+	{"fricative", FricativeCM, false},
+	{"lateral fricative", FricativeCM, true},
+	{"affricate", AffricateCM, false},
+	{"lateral affricate", AffricateCM, true},
+	{"approximant", ApproximantCM, false},
+	{"lateral approximant", ApproximantCM, true},
+}
+
+// impossibleArticulation returns whether the IPA chart shades the cell as impossible
+func impossibleArticulation(place ConsonantPlace, row chartRow) bool {
+	switch {
+	case row.lateral:
+		return place <= LabioDentalCP || place >= PharyngealCP
+	case row.manner == NasalCM:
+		return place >= PharyngealCP
+	case row.manner == StopCM || row.manner == AffricateCM:
+		return place == PharyngealCP
+	case row.manner == TrillCM || row.manner == TapCM:
+		return place == VelarCP || place == GlottalCP
+	}
+	return false
+}
+
+// chartListTitles are the titles of the side lists, in the order they are shown
+var chartListTitles = []string{"ejectives", "implosives", "clicks", "labialized", "palatalized", "velarized",
+	"pharyngealized", "prenasalized", "other consonants", "other vowels", "diphthongs", "custom segments"}
+
+// NewChart lays out the inventory as a Chart
+func NewChart(inv Inventory) Chart {
+	lists := map[string][]string{}
+	grid := map[ConsonantPlace]map[int]*ChartCell{}
+	usedRows := map[int]bool{}
+
+	for _, c := range inv.Consonants {
+		if title, aside := chartListTitle(c); aside {
+			lists[title] = append(lists[title], c.ToIPA())
+			continue
+		}
+		row := -1
+		for i, r := range chartRows {
+			if r.manner == c.Manner && r.lateral == (c.Lateral == LateralCL) {
+				row = i
+				break
+			}
+		}
+		if row < 0 || c.Place == UnspecifiedCP {
+			lists["other consonants"] = append(lists["other consonants"], c.ToIPA())
+			continue
+		}
+
+		if grid[c.Place] == nil {
+			grid[c.Place] = map[int]*ChartCell{}
+		}
+		if grid[c.Place][row] == nil {
+			grid[c.Place][row] = &ChartCell{}
+		}
+		cell := grid[c.Place][row]
+		if c.Voiced == VoicedCV || c.Voiced == PrevoicedCV {
+			cell.Right = append(cell.Right, c.ToIPA())
+		} else {
+			cell.Left = append(cell.Left, c.ToIPA())
+		}
+		usedRows[row] = true
+	}
+
+	chart := Chart{}
+	for p := BilabialCP; p <= GlottalCP; p++ {
+		if grid[p] != nil {
+			chart.Consonants.Columns = append(chart.Consonants.Columns, placeNames[p])
+		}
+	}
+	for i, r := range chartRows {
+		if !usedRows[i] {
+			continue
+		}
+		chart.Consonants.Rows = append(chart.Consonants.Rows, r.label)
+		cells := []ChartCell{}
+		for p := BilabialCP; p <= GlottalCP; p++ {
+			if grid[p] == nil {
+				continue
+			}
+			switch {
+			case grid[p][i] != nil:
+				cells = append(cells, *grid[p][i])
+			case impossibleArticulation(p, r):
+				cells = append(cells, ChartCell{Impossible: true})
+			default:
+				cells = append(cells, ChartCell{Gap: true})
+			}
+		}
+		chart.Consonants.Cells = append(chart.Consonants.Cells, cells)
+	}
+
+	chart.Vowels = vowelTable(inv.Vowels, lists)
+	for _, d := range inv.Diphthongs {
+		lists["diphthongs"] = append(lists["diphthongs"], d.ToIPA())
+	}
+	for _, s := range inv.Segments {
+		lists["custom segments"] = append(lists["custom segments"], s.ToIPA())
+	}
+	for _, title := range chartListTitles {
+		if len(lists[title]) > 0 {
+			chart.Lists = append(chart.Lists, ChartList{Title: title, Phonemes: lists[title]})
+		}
+	}
+	return chart
+}
+
+// chartListTitle returns the side list a consonant belongs in, if it doesn't belong
+// in the consonant table
+func chartListTitle(c Consonant) (string, bool) {
+	switch {
+	case c.Manner == ClickCM || c.NonPulmonic == VelaricCNP:
+		return "clicks", true
+	case c.NonPulmonic == EjectiveCNP:
+		return "ejectives", true
+	case c.NonPulmonic == ImplosiveCNP:
+		return "implosives", true
+	case c.Coarticulation > NoneCC:
+		return coarticulationNames[c.Coarticulation], true
+	}
+	return "", false
+}
+
+// vowelTable lays out the vowels by height and frontness. Every height is shown, as
+// in the IPA chart, so that the table keeps the shape of the vowel space. Vowels
+// with no height or frontness are added to the "other vowels" list instead
+func vowelTable(vs []Vowel, lists map[string][]string) ChartTable {
+	t := ChartTable{Columns: frontnessNames[FrontVF:], Rows: heightNames[CloseVH:]}
+	t.Cells = make([][]ChartCell, len(t.Rows))
+	for i := range t.Cells {
+		t.Cells[i] = make([]ChartCell, len(t.Columns))
+	}
+
+	for _, v := range vs {
+		if v.Height == UnspecifiedVH || v.Frontness == UnspecifiedVF {
+			lists["other vowels"] = append(lists["other vowels"], v.ToIPA())
+			continue
+		}
+		cell := &t.Cells[v.Height-CloseVH][v.Frontness-FrontVF]
+		if v.Rounding == RoundedVR {
+			cell.Right = append(cell.Right, v.ToIPA())
+		} else {
+			cell.Left = append(cell.Left, v.ToIPA())
+		}
+	}
+	return t
+}
+
+// Markdown renders the chart as Markdown tables, with gaps marked "–" and impossible
+// articulations "░"
+func (c Chart) Markdown() string {
+	var b strings.Builder
+	if len(c.Consonants.Rows) > 0 {
+		b.WriteString("### Consonants\n\n")
+		c.Consonants.writeMarkdown(&b)
+		b.WriteString("\n– gap, ░ impossible articulation\n\n")
+	}
+	b.WriteString("### Vowels\n\n")
+	c.Vowels.writeMarkdown(&b)
+	for _, l := range c.Lists {
+		b.WriteString("\n**" + l.Title + ":** " + markdownEscape(strings.Join(l.Phonemes, " ")) + "\n")
+	}
+	return b.String()
+}
+
+func (t ChartTable) writeMarkdown(b *strings.Builder) {
+	b.WriteString("| |")
+	for _, col := range t.Columns {
+		b.WriteString(" " + col + " |")
+	}
+	b.WriteString("\n|---|")
+	for range t.Columns {
+		b.WriteString("---|")
+	}
+	b.WriteString("\n")
+
+	for i, row := range t.Rows {
+		b.WriteString("| " + row + " |")
+		for _, cell := range t.Cells[i] {
+			switch {
+			case cell.Gap:
+				b.WriteString(" – |")
+			case cell.Impossible:
+				b.WriteString(" ░ |")
+			default:
+				b.WriteString(" " + markdownEscape(strings.Join(append(cell.Left, cell.Right...), " ")) + " |")
+			}
+		}
+		b.WriteString("\n")
+	}
+}
+
+// markdownEscape escapes characters of custom segment symbols that would break a table
+func markdownEscape(s string) string {
+	return strings.NewReplacer("|", "\\|", "*", "\\*", "_", "\\_").Replace(s)
+}
+
+// chartStyle styles the HTML chart, shading impossible articulations as the IPA chart does
+const chartStyle = `<style>
+.ipa-chart table { border-collapse: collapse; margin-bottom: 1em; }
+.ipa-chart th, .ipa-chart td { border: 1px solid #999; padding: 0.2em 0.5em; text-align: center; }
+.ipa-chart td.left { border-right: none; }
+.ipa-chart td.right { border-left: none; }
+.ipa-chart td.gap { color: #999; }
+.ipa-chart td.impossible { background: #ccc; }
+</style>
+`
+
+// HTML renders the chart as an HTML fragment, with each table cell split into two
+// columns for its pair of phonemes
+func (c Chart) HTML() string {
+	var b strings.Builder
+	b.WriteString("<div class=\"ipa-chart\">\n" + chartStyle)
+	if len(c.Consonants.Rows) > 0 {
+		b.WriteString("<h3>Consonants</h3>\n")
+		c.Consonants.writeHTML(&b)
+	}
+	b.WriteString("<h3>Vowels</h3>\n")
+	c.Vowels.writeHTML(&b)
+	for _, l := range c.Lists {
+		b.WriteString("<p><strong>" + html.EscapeString(l.Title) + ":</strong> " + html.EscapeString(strings.Join(l.Phonemes, " ")) + "</p>\n")
+	}
+	b.WriteString("</div>\n")
+	return b.String()
+}
+
+func (t ChartTable) writeHTML(b *strings.Builder) {
+	b.WriteString("<table>\n<tr><th></th>")
+	for _, col := range t.Columns {
+		b.WriteString("<th colspan=\"2\">" + html.EscapeString(col) + "</th>")
+	}
+	b.WriteString("</tr>\n")
+
+	for i, row := range t.Rows {
+		b.WriteString("<tr><th>" + html.EscapeString(row) + "</th>")
+		for _, cell := range t.Cells[i] {
+			switch {
+			case cell.Gap:
+				b.WriteString("<td class=\"gap\" colspan=\"2\" title=\"gap\">–</td>")
+			case cell.Impossible:
+				b.WriteString("<td class=\"impossible\" colspan=\"2\"></td>")
+			default:
+				b.WriteString("<td class=\"left\">" + html.EscapeString(strings.Join(cell.Left, " ")) + "</td>")
+				b.WriteString("<td class=\"right\">" + html.EscapeString(strings.Join(cell.Right, " ")) + "</td>")
+			}
+		}
+		b.WriteString("</tr>\n")
+	}
+	b.WriteString("</table>\n")
+}
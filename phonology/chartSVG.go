@@ -0,0 +1,143 @@
+package phonology
+
+import (
+	"fmt"
+	"html"
+	"strings"
+)
+
+// Dimensions of the SVG chart, in pixels
+const (
+	svgMargin      = 20
+	svgLabelWidth  = 150
+	svgCellWidth   = 96
+	svgRowHeight   = 30
+	svgTitleHeight = 36
+	svgVowelWidth  = 360
+	svgVowelHeight = 252
+	svgListHeight  = 24
+)
+
+// svgStyle styles the SVG chart, shading impossible articulations as the IPA chart does
+const svgStyle = `<style>
+text { font-family: "Doulos SIL", "Charis SIL", "Gentium Plus", serif; font-size: 16px; }
+text.label { font-family: sans-serif; font-size: 12px; }
+text.title { font-family: sans-serif; font-size: 15px; font-weight: bold; }
+rect { fill: none; stroke: #999; }
+rect.gap { stroke-dasharray: 4 3; }
+rect.impossible { fill: #ccc; }
+text.gap { fill: #999; }
+polygon, line { fill: none; stroke: #666; }
+circle { fill: #333; }
+</style>
+`
+
+// SVG renders the chart as an SVG image: the consonant table, then the vowels placed
+// on the IPA vowel trapezoid, then the side lists. Gaps in the consonant table are
+// drawn dashed, and impossible articulations shaded
+func (c Chart) SVG() string {
+	var b strings.Builder
+	y := svgMargin
+	if len(c.Consonants.Rows) > 0 {
+		y = c.Consonants.writeSVG(&b, y)
+	}
+	y = c.Vowels.writeVowelSVG(&b, y)
+	for _, l := range c.Lists {
+		y += svgListHeight
+		fmt.Fprintf(&b, "<text x=\"%d\" y=\"%d\"><tspan class=\"label\">%s:</tspan> %s</text>\n",
+			svgMargin, y, html.EscapeString(l.Title), html.EscapeString(strings.Join(l.Phonemes, " ")))
+	}
+
+	width := svgMargin*2 + svgLabelWidth + svgCellWidth*len(c.Consonants.Columns)
+	if w := svgMargin*2 + svgLabelWidth + svgVowelWidth; w > width {
+		width = w
+	}
+	height := y + svgMargin
+	return fmt.Sprintf("<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%d\" height=\"%d\" viewBox=\"0 0 %d %d\">\n%s%s</svg>\n",
+		width, height, width, height, svgStyle, b.String())
+}
+
+// writeSVG draws the consonant table from the top y, returning the y below it
+func (t ChartTable) writeSVG(b *strings.Builder, y int) int {
+	y += svgTitleHeight / 2
+	fmt.Fprintf(b, "<text class=\"title\" x=\"%d\" y=\"%d\">Consonants</text>\n", svgMargin, y)
+	y += svgTitleHeight / 2
+
+	left := svgMargin + svgLabelWidth
+	for j, col := range t.Columns {
+		fmt.Fprintf(b, "<text class=\"label\" x=\"%d\" y=\"%d\" text-anchor=\"middle\">%s</text>\n",
+			left+j*svgCellWidth+svgCellWidth/2, y+svgRowHeight*2/3, html.EscapeString(col))
+	}
+	y += svgRowHeight
+
+	for i, row := range t.Rows {
+		fmt.Fprintf(b, "<text class=\"label\" x=\"%d\" y=\"%d\">%s</text>\n", svgMargin, y+svgRowHeight*2/3, html.EscapeString(row))
+		for j, cell := range t.Cells[i] {
+			x := left + j*svgCellWidth
+			switch {
+			case cell.Gap:
+				fmt.Fprintf(b, "<rect class=\"gap\" x=\"%d\" y=\"%d\" width=\"%d\" height=\"%d\"/>\n", x, y, svgCellWidth, svgRowHeight)
+				fmt.Fprintf(b, "<text class=\"gap\" x=\"%d\" y=\"%d\" text-anchor=\"middle\">–</text>\n", x+svgCellWidth/2, y+svgRowHeight*2/3)
+			case cell.Impossible:
+				fmt.Fprintf(b, "<rect class=\"impossible\" x=\"%d\" y=\"%d\" width=\"%d\" height=\"%d\"/>\n", x, y, svgCellWidth, svgRowHeight)
+			default:
+				fmt.Fprintf(b, "<rect x=\"%d\" y=\"%d\" width=\"%d\" height=\"%d\"/>\n", x, y, svgCellWidth, svgRowHeight)
+				fmt.Fprintf(b, "<text x=\"%d\" y=\"%d\" text-anchor=\"middle\">%s</text>\n",
+					x+svgCellWidth/4, y+svgRowHeight*2/3, html.EscapeString(strings.Join(cell.Left, " ")))
+				fmt.Fprintf(b, "<text x=\"%d\" y=\"%d\" text-anchor=\"middle\">%s</text>\n",
+					x+svgCellWidth*3/4, y+svgRowHeight*2/3, html.EscapeString(strings.Join(cell.Right, " ")))
+			}
+		}
+		y += svgRowHeight
+	}
+	return y
+}
+
+// writeVowelSVG draws the vowel table as the IPA vowel trapezoid from the top y, returning
+// the y below it. Each height and frontness with vowels gets a dot, with the unrounded
+// vowels to its left and the rounded to its right
+func (t ChartTable) writeVowelSVG(b *strings.Builder, y int) int {
+	y += svgTitleHeight / 2
+	fmt.Fprintf(b, "<text class=\"title\" x=\"%d\" y=\"%d\">Vowels</text>\n", svgMargin, y)
+	y += svgTitleHeight
+
+	left := svgMargin + svgLabelWidth
+	point := func(row, col int) (int, int) {
+		// the front edge slants in to half the width at the bottom, as in the IPA chart
+		shift := svgVowelWidth / 2 * row / (len(t.Rows) - 1)
+		return left + shift + (svgVowelWidth-shift)*col/(len(t.Columns)-1), y + svgVowelHeight*row/(len(t.Rows)-1)
+	}
+
+	last := len(t.Rows) - 1
+	x1, y1 := point(0, 0)
+	x2, y2 := point(0, len(t.Columns)-1)
+	x3, y3 := point(last, len(t.Columns)-1)
+	x4, y4 := point(last, 0)
+	fmt.Fprintf(b, "<polygon points=\"%d,%d %d,%d %d,%d %d,%d\"/>\n", x1, y1, x2, y2, x3, y3, x4, y4)
+	x1, y1 = point(0, 1)
+	x2, y2 = point(last, 1)
+	fmt.Fprintf(b, "<line x1=\"%d\" y1=\"%d\" x2=\"%d\" y2=\"%d\"/>\n", x1, y1, x2, y2)
+
+	for j, col := range t.Columns {
+		x, _ := point(0, j)
+		fmt.Fprintf(b, "<text class=\"label\" x=\"%d\" y=\"%d\" text-anchor=\"middle\">%s</text>\n", x, y-svgRowHeight/2, html.EscapeString(col))
+	}
+	for i, row := range t.Rows {
+		_, py := point(i, 0)
+		fmt.Fprintf(b, "<text class=\"label\" x=\"%d\" y=\"%d\">%s</text>\n", svgMargin, py+4, html.EscapeString(row))
+		for j, cell := range t.Cells[i] {
+			if len(cell.Left)+len(cell.Right) == 0 {
+				continue
+			}
+			px, py := point(i, j)
+			fmt.Fprintf(b, "<circle cx=\"%d\" cy=\"%d\" r=\"3\"/>\n", px, py)
+			if len(cell.Left) > 0 {
+				fmt.Fprintf(b, "<text x=\"%d\" y=\"%d\" text-anchor=\"end\">%s</text>\n", px-6, py+5, html.EscapeString(strings.Join(cell.Left, " ")))
+			}
+			if len(cell.Right) > 0 {
+				fmt.Fprintf(b, "<text x=\"%d\" y=\"%d\">%s</text>\n", px+6, py+5, html.EscapeString(strings.Join(cell.Right, " ")))
+			}
+		}
+	}
+	return y + svgVowelHeight + svgRowHeight/2
+}
@@ -0,0 +1,158 @@
+package phonology
+
+import (
+	"encoding/xml"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// chartInventory has consonants in and out of the table, vowels, a diphthong, and a custom
+// segment whose symbol must be escaped
+func chartInventory(t *testing.T) Inventory {
+	t.Helper()
+	inv := Inventory{}
+	for _, p := range phonemeList(t, "p", "b", "pʰ", "t", "d", "k", "m", "n", "s", "z", "h", "l", "pʼ", "kʷ", "i", "u", "a", "ə") {
+		switch p := p.(type) {
+		case Consonant:
+			inv.Consonants = append(inv.Consonants, p)
+		case Vowel:
+			inv.Vowels = append(inv.Vowels, p)
+		}
+	}
+	ai, err := NewDiphthongFromIPA("ai̯")
+	if err != nil {
+		t.Fatal(err)
+	}
+	inv.Diphthongs = []Diphthong{ai}
+	inv.Segments = []CustomSegment{{Symbol: "<&>"}}
+	return inv
+}
+
+// chartCell returns the cell of the table at the row and column with the labels
+func chartCell(t *testing.T, table ChartTable, row, column string) ChartCell {
+	t.Helper()
+	for i, r := range table.Rows {
+		for j, c := range table.Columns {
+			if r == row && c == column {
+				return table.Cells[i][j]
+			}
+		}
+	}
+	t.Fatalf("no %s %s cell in %v by %v", column, row, table.Rows, table.Columns)
+	return ChartCell{}
+}
+
+func TestChartPlacement(t *testing.T) {
+	chart := NewChart(chartInventory(t))
+
+	// only the places and manners in use are shown, in the order of the IPA chart
+	cons := chart.Consonants
+	if want := []string{"bilabial", "alveolar", "velar", "glottal"}; !reflect.DeepEqual(cons.Columns, want) {
+		t.Errorf("columns %v, want %v", cons.Columns, want)
+	}
+	if want := []string{"stop", "nasal", "fricative", "lateral approximant"}; !reflect.DeepEqual(cons.Rows, want) {
+		t.Errorf("rows %v, want %v", cons.Rows, want)
+	}
+
+	for _, tc := range []struct {
+		row, column string
+		want        ChartCell
+	}{
+		// voiceless on the left and voiced on the right, with variants side by side
+		{"stop", "bilabial", ChartCell{Left: []string{"p", "pʰ"}, Right: []string{"b"}}},
+		{"stop", "velar", ChartCell{Left: []string{"k"}}},
+		{"fricative", "alveolar", ChartCell{Left: []string{"s"}, Right: []string{"z"}}},
+		{"nasal", "velar", ChartCell{Gap: true}},
+		{"stop", "glottal", ChartCell{Gap: true}},
+		{"nasal", "glottal", ChartCell{Impossible: true}},
+		{"lateral approximant", "bilabial", ChartCell{Impossible: true}},
+	} {
+		if got := chartCell(t, cons, tc.row, tc.column); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s %s: got %+v, want %+v", tc.column, tc.row, got, tc.want)
+		}
+	}
+
+	// every height is shown for vowels, unrounded on the left and rounded on the right
+	if len(chart.Vowels.Rows) != len(heightNames)-1 || len(chart.Vowels.Columns) != len(frontnessNames)-1 {
+		t.Errorf("vowel table is %v by %v", chart.Vowels.Rows, chart.Vowels.Columns)
+	}
+	for _, tc := range []struct {
+		row, column string
+		want        ChartCell
+	}{
+		{"close", "front", ChartCell{Left: []string{"i"}}},
+		{"close", "back", ChartCell{Right: []string{"u"}}},
+		{"mid", "central", ChartCell{Left: []string{"ə"}}},
+		{"open", "front", ChartCell{Left: []string{"a"}}},
+		{"open", "back", ChartCell{}},
+	} {
+		if got := chartCell(t, chart.Vowels, tc.row, tc.column); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s %s: got %+v, want %+v", tc.row, tc.column, got, tc.want)
+		}
+	}
+
+	want := []ChartList{
+		{"ejectives", []string{"pʼ"}},
+		{"labialized", []string{"kʷ"}},
+		{"diphthongs", []string{"ai̯"}},
+		{"custom segments", []string{"<&>"}},
+	}
+	if !reflect.DeepEqual(chart.Lists, want) {
+		t.Errorf("lists %+v, want %+v", chart.Lists, want)
+	}
+}
+
+func TestChartSVG(t *testing.T) {
+	for _, inv := range []Inventory{chartInventory(t), {}} {
+		svg := NewChart(inv).SVG()
+
+		// the SVG is well formed XML, whose text is every phoneme and label
+		decoder := xml.NewDecoder(strings.NewReader(svg))
+		elements := map[string]int{}
+		text := ""
+		root := ""
+		for {
+			token, err := decoder.Token()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatalf("%v in\n%s", err, svg)
+			}
+			switch token := token.(type) {
+			case xml.StartElement:
+				if root == "" {
+					root = token.Name.Space + " " + token.Name.Local
+				}
+				elements[token.Name.Local]++
+			case xml.CharData:
+				text += string(token) + " "
+			}
+		}
+		if root != "http://www.w3.org/2000/svg svg" {
+			t.Errorf("root element is %q", root)
+		}
+
+		if len(inv.Consonants) == 0 {
+			// an empty inventory still draws the vowel trapezoid
+			if elements["polygon"] != 1 || elements["rect"] != 0 || strings.Contains(text, "Consonants") {
+				t.Errorf("drew %v for an empty inventory", elements)
+			}
+			continue
+		}
+		for _, s := range []string{"Consonants", "Vowels", "bilabial", "lateral approximant", "pʰ", "ə", "pʼ", "<&>", "ai̯"} {
+			if !strings.Contains(text, s) {
+				t.Errorf("SVG text lacks %q: %s", s, text)
+			}
+		}
+		// a rect for each of the 16 cells, and a dot for each of the 4 vowel cells
+		if elements["rect"] != 16 || elements["circle"] != 4 {
+			t.Errorf("drew %v", elements)
+		}
+		if gaps := strings.Count(svg, `<rect class="gap"`); gaps != 5 {
+			t.Errorf("drew %d gaps, want 5", gaps)
+		}
+	}
+}
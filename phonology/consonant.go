@@ -2,19 +2,20 @@ package phonology
 
 import (
 	"encoding/json"
+	"fmt"
 )
 
 // Consonant represents a consonant phoneme
 type Consonant struct {
-	Place          ConsonantPlace          `json:"place"`          // Dental, Velar, etc.
-	Manner         ConsonantManner         `json:"manner"`         // Plosive, Nasal, Approximant, etc.
-	Coarticulation ConsonantCoarticulation `json:"coarticulation"` // Labialized, Palatalized, Velarized, etc.
-	NonPulmonic    ConsonantNonPulmonic    `json:"nonpulmonic"`    // Ejective, Implosive, Velaric
-	Voiced         ConsonantVoice          `json:"voiced"`         // Voiced / Voiceless
-	Aspirated      ConsonantAspiration     `json:"aspirated"`      // Aspirated / Unaspirated
-	Lateral        ConsonantLateral        `json:"lateral"`        // Lateral / Central
-	Sibilant       ConsononantSibilance    `json:"sibilant"`       // Sibilant / Nonsibilant
-	Geminate       ConsonantGeminate       `json:"geminate"`       // Geminated / Singleton
+	Place          ConsonantPlace          `json:"place" bson:"place"`                   // Dental, Velar, etc.
+	Manner         ConsonantManner         `json:"manner" bson:"manner"`                 // Plosive, Nasal, Approximant, etc.
+	Coarticulation ConsonantCoarticulation `json:"coarticulation" bson:"coarticulation"` // Labialized, Palatalized, Velarized, etc.
+	NonPulmonic    ConsonantNonPulmonic    `json:"nonpulmonic" bson:"nonpulmonic"`       // Ejective, Implosive, Velaric
+	Voiced         ConsonantVoice          `json:"voiced" bson:"voiced"`                 // Voiced / Voiceless
+	Aspirated      ConsonantAspiration     `json:"aspirated" bson:"aspirated"`           // Aspirated / Unaspirated
+	Lateral        ConsonantLateral        `json:"lateral" bson:"lateral"`               // Lateral / Central
+	Sibilant       ConsononantSibilance    `json:"sibilant" bson:"sibilant"`             // Sibilant / Nonsibilant
+	Geminate       ConsonantGeminate       `json:"geminate" bson:"geminate"`             // Geminated / Singleton
 }
 
 // ConsonantPlace is the place of articulation for consonants
@@ -132,21 +133,26 @@ const (
 
 // ConsonantJSON is an intermediate form of the Consonant type,
 // mirroring the simplified version used on the frontend, used
-// for marshalling and unmarshalling
+// for marshalling and unmarshalling. Version 2 adds Features,
+// which carries every feature and takes precedence when present
 type ConsonantJSON struct {
-	IPA         string `json:"ipa"`
-	Manner      string `json:"manner"`
-	Place       string `json:"place"`
-	Voiced      bool   `json:"voiced"`
-	NonPulmonic string `json:"nonpulmonic"`
-	Aspirated   bool   `json:"aspirated"`
-	Lateral     bool   `json:"lateral"`
-	Sibilant    bool   `json:"sibilant"`
+	IPA         string             `json:"ipa"`
+	Manner      string             `json:"manner"`
+	Place       string             `json:"place"`
+	Voiced      bool               `json:"voiced"`
+	NonPulmonic string             `json:"nonpulmonic"`
+	Aspirated   bool               `json:"aspirated"`
+	Lateral     bool               `json:"lateral"`
+	Sibilant    bool               `json:"sibilant"`
+	Version     int                `json:"version,omitempty"`
+	Features    *ConsonantFeatures `json:"features,omitempty"`
 }
 
 // MarshalJSON to implement Marshaler interface for type Consonant
-// converts Consonant structs into the JS format used on the frontend
+// converts Consonant structs into the JS format used on the frontend,
+// along with the lossless v2 features
 func (c Consonant) MarshalJSON() ([]byte, error) {
+	features := c.Features()
 	return json.Marshal(&ConsonantJSON{
 		IPA:         c.ToIPA(),
 		Manner:      mannerToString(c.Manner),
@@ -156,13 +162,32 @@ func (c Consonant) MarshalJSON() ([]byte, error) {
 		Aspirated:   c.Aspirated == AspiratedCA,
 		Lateral:     c.Lateral == LateralCL,
 		Sibilant:    c.Sibilant == SibilantCS,
+		Version:     PhonemeJSONVersion,
+		Features:    &features,
 	})
 }
 
-// UnmarshalJSON implements the Unmarshaler interface for type Consonant
+// UnmarshalJSON implements the Unmarshaler interface for type Consonant.
+// The v2 features are used if present, and otherwise the v1 fields, in which
+// unmentioned features default to their most common values. Later versions are rejected
 func (c *Consonant) UnmarshalJSON(data []byte) error {
 	var cj ConsonantJSON
-	json.Unmarshal(data, &cj)
+	err := json.Unmarshal(data, &cj)
+	if err != nil {
+		return err
+	}
+	if cj.Version > PhonemeJSONVersion {
+		return fmt.Errorf("unsupported consonant JSON version %d, expected at most %d", cj.Version, PhonemeJSONVersion)
+	}
+
+	if cj.Features != nil {
+		decoded, err := cj.Features.Consonant()
+		if err != nil {
+			return err
+		}
+		*c = decoded
+		return nil
+	}
 
 	c.Place = placeFromString(cj.Place)
 	c.Manner = mannerFromString(cj.Manner)
@@ -304,6 +329,8 @@ func mannerToString(m ConsonantManner) string {
 		s = "tap"
 	case TrillCM:
 		s = "trill"
+	case ClickCM:
+		s = "click"
 	default:
 		s = ""
 	}
@@ -327,6 +354,8 @@ func mannerFromString(s string) ConsonantManner {
 		m = TapCM
 	case "trill":
 		m = TrillCM
+	case "click":
+		m = ClickCM
 	}
 	return m
 }
@@ -0,0 +1,407 @@
+package phonology
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DistinctiveFeature is a binary distinctive feature, in the tradition of SPE, in which
+// phonological rules and natural classes are written, like [+sonorant -continuant]
+type DistinctiveFeature uint8
+
+// DistinctiveFeature values, in the order feature bundles are written
+const (
+	UnspecifiedDF DistinctiveFeature = iota
+	SyllabicDF
+	ConsonantalDF
+	SonorantDF
+	ContinuantDF
+	DelayedReleaseDF
+	NasalDF
+	LateralDF
+	StridentDF
+	VoiceDF
+	SpreadGlottisDF
+	ConstrictedGlottisDF
+	LabialDF
+	RoundDF
+	CoronalDF
+	AnteriorDF
+	DistributedDF
+	DorsalDF
+	HighDF
+	LowDF
+	BackDF
+	FrontDF
+	TenseDF
+	ATRDF
+	PharyngealDF
+	RhoticDF
+	LongDF
+)
+
+// distinctiveFeatureNames are the names features are written with, indexed by feature
+var distinctiveFeatureNames = []string{"", "syl", "cons", "son", "cont", "delrel", "nasal", "lat", "strid", "voice", "sg", "cg",
+	"lab", "round", "cor", "ant", "dist", "dors", "high", "low", "back", "front", "tense", "atr", "phar", "rhotic", "long"}
+
+// distinctiveFeatureAliases are longer names also accepted when parsing
+var distinctiveFeatureAliases = map[string]DistinctiveFeature{
+	"syllabic": SyllabicDF, "consonantal": ConsonantalDF, "sonorant": SonorantDF, "continuant": ContinuantDF,
+	"delayed-release": DelayedReleaseDF, "nas": NasalDF, "lateral": LateralDF, "strident": StridentDF, "voiced": VoiceDF,
+	"spread": SpreadGlottisDF, "spread-glottis": SpreadGlottisDF, "constricted": ConstrictedGlottisDF,
+	"constricted-glottis": ConstrictedGlottisDF, "labial": LabialDF, "rnd": RoundDF, "coronal": CoronalDF,
+	"anterior": AnteriorDF, "distributed": DistributedDF, "dorsal": DorsalDF, "hi": HighDF, "lo": LowDF,
+	"bk": BackDF, "fr": FrontDF, "pharyngeal": PharyngealDF, "rhot": RhoticDF,
+}
+
+func (f DistinctiveFeature) String() string {
+	if int(f) < len(distinctiveFeatureNames) {
+		return distinctiveFeatureNames[f]
+	}
+	return ""
+}
+
+// FeatureBundle is a set of distinctive feature values, true for + and false for -.
+// A phoneme's matrix holds every feature that applies to it, leaving out features that
+// don't, like [anterior] for non-coronals. A bundle used as a pattern, like [+voice -son],
+// holds only the features it specifies
+type FeatureBundle map[DistinctiveFeature]bool
+
+// DistinctiveFeatures returns the feature matrix of a phoneme. Diphthongs have the features
+// of their nucleus, and custom segments those of their features, if any
+func DistinctiveFeatures(p Phoneme) FeatureBundle {
+	p = standIn(p)
+	if v, isVowel := p.asVowel(); isVowel {
+		return vowelFeatures(v)
+	}
+	if c, isConsonant := p.asConsonant(); isConsonant {
+		return consonantFeatures(c)
+	}
+	return FeatureBundle{}
+}
+
+func vowelFeatures(v Vowel) FeatureBundle {
+	b := FeatureBundle{
+		SyllabicDF:           true,
+		ConsonantalDF:        false,
+		SonorantDF:           true,
+		ContinuantDF:         true,
+		NasalDF:              v.Nasal == NasalVN,
+		LateralDF:            false,
+		VoiceDF:              v.Phonation != DevoicedVP,
+		SpreadGlottisDF:      v.Phonation == BreathyVP,
+		ConstrictedGlottisDF: v.Phonation == CreakyVP,
+		LabialDF:             v.Rounding == RoundedVR,
+		RoundDF:              v.Rounding == RoundedVR,
+		CoronalDF:            false,
+		DorsalDF:             true,
+		HighDF:               v.Height == CloseVH || v.Height == NearCloseVH,
+		LowDF:                v.Height == NearOpenVH || v.Height == OpenVH,
+		BackDF:               v.Frontness == BackVF,
+		FrontDF:              v.Frontness == FrontVF,
+		PharyngealDF:         v.Pharyngealization == PharyngealizedVPH,
+		RhoticDF:             v.Rhoticity == RhoticVRH,
+		LongDF:               v.Length == LongVL || v.Length == ExtraLongVL,
+	}
+	if v.Tenseness != UnspecifiedVTS {
+		b[TenseDF] = v.Tenseness == TenseVTS
+	}
+	if v.TongueRoot == AdvancedVTR || v.TongueRoot == RetractedVTR {
+		b[ATRDF] = v.TongueRoot == AdvancedVTR
+	}
+	return b
+}
+
+func consonantFeatures(c Consonant) FeatureBundle {
+	obstruent := c.Manner == StopCM || c.Manner == AffricateCM || c.Manner == FricativeCM || c.Manner == ClickCM || c.NonPulmonic == VelaricCNP
+	coronal := c.Place == DentalCP || c.Place == AlveolarCP || c.Place == PostAlveolarCP || c.Place == RetroflexCP
+	dorsal := c.Place == PalatalCP || c.Place == VelarCP || c.Place == UvularCP
+	// glides and laryngeals are not consonantal, while liquids are
+	glide := c.Manner == ApproximantCM && c.Lateral != LateralCL && !coronal
+
+	b := FeatureBundle{
+		SyllabicDF:           false,
+		ConsonantalDF:        !glide && c.Place != GlottalCP,
+		SonorantDF:           !obstruent,
+		ContinuantDF:         c.Manner == FricativeCM || c.Manner == ApproximantCM || c.Manner == TapCM || c.Manner == TrillCM,
+		NasalDF:              c.Manner == NasalCM,
+		LateralDF:            c.Lateral == LateralCL,
+		VoiceDF:              c.Voiced == VoicedCV || c.Voiced == PrevoicedCV,
+		SpreadGlottisDF:      c.Aspirated == AspiratedCA || (c.Place == GlottalCP && c.Manner == FricativeCM && c.Voiced != VoicedCV),
+		ConstrictedGlottisDF: c.NonPulmonic == EjectiveCNP || c.NonPulmonic == ImplosiveCNP || (c.Place == GlottalCP && c.Manner == StopCM),
+		LabialDF:             c.Place == BilabialCP || c.Place == LabioDentalCP || c.Coarticulation == LabialCC,
+		RoundDF:              c.Coarticulation == LabialCC || (c.Place == BilabialCP && glide),
+		CoronalDF:            coronal,
+		DorsalDF:             dorsal || c.Coarticulation == PalatalCC || c.Coarticulation == VelarCC || (c.Place == BilabialCP && glide),
+		PharyngealDF:         c.Place == PharyngealCP || c.Coarticulation == PharyngealCC,
+		RhoticDF:             coronal && c.Lateral != LateralCL && (c.Manner == TapCM || c.Manner == TrillCM || c.Manner == ApproximantCM),
+		LongDF:               c.Geminate == GeminateCG,
+	}
+	if obstruent {
+		b[DelayedReleaseDF] = c.Manner == AffricateCM || c.Manner == FricativeCM
+		b[StridentDF] = c.Sibilant == SibilantCS
+	}
+	if coronal {
+		b[AnteriorDF] = c.Place == DentalCP || c.Place == AlveolarCP
+		b[DistributedDF] = c.Place == DentalCP || c.Place == PostAlveolarCP
+	}
+	if b[DorsalDF] {
+		switch {
+		case c.Place == PalatalCP || c.Coarticulation == PalatalCC:
+			b[HighDF], b[LowDF], b[BackDF], b[FrontDF] = true, false, false, true
+		case c.Place == UvularCP:
+			b[HighDF], b[LowDF], b[BackDF], b[FrontDF] = false, false, true, false
+		default: // velar, velarized, and labial-velar /w/
+			b[HighDF], b[LowDF], b[BackDF], b[FrontDF] = true, false, true, false
+		}
+	}
+	return b
+}
+
+// Matches returns whether a phoneme has every feature value in the bundle
+func (b FeatureBundle) Matches(p Phoneme) bool {
+	matrix := DistinctiveFeatures(p)
+	for f, value := range b {
+		if v, applies := matrix[f]; !applies || v != value {
+			return false
+		}
+	}
+	return true
+}
+
+// Select returns the phonemes of an inventory that match the bundle
+func (b FeatureBundle) Select(inventory []Phoneme) []Phoneme {
+	class := []Phoneme{}
+	for _, p := range inventory {
+		if b.Matches(p) {
+			class = append(class, p)
+		}
+	}
+	return class
+}
+
+// String writes the bundle in feature-bundle notation, like [+voice -son]
+func (b FeatureBundle) String() string {
+	values := []string{}
+	for _, f := range b.sortedFeatures() {
+		sign := "-"
+		if b[f] {
+			sign = "+"
+		}
+		values = append(values, sign+f.String())
+	}
+	return "[" + strings.Join(values, " ") + "]"
+}
+
+func (b FeatureBundle) sortedFeatures() []DistinctiveFeature {
+	features := []DistinctiveFeature{}
+	for f := range b {
+		features = append(features, f)
+	}
+	sort.Slice(features, func(i, j int) bool { return features[i] < features[j] })
+	return features
+}
+
+// IsFeatureBundle returns whether a string is written in feature-bundle notation,
+// as opposed to IPA
+func IsFeatureBundle(s string) bool {
+	return strings.HasPrefix(strings.TrimSpace(s), "[")
+}
+
+// ParseFeatureBundle parses feature-bundle notation, like "[+voice -son]" or
+// "[+sonorant, -continuant]". Features are written with a + or - and their name
+func ParseFeatureBundle(s string) (FeatureBundle, error) {
+	trimmed := strings.TrimSpace(s)
+	if !strings.HasPrefix(trimmed, "[") || !strings.HasSuffix(trimmed, "]") {
+		return nil, fmt.Errorf("feature bundle %q must be enclosed in brackets", s)
+	}
+	fields := strings.FieldsFunc(trimmed[1:len(trimmed)-1], func(r rune) bool {
+		return r == ' ' || r == ',' || r == '\t'
+	})
+
+	b := FeatureBundle{}
+	for _, field := range fields {
+		runes := []rune(field)
+		var value bool
+		switch runes[0] {
+		case '+':
+			value = true
+		case '-', '−': // hyphen or minus sign
+			value = false
+		default:
+			return nil, fmt.Errorf("feature %q in %q must start with + or -", field, s)
+		}
+
+		f, err := parseDistinctiveFeature(strings.ToLower(string(runes[1:])))
+		if err != nil {
+			return nil, err
+		}
+		if old, found := b[f]; found && old != value {
+			return nil, fmt.Errorf("feature bundle %q is both + and - %s", s, f)
+		}
+		b[f] = value
+	}
+	return b, nil
+}
+
+func parseDistinctiveFeature(name string) (DistinctiveFeature, error) {
+	for i, n := range distinctiveFeatureNames {
+		if i > 0 && n == name {
+			return DistinctiveFeature(i), nil
+		}
+	}
+	if f, found := distinctiveFeatureAliases[name]; found {
+		return f, nil
+	}
+	return UnspecifiedDF, fmt.Errorf("unknown distinctive feature %q", name)
+}
+
+// maxExactSpecification is the largest specification searched for exhaustively. Classes that
+// need more features than this are specified greedily, which may use a feature or two too many
+const maxExactSpecification = 6
+
+// MinimalSpecification returns the smallest feature bundle that picks out exactly the class
+// from the inventory, preferring features in the order they are written when several bundles
+// are as small. Phonemes are compared by IPA, and every member of the class must be in the
+// inventory. It returns an error if the class is not natural, i.e. no bundle picks it out
+func MinimalSpecification(class []Phoneme, inventory []Phoneme) (FeatureBundle, error) {
+	if len(class) == 0 {
+		return nil, errors.New("natural class is empty")
+	}
+	inClass := map[string]bool{}
+	for _, p := range class {
+		inClass[p.ToIPA()] = true
+	}
+	inInventory := map[string]bool{}
+	for _, p := range inventory {
+		inInventory[p.ToIPA()] = true
+	}
+	for ipa := range inClass {
+		if !inInventory[ipa] {
+			return nil, fmt.Errorf("/%s/ is not in the inventory", ipa)
+		}
+	}
+
+	// the features every member shares
+	shared := DistinctiveFeatures(class[0])
+	for _, p := range class[1:] {
+		matrix := DistinctiveFeatures(p)
+		for f, value := range shared {
+			if v, applies := matrix[f]; !applies || v != value {
+				delete(shared, f)
+			}
+		}
+	}
+
+	outsiders := []Phoneme{}
+	for _, p := range inventory {
+		if !inClass[p.ToIPA()] {
+			outsiders = append(outsiders, p)
+		}
+	}
+	intruders := []string{}
+	for _, p := range shared.Select(outsiders) {
+		intruders = append(intruders, "/"+p.ToIPA()+"/")
+	}
+	if len(intruders) > 0 {
+		return nil, fmt.Errorf("not a natural class: every bundle its members share, %s, also picks out %s", shared, strings.Join(intruders, " "))
+	}
+
+	// each shared feature excludes some of the outsiders, and the specification must exclude them all
+	features := []DistinctiveFeature{}
+	excludes := [][]bool{}
+	for _, f := range shared.sortedFeatures() {
+		excluded := make([]bool, len(outsiders))
+		useful := false
+		for i, p := range outsiders {
+			if v, applies := DistinctiveFeatures(p)[f]; !applies || v != shared[f] {
+				excluded[i], useful = true, true
+			}
+		}
+		if useful {
+			features = append(features, f)
+			excludes = append(excludes, excluded)
+		}
+	}
+
+	chosen := exactCover(excludes, len(outsiders))
+	if chosen == nil {
+		chosen = greedyCover(excludes, len(outsiders))
+	}
+	b := FeatureBundle{}
+	for _, i := range chosen {
+		b[features[i]] = shared[features[i]]
+	}
+	return b, nil
+}
+
+// exactCover returns the indexes of the fewest sets that together exclude all n outsiders,
+// trying sets in order so that the earliest features are preferred, or nil if that takes
+// more than maxExactSpecification sets
+func exactCover(excludes [][]bool, n int) []int {
+	if n == 0 {
+		return []int{}
+	}
+	for size := 1; size <= maxExactSpecification && size <= len(excludes); size++ {
+		if chosen := coverWith(excludes, n, size, 0, []int{}); chosen != nil {
+			return chosen
+		}
+	}
+	return nil
+}
+
+func coverWith(excludes [][]bool, n int, size int, start int, chosen []int) []int {
+	if len(chosen) == size {
+		for i := 0; i < n; i++ {
+			covered := false
+			for _, set := range chosen {
+				if excludes[set][i] {
+					covered = true
+					break
+				}
+			}
+			if !covered {
+				return nil
+			}
+		}
+		return chosen
+	}
+	for set := start; set < len(excludes); set++ {
+		next := append(append([]int{}, chosen...), set)
+		if res := coverWith(excludes, n, size, set+1, next); res != nil {
+			return res
+		}
+	}
+	return nil
+}
+
+// greedyCover repeatedly takes the set excluding the most outsiders still included
+func greedyCover(excludes [][]bool, n int) []int {
+	covered := make([]bool, n)
+	chosen := []int{}
+	for remaining := n; remaining > 0; {
+		best, bestCount := -1, 0
+		for set, excluded := range excludes {
+			count := 0
+			for i := range excluded {
+				if excluded[i] && !covered[i] {
+					count++
+				}
+			}
+			if count > bestCount {
+				best, bestCount = set, count
+			}
+		}
+		chosen = append(chosen, best)
+		for i, excluded := range excludes[best] {
+			if excluded && !covered[i] {
+				covered[i] = true
+				remaining--
+			}
+		}
+	}
+	sort.Ints(chosen)
+	return chosen
+}
@@ -0,0 +1,130 @@
+package phonology
+
+import (
+	"strings"
+	"testing"
+)
+
+// phonemeList parses each IPA string as one phoneme
+func phonemeList(t *testing.T, ipa ...string) []Phoneme {
+	t.Helper()
+	ps := []Phoneme{}
+	for _, s := range ipa {
+		word, err := ParseWord(s)
+		if err != nil || len(word) != 1 {
+			t.Fatalf("/%s/ is not one phoneme: %v", s, err)
+		}
+		ps = append(ps, word[0])
+	}
+	return ps
+}
+
+func ipaList(ps []Phoneme) string {
+	res := []string{}
+	for _, p := range ps {
+		res = append(res, p.ToIPA())
+	}
+	return strings.Join(res, " ")
+}
+
+// smallInventory has stops, fricatives, nasals and a liquid at three places, and three vowels
+var smallInventory = []string{"p", "t", "k", "b", "d", "g", "s", "z", "m", "n", "l", "a", "i", "u"}
+
+func TestParseFeatureBundle(t *testing.T) {
+	for _, tc := range []struct {
+		s, want string // want is the bundle written back, or "" if s is invalid
+	}{
+		{"[+voice -son]", "[-son +voice]"},
+		{"[+sonorant, -continuant]", "[+son -cont]"},
+		{"[ −voice\t+nasal ]", "[+nasal -voice]"},
+		{"[]", "[]"},
+		{"[+voice +voiced]", "[+voice]"},
+		{"+voice", ""},
+		{"[voice]", ""},
+		{"[+fuzzy]", ""},
+		{"[+voice -voice]", ""},
+	} {
+		b, err := ParseFeatureBundle(tc.s)
+		if tc.want == "" {
+			if err == nil {
+				t.Errorf("%q: parsed as %s, expected an error", tc.s, b)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: %v", tc.s, err)
+		} else if b.String() != tc.want {
+			t.Errorf("%q: parsed as %s, want %s", tc.s, b, tc.want)
+		}
+	}
+}
+
+func TestNaturalClasses(t *testing.T) {
+	inventory := phonemeList(t, smallInventory...)
+	for _, tc := range []struct {
+		bundle, class string
+	}{
+		{"[+syl]", "a i u"},
+		{"[-syl]", "p t k b d g s z m n l"},
+		{"[+nasal]", "m n"},
+		{"[-son +voice]", "b d g z"},
+		{"[-son -cont]", "p t k b d g"},
+		{"[+son +cons]", "m n l"},
+		{"[+cor -son]", "t d s z"},
+		{"[+lab]", "p b m u"}, // rounding is labial
+		{"[+dors -syl]", "k g"},
+		{"[+high]", "k g i u"},
+		{"[+lat]", "l"},
+		{"[+strid]", "s z"},
+		{"[+nasal -voice]", ""},
+	} {
+		b, err := ParseFeatureBundle(tc.bundle)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := ipaList(b.Select(inventory)); got != tc.class {
+			t.Errorf("%s: selected /%s/, want /%s/", tc.bundle, got, tc.class)
+		}
+	}
+}
+
+func TestMinimalSpecification(t *testing.T) {
+	inventory := phonemeList(t, smallInventory...)
+	for _, tc := range []struct {
+		class []string // in the order of the inventory
+		want  string   // the bundle, or "" if the class is not natural in the inventory
+	}{
+		{[]string{"a", "i", "u"}, "[+syl]"},
+		{[]string{"m", "n"}, "[+nasal]"},
+		{[]string{"l"}, "[+lat]"},
+		{[]string{"p", "t", "k"}, "[-cont -voice]"},
+		{[]string{"b", "d", "g", "z"}, "[-son +voice]"},
+		// sonorants have no [delrel], so it alone sets the stops apart
+		{[]string{"p", "t", "k", "b", "d", "g"}, "[-delrel]"},
+		// /p/ and /d/ share nothing that leaves out /t/ and /b/
+		{[]string{"p", "d"}, ""},
+		{[]string{"a", "l"}, ""},
+		// a member missing from the inventory, and no members at all
+		{[]string{"x"}, ""},
+		{[]string{}, ""},
+	} {
+		b, err := MinimalSpecification(phonemeList(t, tc.class...), inventory)
+		if tc.want == "" {
+			if err == nil {
+				t.Errorf("/%s/: specified as %s, expected an error", strings.Join(tc.class, " "), b)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("/%s/: %v", strings.Join(tc.class, " "), err)
+			continue
+		}
+		if b.String() != tc.want {
+			t.Errorf("/%s/: specified as %s, want %s", strings.Join(tc.class, " "), b, tc.want)
+		}
+		// the specification picks out the class and nothing else
+		if got := ipaList(b.Select(inventory)); got != strings.Join(tc.class, " ") {
+			t.Errorf("/%s/: %s selects /%s/", strings.Join(tc.class, " "), b, got)
+		}
+	}
+}
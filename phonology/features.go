@@ -0,0 +1,176 @@
+package phonology
+
+import "fmt"
+
+// PhonemeJSONVersion is the version of the JSON encoding written for phonemes. Version 1,
+// the frontend's shape, flattens binary features to bools and drops some features entirely.
+// Version 2 adds a features object carrying every feature, so that phonemes survive a round
+// trip unchanged, including unspecified features in patterns like Consonant{}. Phonemes
+// written by a later version fail to decode
+const PhonemeJSONVersion = 2
+
+// ConsonantFeatures names every feature of a consonant, for the v2 JSON encoding.
+// An empty string means the feature is unspecified
+type ConsonantFeatures struct {
+	Place          string `json:"place"`
+	Manner         string `json:"manner"`
+	Coarticulation string `json:"coarticulation"`
+	NonPulmonic    string `json:"nonpulmonic"`
+	Voice          string `json:"voice"`
+	Aspiration     string `json:"aspiration"`
+	Laterality     string `json:"laterality"`
+	Sibilance      string `json:"sibilance"`
+	Gemination     string `json:"gemination"`
+}
+
+// VowelFeatures names every feature of a vowel, for the v2 JSON encoding.
+// An empty string means the feature is unspecified
+type VowelFeatures struct {
+	Height    string `json:"height"`
+	Frontness string `json:"frontness"`
+	Phonation string `json:"phonation"`
+	Rounding  string `json:"rounding"`
+	Nasality  string `json:"nasality"`
+	Length    string `json:"length"`
+
+	TongueRoot        string `json:"tongueRoot"`
+	Tenseness         string `json:"tenseness"`
+	Rhoticity         string `json:"rhoticity"`
+	Pharyngealization string `json:"pharyngealization"`
+}
+
+// Feature value names, indexed by value. The unspecified value of each is ""
+var (
+	placeNames          = []string{"", "bilabial", "labio-dental", "dental", "alveolar", "post-alveolar", "retroflex", "palatal", "velar", "uvular", "pharyngeal", "glottal"}
+	mannerNames         = []string{"", "nasal", "stop", "affricate", "fricative", "approximant", "tap", "trill", "click"}
+	coarticulationNames = []string{"", "none", "labialized", "palatalized", "velarized", "pharyngealized", "prenasalized"}
+	nonPulmonicNames    = []string{"", "pulmonic", "ejective", "implosive", "velaric"}
+	voiceNames          = []string{"", "voiceless", "voiced", "prevoiced"}
+	aspirationNames     = []string{"", "unaspirated", "aspirated"}
+	lateralityNames     = []string{"", "central", "lateral"}
+	sibilanceNames      = []string{"", "nonsibilant", "sibilant"}
+	geminationNames     = []string{"", "singleton", "geminate"}
+
+	heightNames    = []string{"", "close", "near-close", "close-mid", "mid", "open-mid", "near-open", "open"}
+	frontnessNames = []string{"", "front", "central", "back"}
+	phonationNames = []string{"", "modal", "devoiced", "creaky", "breathy"}
+	roundingNames  = []string{"", "rounded", "unrounded"}
+	nasalityNames  = []string{"", "oral", "nasal"}
+	lengthNames    = []string{"", "short", "long", "extra-short", "extra-long"}
+
+	tongueRootNames        = []string{"", "neutral", "advanced", "retracted"}
+	tensenessNames         = []string{"", "tense", "lax"}
+	rhoticityNames         = []string{"", "nonrhotic", "rhotic"}
+	pharyngealizationNames = []string{"", "plain", "pharyngealized"}
+)
+
+// featureName returns the name of a feature value from its table
+func featureName(names []string, value uint8) string {
+	if int(value) < len(names) {
+		return names[value]
+	}
+	return ""
+}
+
+// featureValue returns the value of a feature from its name in the table
+func featureValue(feature string, names []string, name string) (uint8, error) {
+	for i, n := range names {
+		if n == name {
+			return uint8(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown %s %q", feature, name)
+}
+
+// Features returns the names of all of the consonant's features
+func (c Consonant) Features() ConsonantFeatures {
+	return ConsonantFeatures{
+		Place:          featureName(placeNames, uint8(c.Place)),
+		Manner:         featureName(mannerNames, uint8(c.Manner)),
+		Coarticulation: featureName(coarticulationNames, uint8(c.Coarticulation)),
+		NonPulmonic:    featureName(nonPulmonicNames, uint8(c.NonPulmonic)),
+		Voice:          featureName(voiceNames, uint8(c.Voiced)),
+		Aspiration:     featureName(aspirationNames, uint8(c.Aspirated)),
+		Laterality:     featureName(lateralityNames, uint8(c.Lateral)),
+		Sibilance:      featureName(sibilanceNames, uint8(c.Sibilant)),
+		Gemination:     featureName(geminationNames, uint8(c.Geminate)),
+	}
+}
+
+// Consonant returns the consonant with the named features, or an error naming the
+// first feature value it does not know
+func (f ConsonantFeatures) Consonant() (Consonant, error) {
+	var c Consonant
+	features := []struct {
+		feature string
+		names   []string
+		name    string
+		set     func(uint8)
+	}{
+		{"place", placeNames, f.Place, func(v uint8) { c.Place = ConsonantPlace(v) }},
+		{"manner", mannerNames, f.Manner, func(v uint8) { c.Manner = ConsonantManner(v) }},
+		{"coarticulation", coarticulationNames, f.Coarticulation, func(v uint8) { c.Coarticulation = ConsonantCoarticulation(v) }},
+		{"nonpulmonic", nonPulmonicNames, f.NonPulmonic, func(v uint8) { c.NonPulmonic = ConsonantNonPulmonic(v) }},
+		{"voice", voiceNames, f.Voice, func(v uint8) { c.Voiced = ConsonantVoice(v) }},
+		{"aspiration", aspirationNames, f.Aspiration, func(v uint8) { c.Aspirated = ConsonantAspiration(v) }},
+		{"laterality", lateralityNames, f.Laterality, func(v uint8) { c.Lateral = ConsonantLateral(v) }},
+		{"sibilance", sibilanceNames, f.Sibilance, func(v uint8) { c.Sibilant = ConsononantSibilance(v) }},
+		{"gemination", geminationNames, f.Gemination, func(v uint8) { c.Geminate = ConsonantGeminate(v) }},
+	}
+	for _, feature := range features {
+		v, err := featureValue(feature.feature, feature.names, feature.name)
+		if err != nil {
+			return Consonant{}, err
+		}
+		feature.set(v)
+	}
+	return c, nil
+}
+
+// Features returns the names of all of the vowel's features
+func (v Vowel) Features() VowelFeatures {
+	return VowelFeatures{
+		Height:    featureName(heightNames, uint8(v.Height)),
+		Frontness: featureName(frontnessNames, uint8(v.Frontness)),
+		Phonation: featureName(phonationNames, uint8(v.Phonation)),
+		Rounding:  featureName(roundingNames, uint8(v.Rounding)),
+		Nasality:  featureName(nasalityNames, uint8(v.Nasal)),
+		Length:    featureName(lengthNames, uint8(v.Length)),
+
+		TongueRoot:        featureName(tongueRootNames, uint8(v.TongueRoot)),
+		Tenseness:         featureName(tensenessNames, uint8(v.Tenseness)),
+		Rhoticity:         featureName(rhoticityNames, uint8(v.Rhoticity)),
+		Pharyngealization: featureName(pharyngealizationNames, uint8(v.Pharyngealization)),
+	}
+}
+
+// Vowel returns the vowel with the named features, or an error naming the
+// first feature value it does not know
+func (f VowelFeatures) Vowel() (Vowel, error) {
+	var v Vowel
+	features := []struct {
+		feature string
+		names   []string
+		name    string
+		set     func(uint8)
+	}{
+		{"height", heightNames, f.Height, func(x uint8) { v.Height = VowelHeight(x) }},
+		{"frontness", frontnessNames, f.Frontness, func(x uint8) { v.Frontness = VowelFrontness(x) }},
+		{"phonation", phonationNames, f.Phonation, func(x uint8) { v.Phonation = VowelPhonation(x) }},
+		{"rounding", roundingNames, f.Rounding, func(x uint8) { v.Rounding = VowelRounding(x) }},
+		{"nasality", nasalityNames, f.Nasality, func(x uint8) { v.Nasal = VowelNasality(x) }},
+		{"length", lengthNames, f.Length, func(x uint8) { v.Length = VowelLength(x) }},
+		{"tongue root", tongueRootNames, f.TongueRoot, func(x uint8) { v.TongueRoot = VowelTongueRoot(x) }},
+		{"tenseness", tensenessNames, f.Tenseness, func(x uint8) { v.Tenseness = VowelTenseness(x) }},
+		{"rhoticity", rhoticityNames, f.Rhoticity, func(x uint8) { v.Rhoticity = VowelRhoticity(x) }},
+		{"pharyngealization", pharyngealizationNames, f.Pharyngealization, func(x uint8) { v.Pharyngealization = VowelPharyngealization(x) }},
+	}
+	for _, feature := range features {
+		x, err := featureValue(feature.feature, feature.names, feature.name)
+		if err != nil {
+			return Vowel{}, err
+		}
+		feature.set(x)
+	}
+	return v, nil
+}
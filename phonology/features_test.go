@@ -0,0 +1,199 @@
+package phonology
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// eachCombination calls f with every combination of feature values, where the value of
+// feature i ranges from first up to but excluding sizes[i]
+func eachCombination(sizes []int, first uint8, f func(values []uint8)) {
+	values := make([]uint8, len(sizes))
+	var walk func(i int)
+	walk = func(i int) {
+		if i == len(sizes) {
+			f(values)
+			return
+		}
+		for v := first; int(v) < sizes[i]; v++ {
+			values[i] = v
+			walk(i + 1)
+		}
+	}
+	walk(0)
+}
+
+// eachConsonant calls f with every consonant, including those with unspecified features if
+// first is 0, or only fully specified ones if it is 1
+func eachConsonant(first uint8, f func(Consonant)) {
+	sizes := []int{len(placeNames), len(mannerNames), len(coarticulationNames), len(nonPulmonicNames), len(voiceNames),
+		len(aspirationNames), len(lateralityNames), len(sibilanceNames), len(geminationNames)}
+	eachCombination(sizes, first, func(v []uint8) {
+		f(Consonant{
+			Place:          ConsonantPlace(v[0]),
+			Manner:         ConsonantManner(v[1]),
+			Coarticulation: ConsonantCoarticulation(v[2]),
+			NonPulmonic:    ConsonantNonPulmonic(v[3]),
+			Voiced:         ConsonantVoice(v[4]),
+			Aspirated:      ConsonantAspiration(v[5]),
+			Lateral:        ConsonantLateral(v[6]),
+			Sibilant:       ConsononantSibilance(v[7]),
+			Geminate:       ConsonantGeminate(v[8]),
+		})
+	})
+}
+
+// eachVowel calls f with every vowel, including those with unspecified features if first
+// is 0, or only fully specified ones if it is 1
+func eachVowel(first uint8, f func(Vowel)) {
+	sizes := []int{len(heightNames), len(frontnessNames), len(phonationNames), len(roundingNames), len(nasalityNames),
+		len(lengthNames), len(tongueRootNames), len(tensenessNames), len(rhoticityNames), len(pharyngealizationNames)}
+	eachCombination(sizes, first, func(v []uint8) {
+		f(Vowel{
+			Height:            VowelHeight(v[0]),
+			Frontness:         VowelFrontness(v[1]),
+			Phonation:         VowelPhonation(v[2]),
+			Rounding:          VowelRounding(v[3]),
+			Nasal:             VowelNasality(v[4]),
+			Length:            VowelLength(v[5]),
+			TongueRoot:        VowelTongueRoot(v[6]),
+			Tenseness:         VowelTenseness(v[7]),
+			Rhoticity:         VowelRhoticity(v[8]),
+			Pharyngealization: VowelPharyngealization(v[9]),
+		})
+	})
+}
+
+// jsonFirstValue is where the JSON round trips start each feature: with unspecified values, or
+// only with specified ones in short mode, as the whole cross product takes a while
+func jsonFirstValue() uint8 {
+	if testing.Short() {
+		return 1
+	}
+	return 0
+}
+
+func TestConsonantJSONRoundTrip(t *testing.T) {
+	eachConsonant(jsonFirstValue(), func(c Consonant) {
+		data, err := json.Marshal(c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var decoded Consonant
+		if err := json.Unmarshal(data, &decoded); err != nil {
+			t.Fatalf("%s: %v", data, err)
+		}
+		if decoded != c {
+			t.Fatalf("%+v decoded from %s as %+v", c.Features(), data, decoded.Features())
+		}
+		if again, _ := json.Marshal(decoded); !bytes.Equal(again, data) {
+			t.Fatalf("%+v encoded as %s, then as %s", c.Features(), data, again)
+		}
+	})
+}
+
+func TestVowelJSONRoundTrip(t *testing.T) {
+	eachVowel(jsonFirstValue(), func(v Vowel) {
+		data, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var decoded Vowel
+		if err := json.Unmarshal(data, &decoded); err != nil {
+			t.Fatalf("%s: %v", data, err)
+		}
+		if decoded != v {
+			t.Fatalf("%+v decoded from %s as %+v", v.Features(), data, decoded.Features())
+		}
+		if again, _ := json.Marshal(decoded); !bytes.Equal(again, data) {
+			t.Fatalf("%+v encoded as %s, then as %s", v.Features(), data, again)
+		}
+	})
+}
+
+// IPA has no letters for many feature combinations, like sibilant nasals, so a consonant is
+// written as the nearest one it has. Reading that IPA back must give a consonant written the
+// same way, which in turn survives the round trip unchanged
+func TestConsonantIPARoundTrip(t *testing.T) {
+	eachConsonant(1, func(c Consonant) {
+		ipa := c.ToIPA()
+		if strings.Contains(ipa, "C") {
+			return // no letter at all, as for velar clicks
+		}
+		parsed, err := NewConsonantFromIPA(ipa)
+		if err != nil {
+			t.Fatalf("%+v written as /%s/: %v", c.Features(), ipa, err)
+		}
+		if parsed.ToIPA() != ipa {
+			t.Fatalf("%+v written as /%s/, read back as /%s/", c.Features(), ipa, parsed.ToIPA())
+		}
+		if again, err := NewConsonantFromIPA(parsed.ToIPA()); err != nil || again != parsed {
+			t.Fatalf("/%s/ read as %+v, then as %+v", ipa, parsed.Features(), again.Features())
+		}
+	})
+}
+
+// as for consonants, a vowel is written as the nearest one IPA has a letter for
+func TestVowelIPARoundTrip(t *testing.T) {
+	eachVowel(1, func(v Vowel) {
+		ipa := v.ToIPA()
+		parsed, err := NewVowelFromIPA(ipa)
+		if err != nil {
+			t.Fatalf("%+v written as /%s/: %v", v.Features(), ipa, err)
+		}
+		if parsed.ToIPA() != ipa {
+			t.Fatalf("%+v written as /%s/, read back as /%s/", v.Features(), ipa, parsed.ToIPA())
+		}
+		if again, err := NewVowelFromIPA(parsed.ToIPA()); err != nil || again != parsed {
+			t.Fatalf("/%s/ read as %+v, then as %+v", ipa, parsed.Features(), again.Features())
+		}
+	})
+}
+
+func TestLaterPhonemeJSONVersionsAreRejected(t *testing.T) {
+	var c Consonant
+	if err := json.Unmarshal([]byte(`{"ipa": "p", "version": 3, "features": {"place": "bilabial"}}`), &c); err == nil {
+		t.Errorf("consonant of version 3 decoded as %+v", c.Features())
+	}
+	var v Vowel
+	if err := json.Unmarshal([]byte(`{"ipa": "a", "version": 3, "features": {"height": "open"}}`), &v); err == nil {
+		t.Errorf("vowel of version 3 decoded as %+v", v.Features())
+	}
+
+	// the frontend's v1 phonemes have no version at all
+	if err := json.Unmarshal([]byte(`{"ipa": "p", "manner": "stop", "place": "bilabial"}`), &c); err != nil {
+		t.Errorf("v1 consonant: %v", err)
+	}
+	if err := json.Unmarshal([]byte(`{"ipa": "a", "height": "open", "frontness": "front"}`), &v); err != nil {
+		t.Errorf("v1 vowel: %v", err)
+	}
+}
+
+func TestV1VowelsMatchTheirIPA(t *testing.T) {
+	// every vowel letter but the rhotic ones, as v1 has no rhoticity
+	for _, ipa := range []string{"i", "y", "ɪ", "ʏ", "e", "ø", "ɛ", "œ", "æ", "a", "ɨ", "ʉ", "ə", "ɵ", "ɞ", "ɐ",
+		"ɯ", "u", "ʊ", "ɤ", "o", "ʌ", "ɔ", "ɑ", "ɒ", "i\u0303", "uː", "ɛ\u0303ː"} {
+		want, err := NewVowelFromIPA(ipa)
+		if err != nil {
+			t.Fatal(err)
+		}
+		v1 := VowelJSON{
+			IPA:       ipa,
+			Height:    heightToString(want.Height),
+			Frontness: frontnessToString(want.Frontness),
+			Rounding:  want.Rounding == RoundedVR,
+			Nasal:     want.Nasal == NasalVN,
+			Length:    want.Length == LongVL,
+		}
+		data, _ := json.Marshal(v1)
+		var decoded Vowel
+		if err := json.Unmarshal(data, &decoded); err != nil {
+			t.Fatalf("%s: %v", data, err)
+		}
+		if decoded != want {
+			t.Errorf("/%s/ decoded from %s as %+v, want %+v", ipa, data, decoded.Features(), want.Features())
+		}
+	}
+}
@@ -1,11 +1,14 @@
 package phonology
 
 // Inventory represents the phonological inventory of a language,
-// essentially just lists of consonant and vowel phonemes
+// essentially just lists of consonant and vowel phonemes, along with
+// any diphthongs and custom segments that act as single phonemes
 type Inventory struct {
-	LanguageID string      `json:"lang_id" bson:"lang_id"`
-	Vowels     []Vowel     `json:"vowels" bson:"vowels"`
-	Consonants []Consonant `json:"consonants" bson:"consonants"`
+	LanguageID string          `json:"lang_id" bson:"lang_id"`
+	Vowels     []Vowel         `json:"vowels" bson:"vowels"`
+	Consonants []Consonant     `json:"consonants" bson:"consonants"`
+	Diphthongs []Diphthong     `json:"diphthongs" bson:"diphthongs"`
+	Segments   []CustomSegment `json:"segments" bson:"segments"`
 }
 
 // InventoryIPA ...
@@ -13,6 +16,8 @@ type InventoryIPA struct {
 	LanguageID string   `json:"lang_id" bson:"lang_id"`
 	Vowels     []string `json:"vowels" bson:"vowels"`
 	Consonants []string `json:"consonants" bson:"consonants"`
+	Diphthongs []string `json:"diphthongs" bson:"diphthongs"`
+	Segments   []string `json:"segments" bson:"segments"`
 }
 
 // ToIPA converts a Inventory struct to an InventoryIPA struct to send to the frontend
@@ -24,11 +29,24 @@ func (i *Inventory) ToIPA() InventoryIPA {
 	for _, c := range i.Consonants {
 		res.Consonants = append(res.Consonants, c.ToIPA())
 	}
+	for _, d := range i.Diphthongs {
+		res.Diphthongs = append(res.Diphthongs, d.ToIPA())
+	}
+	for _, s := range i.Segments {
+		res.Segments = append(res.Segments, s.ToIPA())
+	}
 	return res
 }
 
 func (i *Inventory) addPhoneme(s string) {
-	if isIPAVowel(s) {
+	if d, err := NewDiphthongFromIPA(s); err == nil {
+		for _, dp := range i.Diphthongs {
+			if d == dp {
+				return
+			}
+		}
+		i.Diphthongs = append(i.Diphthongs, d)
+	} else if isIPAVowel(s) {
 		v, _ := NewVowelFromIPA(s)
 		for _, vw := range i.Vowels {
 			if v == vw {
@@ -56,14 +74,35 @@ func (i *Inventory) addPhonemes(ps []string) {
 }
 
 // NewInventory creates a pointer to a phonological Inventory out of
-// a slice of IPA strings
+// a slice of IPA strings, where strings of two or three vowels are diphthongs
 func NewInventory(phonemes []string) *Inventory {
 	inv := &Inventory{
 		Consonants: []Consonant{},
 		Vowels:     []Vowel{},
+		Diphthongs: []Diphthong{},
+		Segments:   []CustomSegment{},
 	}
 
 	inv.addPhonemes(phonemes)
 
 	return inv
 }
+
+// Phonemes returns the inventory's consonants, vowels, diphthongs, and custom segments
+// as a single slice
+func (i *Inventory) Phonemes() []Phoneme {
+	res := []Phoneme{}
+	for _, c := range i.Consonants {
+		res = append(res, c)
+	}
+	for _, v := range i.Vowels {
+		res = append(res, v)
+	}
+	for _, d := range i.Diphthongs {
+		res = append(res, d)
+	}
+	for _, s := range i.Segments {
+		res = append(res, s)
+	}
+	return res
+}
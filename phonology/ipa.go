@@ -4,10 +4,22 @@ import "errors"
 
 // ToIPA returns the IPA representation of a Vowel struct
 func (v Vowel) ToIPA() string {
+	// lax vowels take the letter of the next lower height, e.g. lax close /i/ is written /ɪ/
+	height := v.Height
+	if v.Tenseness == LaxVTS {
+		switch height {
+		case CloseVH:
+			height = NearCloseVH
+		case CloseMidVH, MidVH:
+			height = OpenMidVH
+		}
+	}
+
 	representation := "V"
+	rhoticLetter := false // whether the letter itself shows rhoticity, as in /ɚ/
 	switch v.Frontness {
 	case FrontVF:
-		switch v.Height {
+		switch height {
 		case CloseVH:
 			if v.Rounding == RoundedVR {
 				representation = "y"
@@ -46,7 +58,7 @@ func (v Vowel) ToIPA() string {
 			}
 		}
 	case CentralVF:
-		switch v.Height {
+		switch height {
 		case CloseVH, NearCloseVH:
 			if v.Rounding == RoundedVR {
 				representation = "ʉ"
@@ -54,8 +66,14 @@ func (v Vowel) ToIPA() string {
 				representation = "ɨ"
 			}
 		case CloseMidVH, MidVH, OpenMidVH:
-			if v.Rounding == RoundedVR {
+			if v.Rounding == RoundedVR && height == OpenMidVH {
+				representation = "ɞ"
+			} else if v.Rounding == RoundedVR {
 				representation = "ɵ"
+			} else if v.Rhoticity == RhoticVRH && height == OpenMidVH {
+				representation, rhoticLetter = "ɝ", true
+			} else if v.Rhoticity == RhoticVRH {
+				representation, rhoticLetter = "ɚ", true
 			} else {
 				representation = "ə"
 			}
@@ -67,7 +85,7 @@ func (v Vowel) ToIPA() string {
 			}
 		}
 	case BackVF:
-		switch v.Height {
+		switch height {
 		case CloseVH:
 			if v.Rounding == RoundedVR {
 				representation = "u"
@@ -108,9 +126,21 @@ func (v Vowel) ToIPA() string {
 	case BreathyVP:
 		representation += string(rune(0x0324)) // diaresis below
 	}
+	switch v.TongueRoot {
+	case AdvancedVTR:
+		representation += string(rune(0x0318)) // left tack below
+	case RetractedVTR:
+		representation += string(rune(0x0319)) // right tack below
+	}
 	if v.Nasal == NasalVN {
 		representation += string(rune(0x0303)) // tilde above
 	}
+	if v.Rhoticity == RhoticVRH && !rhoticLetter {
+		representation += "˞"
+	}
+	if v.Pharyngealization == PharyngealizedVPH {
+		representation += "ˤ"
+	}
 	if v.Length == LongVL {
 		representation += "ː"
 	}
@@ -404,9 +434,9 @@ func (c Consonant) ToIPA() string {
 			}
 		case PharyngealCP:
 			if c.Voiced == VoicedCV {
-				representation = "ħ"
-			} else {
 				representation = "ʕ"
+			} else {
+				representation = "ħ"
 			}
 		case GlottalCP:
 			if c.Voiced == VoicedCV {
@@ -450,7 +480,7 @@ func (c Consonant) ToIPA() string {
 				representation = "ɰ"
 			}
 		}
-		if c.Voiced == UnvoicedCV {
+		if c.Voiced == UnvoicedCV && representation != "ʍ" {
 			representation += string(rune(0x0325)) // devoiced approximant
 		}
 	case TapCM:
@@ -552,15 +582,37 @@ func (c Consonant) ToIPA() string {
 		}
 	}
 
+	// prenasalization is written before the consonant, with a homorganic nasal where there is a letter for one
+	if c.Coarticulation == PrenasalCC {
+		place := c.Place
+		if written, err := NewConsonantFromIPA(representation); err == nil {
+			place = written.Place // the letter may be for a nearby place, as ɴ is for glottal nasals
+		}
+		switch place {
+		case BilabialCP, LabioDentalCP:
+			representation = "ᵐ" + representation
+		case VelarCP, UvularCP:
+			representation = "ᵑ" + representation
+		default:
+			representation = "ⁿ" + representation
+		}
+	}
+
 	return representation
 }
 
+// isPrenasalMark returns whether a rune is one of the superscript nasals written before
+// a prenasalized consonant
+func isPrenasalMark(r rune) bool {
+	return r == 'ⁿ' || r == 'ᵐ' || r == 'ᵑ'
+}
+
 func isIPAVowel(s string) bool {
 	if len(s) == 0 {
 		return false
 	}
 	switch string([]rune(s)[0]) {
-	case "y", "i", "ʏ", "ɪ", "ø", "e", "œ", "ɛ", "æ", "a", "ʉ", "ɨ", "ɵ", "ə", "ɞ", "ɐ", "u", "ɯ", "ʊ", "o", "ɤ", "ɔ", "ʌ", "ɒ", "ɑ":
+	case "y", "i", "ʏ", "ɪ", "ø", "e", "œ", "ɛ", "æ", "a", "ʉ", "ɨ", "ɵ", "ə", "ɚ", "ɝ", "ɞ", "ɐ", "u", "ɯ", "ʊ", "o", "ɤ", "ɔ", "ʌ", "ɒ", "ɑ":
 		return true
 	default:
 		return false
@@ -572,13 +624,25 @@ func isIPAConsonant(s string) bool {
 		return false
 	}
 	switch string([]rune(s)[0]) {
-	case "m", "n", "ɳ", "ɲ", "ŋ", "ɴ", "b", "p", "t", "d", "ɖ", "ʈ", "ɟ", "c", "g", "k", "ɢ", "q", "ʡ", "ʔ", "β", "ɸ", "v", "f", "z", "s", "ɮ", "ɬ", "ð", "θ", "ʒ", "ʃ", "ʐ", "ʂ", "ʑ", "ɕ", "ʝ", "ç", "ɣ", "x", "ʁ", "χ", "ħ", "ʕ", "ɦ", "h", "w", "ʍ", "ʋ", "l", "ɹ", "ɭ", "ɻ", "ʎ", "j", "ʟ", "ɰ", "ⱱ", "ɺ", "ɾ", "ɽ", "ʙ", "r", "ʀ", "ʢ", "ɓ", "ɗ", "ᶑ", "ʄ", "ɠ", "ʛ", "ʘ", "ǀ", "!", "‼", "ǂ", "ǁ":
+	case "m", "n", "ɳ", "ɲ", "ŋ", "ɴ", "b", "p", "t", "d", "ɖ", "ʈ", "ɟ", "c", "g", "ɡ", "k", "ɢ", "q", "ʡ", "ʔ", "β", "ɸ", "v", "f", "z", "s", "ɮ", "ɬ", "ð", "θ", "ʒ", "ʃ", "ʐ", "ʂ", "ʑ", "ɕ", "ʝ", "ç", "ɣ", "x", "ʁ", "χ", "ħ", "ʕ", "ɦ", "h", "w", "ʍ", "ʋ", "l", "ɹ", "ɭ", "ɻ", "ʎ", "j", "ʟ", "ɰ", "ⱱ", "ɺ", "ɾ", "ɽ", "ʙ", "r", "ʀ", "ʢ", "ɓ", "ɗ", "ᶑ", "ʄ", "ɠ", "ʛ", "ʘ", "ǀ", "!", "‼", "ǂ", "ǁ":
 		return true
 	default:
 		return false
 	}
 }
 
+// letterTenseness returns the tenseness a vowel letter shows, which only the letters of the
+// peripheral vowels do
+func letterTenseness(letter string) VowelTenseness {
+	switch letter {
+	case "y", "i", "u", "ɯ", "ø", "e", "o", "ɤ":
+		return TenseVTS
+	case "ʏ", "ɪ", "ʊ", "œ", "ɛ", "ɔ", "ʌ":
+		return LaxVTS
+	}
+	return UnspecifiedVTS
+}
+
 // NewVowelFromIPA creates a Vowel from an IPA string
 func NewVowelFromIPA(s string) (Vowel, error) {
 	runes := []rune(s)
@@ -588,7 +652,7 @@ func NewVowelFromIPA(s string) (Vowel, error) {
 	switch string(runes[0]) {
 	case "y", "i", "ʏ", "ɪ", "ø", "e", "œ", "ɛ", "æ", "a":
 		vowel.Frontness = FrontVF
-	case "ʉ", "ɨ", "ɵ", "ə", "ɞ", "ɐ":
+	case "ʉ", "ɨ", "ɵ", "ə", "ɚ", "ɝ", "ɞ", "ɐ":
 		vowel.Frontness = CentralVF
 	case "u", "ɯ", "ʊ", "o", "ɤ", "ɔ", "ʌ", "ɒ", "ɑ":
 		vowel.Frontness = BackVF
@@ -604,9 +668,9 @@ func NewVowelFromIPA(s string) (Vowel, error) {
 		vowel.Height = NearCloseVH
 	case "ø", "e", "ɵ", "o", "ɤ":
 		vowel.Height = CloseMidVH
-	case "ə":
+	case "ə", "ɚ":
 		vowel.Height = MidVH
-	case "œ", "ɛ", "ɞ", "ɔ", "ʌ":
+	case "œ", "ɛ", "ɝ", "ɞ", "ɔ", "ʌ":
 		vowel.Height = OpenMidVH
 	case "æ", "ɐ":
 		vowel.Height = NearOpenVH
@@ -624,13 +688,33 @@ func NewVowelFromIPA(s string) (Vowel, error) {
 		vowel.Rounding = UnroundedVR
 	}
 
+	vowel.Tenseness = letterTenseness(string(runes[0]))
+
+	// Rhoticity
+	switch string(runes[0]) {
+	case "ɚ", "ɝ":
+		vowel.Rhoticity = RhoticVRH
+	default:
+		vowel.Rhoticity = NonrhoticVRH
+	}
+
 	vowel.Phonation = ModalVP
 	vowel.Nasal = OralVN
 	vowel.Length = ShortVL
+	vowel.TongueRoot = NeutralVTR
+	vowel.Pharyngealization = PlainVPH
 
-	// Nasal, Long, Phonation
+	// Nasal, Long, Phonation, Tongue root, Rhoticity, Pharyngealization
 	for i := 1; i < len(runes); i++ {
 		switch string(runes[i]) {
+		case string(rune(0x0318)): // advanced tongue root
+			vowel.TongueRoot = AdvancedVTR
+		case string(rune(0x0319)): // retracted tongue root
+			vowel.TongueRoot = RetractedVTR
+		case "˞": // r-colored
+			vowel.Rhoticity = RhoticVRH
+		case "ˤ": // pharyngealized
+			vowel.Pharyngealization = PharyngealizedVPH
 		case string(rune(0x0325)): // devoiced
 			vowel.Phonation = DevoicedVP
 		case string(rune(0x0330)): // creaky
@@ -652,11 +736,19 @@ func NewConsonantFromIPA(s string) (Consonant, error) {
 	runes := []rune(s)
 	cons := Consonant{}
 
+	prenasal := len(runes) > 1 && isPrenasalMark(runes[0])
+	if prenasal {
+		runes = runes[1:]
+	}
+	if len(runes) == 0 {
+		return cons, errors.New("Failed to parse consonant string: \"" + s + "\"")
+	}
+
 	// Manner
 	switch string(runes[0]) {
 	case "m", "n", "ɳ", "ɲ", "ŋ", "ɴ":
 		cons.Manner = NasalCM
-	case "b", "p", "t", "d", "ɖ", "ʈ", "ɟ", "c", "g", "k", "ɢ", "q", "ʡ", "ʔ", "ɓ", "ɗ", "ᶑ", "ʄ", "ɠ", "ʛ", "ʘ", "ǀ", "!", "‼", "ǂ", "ǁ":
+	case "b", "p", "t", "d", "ɖ", "ʈ", "ɟ", "c", "g", "ɡ", "k", "ɢ", "q", "ʡ", "ʔ", "ɓ", "ɗ", "ᶑ", "ʄ", "ɠ", "ʛ", "ʘ", "ǀ", "!", "‼", "ǂ", "ǁ":
 		cons.Manner = StopCM
 	case "β", "ɸ", "v", "f", "z", "s", "ɮ", "ɬ", "ð", "θ", "ʒ", "ʃ", "ʐ", "ʂ", "ʑ", "ɕ", "ʝ", "ç", "ɣ", "x", "ʁ", "χ", "ħ", "ʕ", "ɦ", "h":
 		cons.Manner = FricativeCM
@@ -686,7 +778,7 @@ func NewConsonantFromIPA(s string) (Consonant, error) {
 		cons.Place = RetroflexCP
 	case "ɲ", "ɟ", "c", "ʑ", "ɕ", "ʝ", "ç", "ʎ", "j", "ʄ", "ǂ":
 		cons.Place = PalatalCP
-	case "ŋ", "g", "k", "ɣ", "x", "ʟ", "ɰ", "ɠ":
+	case "ŋ", "g", "ɡ", "k", "ɣ", "x", "ʟ", "ɰ", "ɠ":
 		cons.Place = VelarCP
 	case "ɴ", "ɢ", "q", "ʁ", "χ", "ʀ", "ʛ":
 		cons.Place = UvularCP
@@ -708,7 +800,7 @@ func NewConsonantFromIPA(s string) (Consonant, error) {
 
 	// Voiced
 	switch string(runes[0]) {
-	case "m", "n", "ɳ", "ɲ", "ŋ", "ɴ", "b", "d", "ɖ", "ɟ", "g", "ɢ", "β", "v", "z", "ɮ", "ð", "ʒ", "ʐ", "ʑ", "ʝ", "ɣ", "ʁ", "ʕ", "ɦ", "w", "ʋ", "l", "ɹ", "ɭ", "ɻ", "ʎ", "j", "ʟ", "ɰ", "ⱱ", "ɺ", "ɾ", "ɽ", "ʙ", "r", "ʀ", "ʢ", "ɓ", "ɗ", "ᶑ", "ʄ", "ɠ", "ʛ":
+	case "m", "n", "ɳ", "ɲ", "ŋ", "ɴ", "b", "d", "ɖ", "ɟ", "g", "ɡ", "ɢ", "β", "v", "z", "ɮ", "ð", "ʒ", "ʐ", "ʑ", "ʝ", "ɣ", "ʁ", "ʕ", "ɦ", "w", "ʋ", "l", "ɹ", "ɭ", "ɻ", "ʎ", "j", "ʟ", "ɰ", "ⱱ", "ɺ", "ɾ", "ɽ", "ʙ", "r", "ʀ", "ʢ", "ɓ", "ɗ", "ᶑ", "ʄ", "ɠ", "ʛ":
 		cons.Voiced = VoicedCV
 	default:
 		cons.Voiced = UnvoicedCV
@@ -735,6 +827,9 @@ func NewConsonantFromIPA(s string) (Consonant, error) {
 	cons.Aspirated = UnaspiratedCA
 	cons.Geminate = SingletonCG
 	cons.Coarticulation = NoneCC
+	if prenasal {
+		cons.Coarticulation = PrenasalCC
+	}
 
 	// Aspiration, Affricates, Ejectives, Coarticulation, Gemination
 	for i := 1; i < len(runes); i++ {
@@ -757,7 +852,9 @@ func NewConsonantFromIPA(s string) (Consonant, error) {
 			cons.Place = DentalCP
 		case string(rune(0x0325)): // Devoiced
 			cons.Voiced = UnvoicedCV
-		case "ɮ", "ɬ", "ɭ", "ʎ", "β", "ɸ", "v", "f", "z", "s", "ð", "θ", "ʒ", "ʃ", "ʐ", "ʂ", "ʑ", "ɕ", "ʝ", "ç", "ɣ", "x", "ʁ", "χ", "ħ", "ʕ", "ɦ", "h": // Affricates
+		case "r": // retroflex trill, written ɽ͡r
+			cons.Manner = TrillCM
+		case "ɮ", "ɬ", "ɭ", "ʎ", "β", "ɸ", "v", "f", "z", "s", "ð", "θ", "ʒ", "ʃ", "ʐ", "ʂ", "ʑ", "ɕ", "ʝ", "ç", "ɣ", "x", "ʁ", "χ", "ħ", "ʕ", "ʢ", "ɦ", "h": // Affricates
 			cons.Manner = AffricateCM
 			// fricative part adds sibilance or lateral release
 			switch string(runes[i]) {
@@ -768,7 +865,13 @@ func NewConsonantFromIPA(s string) (Consonant, error) {
 			}
 			// fricative part changes place of articulation
 			switch string(runes[i]) {
-			case "ɮ", "ɬ", "z", "s":
+			case "v", "f":
+				cons.Place = LabioDentalCP
+			case "ɮ", "ɬ":
+				if cons.Place != RetroflexCP { // retroflex lateral affricates are written ɖ͡ɮ
+					cons.Place = AlveolarCP
+				}
+			case "z", "s":
 				cons.Place = AlveolarCP
 			case "ʒ", "ʃ":
 				cons.Place = PostAlveolarCP
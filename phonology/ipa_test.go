@@ -0,0 +1,29 @@
+package phonology
+
+import "testing"
+
+func TestConsonantToIPA(t *testing.T) {
+	fricative := func(place ConsonantPlace, voiced ConsonantVoice) Consonant {
+		return Consonant{Place: place, Manner: FricativeCM, Voiced: voiced}
+	}
+	for _, tc := range []struct {
+		c    Consonant
+		want string
+	}{
+		// the voiced pharyngeal fricative is ʕ and the voiceless one ħ, as in Arabic ʕayn and ħaːʔ
+		{fricative(PharyngealCP, VoicedCV), "ʕ"},
+		{fricative(PharyngealCP, UnvoicedCV), "ħ"},
+		{fricative(GlottalCP, VoicedCV), "ɦ"},
+		{fricative(GlottalCP, UnvoicedCV), "h"},
+		{fricative(UvularCP, VoicedCV), "ʁ"},
+		{fricative(UvularCP, UnvoicedCV), "χ"},
+	} {
+		if got := tc.c.ToIPA(); got != tc.want {
+			t.Errorf("%+v written as /%s/, want /%s/", tc.c.Features(), got, tc.want)
+			continue
+		}
+		if parsed, err := NewConsonantFromIPA(tc.want); err != nil || parsed.Voiced != tc.c.Voiced {
+			t.Errorf("/%s/ read with voicing %d, want %d", tc.want, parsed.Voiced, tc.c.Voiced)
+		}
+	}
+}
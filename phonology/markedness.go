@@ -0,0 +1,123 @@
+package phonology
+
+// Markedness returns a rough score of how typologically marked a phoneme is, from 0 for
+// segments found in nearly every language, like /p t k m n i a u/, upwards for rarer ones.
+// It is meant for ranking segments against each other, not as an absolute measure.
+// Diphthongs score their nucleus plus one for each glide, and custom segments score
+// their features, if any
+func Markedness(p Phoneme) int {
+	if d, isDiphthong := p.(Diphthong); isDiphthong {
+		score := vowelMarkedness(d.Nucleus)
+		for _, glide := range []Vowel{d.Onglide, d.Offglide} {
+			if glide != (Vowel{}) {
+				score++
+			}
+		}
+		return score
+	}
+	p = standIn(p)
+	if v, isVowel := p.asVowel(); isVowel {
+		return vowelMarkedness(v)
+	}
+	if c, isConsonant := p.asConsonant(); isConsonant {
+		return consonantMarkedness(c)
+	}
+	return 0
+}
+
+func vowelMarkedness(v Vowel) int {
+	score := 0
+
+	// front rounded and back unrounded vowels are rarer than the reverse
+	if v.Frontness == FrontVF && v.Rounding == RoundedVR {
+		score += 2
+	}
+	if v.Frontness == BackVF && v.Rounding == UnroundedVR && v.Height != OpenVH && v.Height != NearOpenVH {
+		score += 2
+	}
+	if v.Frontness == CentralVF && v.Height != MidVH {
+		score++
+	}
+	// the corners /i a u/ are the least marked, with /e o/ close behind
+	switch v.Height {
+	case NearCloseVH, NearOpenVH:
+		score++
+	case CloseMidVH, OpenMidVH, MidVH:
+		if v.Frontness != CentralVF {
+			score++
+		}
+	}
+
+	if v.Nasal == NasalVN {
+		score += 2
+	}
+	if v.Length == LongVL || v.Length == ExtraShortVL || v.Length == ExtraLongVL {
+		score++
+	}
+	if v.Phonation == CreakyVP || v.Phonation == BreathyVP || v.Phonation == DevoicedVP {
+		score += 2
+	}
+	if v.TongueRoot == AdvancedVTR || v.TongueRoot == RetractedVTR {
+		score++
+	}
+	if v.Rhoticity == RhoticVRH || v.Pharyngealization == PharyngealizedVPH {
+		score += 2
+	}
+
+	return score
+}
+
+func consonantMarkedness(c Consonant) int {
+	score := 0
+
+	switch c.Place {
+	case PalatalCP, RetroflexCP, DentalCP:
+		score++
+	case UvularCP, PharyngealCP:
+		score += 2
+	}
+
+	switch c.Manner {
+	case AffricateCM, TapCM:
+		score++
+	case TrillCM:
+		score += 2
+	case ClickCM:
+		score += 4
+	case FricativeCM:
+		// /s/ and /h/ are about as common as stops, other fricatives less so
+		if c.Sibilant != SibilantCS && c.Place != GlottalCP {
+			score++
+		}
+	}
+
+	obstruent := c.Manner == StopCM || c.Manner == AffricateCM || c.Manner == FricativeCM
+	if obstruent && c.Voiced == VoicedCV {
+		score++
+	}
+	if !obstruent && c.Voiced == UnvoicedCV {
+		score += 2
+	}
+	if obstruent && c.Lateral == LateralCL {
+		score += 2
+	}
+
+	switch c.NonPulmonic {
+	case EjectiveCNP, ImplosiveCNP:
+		score += 3
+	case VelaricCNP:
+		score += 4
+	}
+
+	if c.Aspirated == AspiratedCA {
+		score += 2
+	}
+	if c.Coarticulation != UnspecifiedCC && c.Coarticulation != NoneCC {
+		score += 2
+	}
+	if c.Geminate == GeminateCG {
+		score++
+	}
+
+	return score
+}
@@ -0,0 +1,32 @@
+package phonology
+
+// Orthography is a spelling system for a language, mapping the IPA of each phoneme
+// to the grapheme that spells it
+type Orthography struct {
+	Name      string            `json:"name" bson:"name"`
+	Graphemes map[string]string `json:"graphemes" bson:"graphemes"`
+}
+
+// Validate returns an error if any of the orthography's phonemes is not valid IPA
+func (o Orthography) Validate() error {
+	for ipa := range o.Graphemes {
+		if _, err := parseSegment(ipa); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Spell writes a word in the orthography, falling back on the IPA of any
+// phoneme without a grapheme
+func (o Orthography) Spell(word []Phoneme) string {
+	s := ""
+	for _, p := range word {
+		if g, found := o.Graphemes[p.ToIPA()]; found {
+			s += g
+		} else {
+			s += p.ToIPA()
+		}
+	}
+	return s
+}
@@ -0,0 +1,83 @@
+package phonology
+
+// Pattern describes a set of phonemes, for rules to pick out the phonemes they apply to.
+// Partially specified phonemes are patterns matching every phoneme with their features,
+// like Consonant{Voiced: VoicedCV}, as are feature bundles like [+voice -son] and word and
+// syllable boundaries, like WordBoundary{Final: true} for the end of a word. Not, AnyOf,
+// and AllOf combine patterns into ones that phonemes alone can't express, like
+// "not velar" or "nasal or liquid"
+type Pattern interface {
+	Matches(Phoneme) bool
+}
+
+// Matches returns whether the target has every feature specified in the receiver,
+// so that a partially specified Vowel can be used as a Pattern
+func (v Vowel) Matches(target Phoneme) bool {
+	return target.Match(v)
+}
+
+// Matches returns whether the target has every feature specified in the receiver,
+// so that a partially specified Consonant can be used as a Pattern
+func (c Consonant) Matches(target Phoneme) bool {
+	return target.Match(c)
+}
+
+// Matches returns whether the target is a word boundary at the edge of the word the
+// receiver specifies, so that word boundaries can be used in patterns
+func (b WordBoundary) Matches(target Phoneme) bool {
+	return target.Match(b)
+}
+
+// Matches returns whether the target is a syllable boundary
+func (b SyllableBoundary) Matches(target Phoneme) bool {
+	return target.Match(b)
+}
+
+type notPattern struct {
+	pattern Pattern
+}
+
+// Not returns a pattern matching every phoneme the given pattern does not, e.g.
+// AllOf(Consonant{}, Not(Consonant{Place: VelarCP})) for non-velar consonants
+func Not(p Pattern) Pattern {
+	return notPattern{p}
+}
+
+func (n notPattern) Matches(target Phoneme) bool {
+	return !n.pattern.Matches(target)
+}
+
+type anyOfPattern []Pattern
+
+// AnyOf returns a pattern matching phonemes that match at least one of the given patterns,
+// e.g. AnyOf(Consonant{Manner: NasalCM}, Consonant{Lateral: LateralCL}). It matches
+// nothing if there are no patterns
+func AnyOf(ps ...Pattern) Pattern {
+	return anyOfPattern(ps)
+}
+
+func (a anyOfPattern) Matches(target Phoneme) bool {
+	for _, p := range a {
+		if p.Matches(target) {
+			return true
+		}
+	}
+	return false
+}
+
+type allOfPattern []Pattern
+
+// AllOf returns a pattern matching phonemes that match every one of the given patterns.
+// It matches everything if there are no patterns
+func AllOf(ps ...Pattern) Pattern {
+	return allOfPattern(ps)
+}
+
+func (a allOfPattern) Matches(target Phoneme) bool {
+	for _, p := range a {
+		if !p.Matches(target) {
+			return false
+		}
+	}
+	return true
+}
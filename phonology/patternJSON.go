@@ -0,0 +1,120 @@
+package phonology
+
+import (
+	"errors"
+	"fmt"
+)
+
+// PatternJSON is an intermediate form of a Pattern, used for storing rules that use patterns
+// and sending them to the frontend. Kind says which field holds the pattern: "consonant",
+// "vowel", "diphthong", and "segment" for partially specified phonemes, "features" for a
+// feature bundle, "wordBoundary" and "syllableBoundary" for boundaries, and "not", "anyOf",
+// and "allOf" for the combinations of Patterns, with Not taking exactly one
+type PatternJSON struct {
+	Kind         string         `json:"kind" bson:"kind"`
+	Consonant    *Consonant     `json:"consonant,omitempty" bson:"consonant,omitempty"`
+	Vowel        *Vowel         `json:"vowel,omitempty" bson:"vowel,omitempty"`
+	Diphthong    *Diphthong     `json:"diphthong,omitempty" bson:"diphthong,omitempty"`
+	Segment      *CustomSegment `json:"segment,omitempty" bson:"segment,omitempty"`
+	Features     string         `json:"features,omitempty" bson:"features,omitempty"` // like "[+voice -son]"
+	WordBoundary *WordBoundary  `json:"wordBoundary,omitempty" bson:"wordBoundary,omitempty"`
+	Patterns     []PatternJSON  `json:"patterns,omitempty" bson:"patterns,omitempty"`
+}
+
+// NewPatternJSON converts a Pattern into its JSON form. It returns an error for patterns
+// implemented outside this package, which have no JSON form
+func NewPatternJSON(p Pattern) (PatternJSON, error) {
+	switch p := p.(type) {
+	case Consonant:
+		return PatternJSON{Kind: "consonant", Consonant: &p}, nil
+	case Vowel:
+		return PatternJSON{Kind: "vowel", Vowel: &p}, nil
+	case Diphthong:
+		return PatternJSON{Kind: "diphthong", Diphthong: &p}, nil
+	case CustomSegment:
+		return PatternJSON{Kind: "segment", Segment: &p}, nil
+	case FeatureBundle:
+		return PatternJSON{Kind: "features", Features: p.String()}, nil
+	case WordBoundary:
+		return PatternJSON{Kind: "wordBoundary", WordBoundary: &p}, nil
+	case SyllableBoundary:
+		return PatternJSON{Kind: "syllableBoundary"}, nil
+	case notPattern:
+		return newCombinationJSON("not", []Pattern{p.pattern})
+	case anyOfPattern:
+		return newCombinationJSON("anyOf", p)
+	case allOfPattern:
+		return newCombinationJSON("allOf", p)
+	case nil:
+		return PatternJSON{}, errors.New("missing pattern")
+	}
+	return PatternJSON{}, fmt.Errorf("pattern of type %T has no JSON form", p)
+}
+
+func newCombinationJSON(kind string, ps []Pattern) (PatternJSON, error) {
+	pj := PatternJSON{Kind: kind}
+	for _, p := range ps {
+		sub, err := NewPatternJSON(p)
+		if err != nil {
+			return PatternJSON{}, err
+		}
+		pj.Patterns = append(pj.Patterns, sub)
+	}
+	return pj, nil
+}
+
+// FromJSON converts PatternJSON back into the Pattern it describes, or returns an error if
+// its kind is unknown or the field for its kind is missing or invalid
+func (pj PatternJSON) FromJSON() (Pattern, error) {
+	missing := fmt.Errorf("%s pattern is missing its %s", pj.Kind, pj.Kind)
+	switch pj.Kind {
+	case "consonant":
+		if pj.Consonant == nil {
+			return nil, missing
+		}
+		return *pj.Consonant, nil
+	case "vowel":
+		if pj.Vowel == nil {
+			return nil, missing
+		}
+		return *pj.Vowel, nil
+	case "diphthong":
+		if pj.Diphthong == nil {
+			return nil, missing
+		}
+		return *pj.Diphthong, nil
+	case "segment":
+		if pj.Segment == nil {
+			return nil, missing
+		}
+		return *pj.Segment, nil
+	case "features":
+		return ParseFeatureBundle(pj.Features)
+	case "wordBoundary":
+		if pj.WordBoundary == nil {
+			return nil, missing
+		}
+		return *pj.WordBoundary, nil
+	case "syllableBoundary":
+		return SyllableBoundary{}, nil
+	case "not", "anyOf", "allOf":
+		var ps []Pattern
+		for _, sub := range pj.Patterns {
+			p, err := sub.FromJSON()
+			if err != nil {
+				return nil, err
+			}
+			ps = append(ps, p)
+		}
+		switch {
+		case pj.Kind == "anyOf":
+			return AnyOf(ps...), nil
+		case pj.Kind == "allOf":
+			return AllOf(ps...), nil
+		case len(ps) != 1:
+			return nil, fmt.Errorf("not pattern has %d patterns, expected 1", len(ps))
+		}
+		return Not(ps[0]), nil
+	}
+	return nil, fmt.Errorf("unknown pattern kind %q", pj.Kind)
+}
@@ -0,0 +1,71 @@
+package phonology
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestPatternJSONRoundTrip(t *testing.T) {
+	bundle, err := ParseFeatureBundle("[+voice -son]")
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, err := NewVowelFromIPA("a")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, p := range []Pattern{
+		Consonant{},
+		Consonant{Manner: NasalCM, Voiced: VoicedCV},
+		Vowel{Rounding: RoundedVR},
+		Diphthong{Nucleus: a},
+		CustomSegment{Symbol: "ch"},
+		bundle,
+		WordBoundary{Initial: true},
+		SyllableBoundary{},
+		Not(Consonant{Place: VelarCP}),
+		AnyOf(Consonant{Manner: NasalCM}, Consonant{Lateral: LateralCL}),
+		AllOf(Consonant{}, Not(AnyOf(bundle, WordBoundary{Final: true}))),
+		AllOf(),
+	} {
+		pj, err := NewPatternJSON(p)
+		if err != nil {
+			t.Fatalf("%#v: %v", p, err)
+		}
+		data, err := json.Marshal(pj)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var decoded PatternJSON
+		if err := json.Unmarshal(data, &decoded); err != nil {
+			t.Fatalf("%s: %v", data, err)
+		}
+		back, err := decoded.FromJSON()
+		if err != nil {
+			t.Fatalf("%s: %v", data, err)
+		}
+		if !reflect.DeepEqual(back, p) {
+			t.Errorf("%#v encoded as %s, decoded as %#v", p, data, back)
+		}
+	}
+}
+
+func TestInvalidPatternJSON(t *testing.T) {
+	for _, data := range []string{
+		`{"kind": "click"}`,
+		`{"kind": "consonant"}`,
+		`{"kind": "features", "features": "+voice"}`,
+		`{"kind": "not", "patterns": []}`,
+		`{"kind": "anyOf", "patterns": [{"kind": "vowel"}]}`,
+	} {
+		var pj PatternJSON
+		if err := json.Unmarshal([]byte(data), &pj); err != nil {
+			t.Fatal(err)
+		}
+		if p, err := pj.FromJSON(); err == nil {
+			t.Errorf("%s decoded as %#v", data, p)
+		}
+	}
+}
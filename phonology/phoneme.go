@@ -4,8 +4,10 @@ package phonology
 // IPA, and perhaps with XSAMPA and various orthographies in the future
 // The Match method returns whether a Phoneme matches the specified features,
 // and the asVowel, asConsonant methods are slightly hacky ways to make
-// Match work across Phoneme types
+// Match work across Phoneme types. Every Phoneme is also a Pattern, matching
+// the phonemes with the features it specifies
 type Phoneme interface {
+	Pattern
 	ToIPA() string
 	Match(Phoneme) bool
 	asVowel() (Vowel, bool)
@@ -64,6 +66,18 @@ func (v Vowel) Match(pattern Phoneme) bool {
 	if patternv.Length != 0 && v.Length != patternv.Length {
 		return false
 	}
+	if patternv.TongueRoot != 0 && v.TongueRoot != patternv.TongueRoot {
+		return false
+	}
+	if patternv.Tenseness != 0 && v.Tenseness != patternv.Tenseness {
+		return false
+	}
+	if patternv.Rhoticity != 0 && v.Rhoticity != patternv.Rhoticity {
+		return false
+	}
+	if patternv.Pharyngealization != 0 && v.Pharyngealization != patternv.Pharyngealization {
+		return false
+	}
 	return true
 }
 
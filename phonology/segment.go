@@ -0,0 +1,195 @@
+package phonology
+
+import (
+	"errors"
+	"strings"
+)
+
+// nonSyllabic is the IPA diacritic marking the glides of a diphthong, as in /ai̯/
+const nonSyllabic = string(rune(0x032F))
+
+// Diphthong is a sequence of vowels within one syllable acting as a single phoneme, like
+// /ai̯/ or /u̯a/, or a triphthong like /u̯ai̯/. Onglide and Offglide are Vowel{} if the
+// diphthong has none. In patterns, a diphthong acts as a vowel, matching Vowel patterns
+// its nucleus matches, and Diphthong{} matches every diphthong
+type Diphthong struct {
+	Onglide  Vowel `json:"onglide" bson:"onglide"`
+	Nucleus  Vowel `json:"nucleus" bson:"nucleus"`
+	Offglide Vowel `json:"offglide" bson:"offglide"`
+}
+
+// ToIPA writes the diphthong's vowels in order, marking the glides as non-syllabic
+func (d Diphthong) ToIPA() string {
+	s := ""
+	if d.Onglide != (Vowel{}) {
+		s += d.Onglide.ToIPA() + nonSyllabic
+	}
+	s += d.Nucleus.ToIPA()
+	if d.Offglide != (Vowel{}) {
+		s += d.Offglide.ToIPA() + nonSyllabic
+	}
+	return s
+}
+
+func (d Diphthong) asVowel() (Vowel, bool) {
+	return Vowel{}, false
+}
+
+func (d Diphthong) asConsonant() (Consonant, bool) {
+	return Consonant{}, false
+}
+
+// Match returns true if the diphthong's nucleus matches a Vowel pattern, or if each vowel
+// of the diphthong matches the corresponding vowel of a Diphthong pattern, where Vowel{}
+// leaves that vowel unspecified
+func (d Diphthong) Match(pattern Phoneme) bool {
+	if v, isVowel := pattern.asVowel(); isVowel {
+		return d.Nucleus.Match(v)
+	}
+	p, isDiphthong := pattern.(Diphthong)
+	if !isDiphthong {
+		return false
+	}
+	return (p.Onglide == Vowel{} || d.Onglide.Match(p.Onglide)) &&
+		(p.Nucleus == Vowel{} || d.Nucleus.Match(p.Nucleus)) &&
+		(p.Offglide == Vowel{} || d.Offglide.Match(p.Offglide))
+}
+
+// Matches returns whether the target matches the features specified in the receiver,
+// so that a partially specified Diphthong can be used as a Pattern
+func (d Diphthong) Matches(target Phoneme) bool {
+	return target.Match(d)
+}
+
+// NewDiphthongFromIPA creates a Diphthong from two or three IPA vowels, like "ai̯", with
+// the glides marked as non-syllabic. If neither vowel of a diphthong is marked, the
+// first is taken as the nucleus
+func NewDiphthongFromIPA(s string) (Diphthong, error) {
+	vowels := []string{}
+	for _, r := range s {
+		ch := string(r)
+		if isIPAVowel(ch) {
+			vowels = append(vowels, ch)
+		} else if len(vowels) > 0 {
+			vowels[len(vowels)-1] += ch
+		} else {
+			return Diphthong{}, errors.New("Failed to parse diphthong string: \"" + s + "\"")
+		}
+	}
+	if len(vowels) < 2 || len(vowels) > 3 {
+		return Diphthong{}, errors.New("\"" + s + "\" is not a diphthong or triphthong")
+	}
+
+	nucleus := -1
+	unmarked := 0
+	for i, v := range vowels {
+		if !strings.Contains(v, nonSyllabic) {
+			nucleus = i
+			unmarked++
+		}
+	}
+	switch {
+	case unmarked == 2 && len(vowels) == 2:
+		nucleus = 0
+	case unmarked > 1:
+		return Diphthong{}, errors.New("\"" + s + "\" has more than one syllabic vowel")
+	}
+	if nucleus < 0 || len(vowels) == 3 && nucleus != 1 {
+		return Diphthong{}, errors.New("\"" + s + "\" has no syllabic vowel between its glides")
+	}
+
+	parsed := []Vowel{}
+	for _, v := range vowels {
+		vowel, err := NewVowelFromIPA(strings.Replace(v, nonSyllabic, "", -1))
+		if err != nil {
+			return Diphthong{}, err
+		}
+		parsed = append(parsed, vowel)
+	}
+
+	d := Diphthong{Nucleus: parsed[nucleus]}
+	if nucleus > 0 {
+		d.Onglide = parsed[0]
+	}
+	if nucleus < len(parsed)-1 {
+		d.Offglide = parsed[len(parsed)-1]
+	}
+	return d, nil
+}
+
+// CustomSegment is a phoneme the language defines with its own symbol, for segments the
+// features can't describe or that a language wants to treat as a unit, like a contrastive
+// /t͡ʃ/ written "ch". Consonant or Vowel optionally give the features it patterns with,
+// and are left as Consonant{} and Vowel{} if it has none. It matches CustomSegment
+// patterns with its symbol, or with an empty symbol for any custom segment, and
+// Consonant and Vowel patterns its features match
+type CustomSegment struct {
+	Symbol    string    `json:"symbol" bson:"symbol"`
+	Name      string    `json:"name" bson:"name"`
+	Consonant Consonant `json:"consonant" bson:"consonant"`
+	Vowel     Vowel     `json:"vowel" bson:"vowel"`
+}
+
+// ToIPA returns the segment's symbol
+func (s CustomSegment) ToIPA() string {
+	return s.Symbol
+}
+
+func (s CustomSegment) asVowel() (Vowel, bool) {
+	return Vowel{}, false
+}
+
+func (s CustomSegment) asConsonant() (Consonant, bool) {
+	return Consonant{}, false
+}
+
+// Match returns whether the segment matches a CustomSegment pattern's symbol, or whether
+// its features match a Consonant or Vowel pattern
+func (s CustomSegment) Match(pattern Phoneme) bool {
+	if p, isCustom := pattern.(CustomSegment); isCustom {
+		return p.Symbol == "" || p.Symbol == s.Symbol
+	}
+	if c, isConsonant := pattern.asConsonant(); isConsonant {
+		return s.Consonant != (Consonant{}) && s.Consonant.Match(c)
+	}
+	if v, isVowel := pattern.asVowel(); isVowel {
+		return s.Vowel != (Vowel{}) && s.Vowel.Match(v)
+	}
+	return false
+}
+
+// Matches returns whether the target is the custom segment, or any custom segment if the
+// receiver's symbol is empty
+func (s CustomSegment) Matches(target Phoneme) bool {
+	return target.Match(s)
+}
+
+// Validate returns an error if the segment has no symbol, or has both consonant and
+// vowel features
+func (s CustomSegment) Validate() error {
+	if strings.TrimSpace(s.Symbol) == "" {
+		return errors.New("custom segment has no symbol")
+	}
+	if s.Consonant != (Consonant{}) && s.Vowel != (Vowel{}) {
+		return errors.New("custom segment \"" + s.Symbol + "\" has both consonant and vowel features")
+	}
+	return nil
+}
+
+// standIn returns the Vowel or Consonant a phoneme takes its sonority, markedness, and
+// distinctive features from: a diphthong's nucleus, or a custom segment's features.
+// Other phonemes are returned as they are
+func standIn(p Phoneme) Phoneme {
+	switch s := p.(type) {
+	case Diphthong:
+		return s.Nucleus
+	case CustomSegment:
+		if s.Consonant != (Consonant{}) {
+			return s.Consonant
+		}
+		if s.Vowel != (Vowel{}) {
+			return s.Vowel
+		}
+	}
+	return p
+}
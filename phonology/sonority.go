@@ -0,0 +1,47 @@
+package phonology
+
+// Sonority returns a phoneme's rank on the sonority scale, from 0 for clicks and non-phonemes
+// up through stops, affricates, fricatives, nasals, liquids, and glides to the vowels, with
+// voiced obstruents one step above voiceless ones and lower vowels above higher ones.
+// Diphthongs rank as their nucleus, and custom segments by their features, if any
+func Sonority(p Phoneme) int {
+	p = standIn(p)
+	if v, isVowel := p.asVowel(); isVowel {
+		switch v.Height {
+		case CloseVH, NearCloseVH:
+			return 11
+		case CloseMidVH, MidVH, OpenMidVH:
+			return 12
+		}
+		return 13
+	}
+
+	c, isConsonant := p.asConsonant()
+	if !isConsonant {
+		return 0
+	}
+
+	voiced := 0
+	if c.Voiced == VoicedCV || c.Voiced == PrevoicedCV {
+		voiced = 1
+	}
+
+	switch c.Manner {
+	case StopCM:
+		return 1 + voiced
+	case AffricateCM:
+		return 3 + voiced
+	case FricativeCM:
+		return 5 + voiced
+	case NasalCM:
+		return 7
+	case TapCM, TrillCM:
+		return 8
+	case ApproximantCM:
+		if c.Lateral == LateralCL {
+			return 9
+		}
+		return 10
+	}
+	return 0
+}
@@ -2,6 +2,7 @@ package phonology
 
 import (
 	"encoding/json"
+	"fmt"
 )
 
 // Vowel represents a vowel phoneme
@@ -12,6 +13,11 @@ type Vowel struct {
 	Rounding  VowelRounding  `json:"rounding" bson:"rounding"`   // Rounded / Unrounded
 	Nasal     VowelNasality  `json:"nasal" bson:"nasal"`         // Nasal / Oral
 	Length    VowelLength    `json:"length" bson:"length"`       // Long / Short
+
+	TongueRoot        VowelTongueRoot        `json:"tongueRoot" bson:"tongueRoot"`               // Advanced / Retracted
+	Tenseness         VowelTenseness         `json:"tenseness" bson:"tenseness"`                 // Tense / Lax
+	Rhoticity         VowelRhoticity         `json:"rhoticity" bson:"rhoticity"`                 // Rhotic / Nonrhotic
+	Pharyngealization VowelPharyngealization `json:"pharyngealization" bson:"pharyngealization"` // Pharyngealized / Plain
 }
 
 // VowelHeight is the height of the vowel
@@ -84,21 +90,71 @@ const (
 	ExtraLongVL
 )
 
+// VowelTongueRoot is the position of the tongue root, which contrasts vowels of the same
+// height in the ATR harmony systems of many West African languages
+type VowelTongueRoot uint8
+
+// VowelTongueRoot values
+const (
+	UnspecifiedVTR VowelTongueRoot = iota
+	NeutralVTR
+	AdvancedVTR  // written with ◌̘
+	RetractedVTR // written with ◌̙
+)
+
+// VowelTenseness is whether a vowel is tense or lax, as in English /i/ and /ɪ/. Tenseness
+// has no diacritic, so a lax vowel is written with the letter of the next lower height,
+// and only the letters of lax vowels, like /ɪ ʊ ɛ ɔ/, are parsed as lax
+type VowelTenseness uint8
+
+// VowelTenseness values
+const (
+	UnspecifiedVTS VowelTenseness = iota
+	TenseVTS
+	LaxVTS
+)
+
+// VowelRhoticity is whether a vowel is r-colored, as in American English /ɚ/
+type VowelRhoticity uint8
+
+// VowelRhoticity values
+const (
+	UnspecifiedVRH VowelRhoticity = iota
+	NonrhoticVRH
+	RhoticVRH // written with ◌˞, or as /ɚ ɝ/
+)
+
+// VowelPharyngealization is whether a vowel is pharyngealized, as next to the emphatic
+// consonants of Arabic
+type VowelPharyngealization uint8
+
+// VowelPharyngealization values
+const (
+	UnspecifiedVPH VowelPharyngealization = iota
+	PlainVPH
+	PharyngealizedVPH // written with ◌ˤ
+)
+
 // VowelJSON is an intermediate form of the Vowel type, mirroring
 // the simplified version used on the frontend, used for
-// marshalling and unmarshalling
+// marshalling and unmarshalling. Version 2 adds Features,
+// which carries every feature and takes precedence when present
 type VowelJSON struct {
-	IPA       string `json:"ipa"`
-	Height    string `json:"height"`
-	Frontness string `json:"frontness"`
-	Rounding  bool   `json:"rounding"`
-	Nasal     bool   `json:"nasal"`
-	Length    bool   `json:"long"`
+	IPA       string         `json:"ipa"`
+	Height    string         `json:"height"`
+	Frontness string         `json:"frontness"`
+	Rounding  bool           `json:"rounding"`
+	Nasal     bool           `json:"nasal"`
+	Length    bool           `json:"long"`
+	Version   int            `json:"version,omitempty"`
+	Features  *VowelFeatures `json:"features,omitempty"`
 }
 
 // MarshalJSON to implement Marshaler interface for type Vowel
-// converts Vowel structs into the JS format used on the frontend
+// converts Vowel structs into the JS format used on the frontend,
+// along with the lossless v2 features
 func (v Vowel) MarshalJSON() ([]byte, error) {
+	features := v.Features()
 	return json.Marshal(&VowelJSON{
 		IPA:       v.ToIPA(),
 		Height:    heightToString(v.Height),
@@ -106,13 +162,32 @@ func (v Vowel) MarshalJSON() ([]byte, error) {
 		Rounding:  v.Rounding == RoundedVR,
 		Nasal:     v.Nasal == NasalVN,
 		Length:    v.Length == LongVL,
+		Version:   PhonemeJSONVersion,
+		Features:  &features,
 	})
 }
 
-// UnmarshalJSON implements the Unmarshaler interface for type Vowel
+// UnmarshalJSON implements the Unmarshaler interface for type Vowel.
+// The v2 features are used if present, and otherwise the v1 fields, in which
+// unmentioned features default to their most common values. Later versions are rejected
 func (v *Vowel) UnmarshalJSON(data []byte) error {
 	var vj VowelJSON
-	json.Unmarshal(data, &vj)
+	err := json.Unmarshal(data, &vj)
+	if err != nil {
+		return err
+	}
+	if vj.Version > PhonemeJSONVersion {
+		return fmt.Errorf("unsupported vowel JSON version %d, expected at most %d", vj.Version, PhonemeJSONVersion)
+	}
+
+	if vj.Features != nil {
+		decoded, err := vj.Features.Vowel()
+		if err != nil {
+			return err
+		}
+		*v = decoded
+		return nil
+	}
 
 	v.Height = heightFromString(vj.Height)
 	v.Frontness = frontnessFromString(vj.Frontness)
@@ -136,6 +211,12 @@ func (v *Vowel) UnmarshalJSON(data []byte) error {
 		v.Length = ShortVL
 	}
 
+	// tenseness as the letter for the vowel shows it, as when it is read from IPA
+	v.Tenseness = letterTenseness(string([]rune(Vowel{Height: v.Height, Frontness: v.Frontness, Rounding: v.Rounding}.ToIPA())[0]))
+	v.TongueRoot = NeutralVTR
+	v.Rhoticity = NonrhoticVRH
+	v.Pharyngealization = PlainVPH
+
 	return nil
 }
 
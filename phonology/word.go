@@ -0,0 +1,147 @@
+package phonology
+
+import (
+	"errors"
+	"strings"
+)
+
+// ParseWord splits an IPA string into a slice of phonemes. Diacritics and modifier letters
+// attach to the preceding base character, except for a superscript nasal directly before a
+// consonant, which marks it as prenasalized. A tie bar joins the next base character to
+// the current one, as in an affricate. Vowels marked non-syllabic join the vowel next to
+// them in the same syllable as a diphthong, as in /ai̯/. Syllable breaks, stress marks,
+// morpheme boundaries and spaces are skipped
+func ParseWord(s string) ([]Phoneme, error) {
+	return ParseWordWithSegments(s, nil)
+}
+
+// ParseWordWithSegments parses a word as ParseWord does, but reads the symbols of a language's
+// custom segments as single phonemes, preferring the longest symbol, so that a segment
+// written "ch" is not read as /c/ followed by /h/
+func ParseWordWithSegments(s string, segments []CustomSegment) ([]Phoneme, error) {
+	parts := []string{}
+	custom := map[int]CustomSegment{} // parts that are custom segments, by index
+	syllableStart := map[int]bool{}   // parts directly after a break
+	tied := false
+	broken := false
+	prenasal := ""
+
+	runes := []rune(s)
+	for i := 0; i < len(runes); i++ {
+		ch := string(runes[i])
+		if seg, found := segmentAt(runes[i:], segments); found && !tied && prenasal == "" {
+			custom[len(parts)] = seg
+			syllableStart[len(parts)] = broken
+			parts = append(parts, seg.Symbol)
+			broken = false
+			i += len([]rune(seg.Symbol)) - 1
+			continue
+		}
+		switch {
+		case ch == "." || ch == "ˈ" || ch == "ˌ" || ch == "-" || ch == "#" || ch == " ":
+			tied = false
+			broken = true
+		case isPrenasalMark(runes[i]) && !tied && i+1 < len(runes) && isIPAConsonant(string(runes[i+1])):
+			prenasal = ch
+		case len(parts) > 0 && custom[len(parts)-1].Symbol != "" && !isIPAVowel(ch) && !isIPAConsonant(ch):
+			return nil, errors.New("Failed to parse word: \"" + s + "\" modifies the custom segment \"" + parts[len(parts)-1] + "\"")
+		case runes[i] == 0x0361 || runes[i] == 0x035C: // tie bars above and below
+			if len(parts) == 0 {
+				return nil, errors.New("Failed to parse word: \"" + s + "\" starts with a tie bar")
+			}
+			parts[len(parts)-1] += ch
+			tied = true
+		case isIPAVowel(ch) || isIPAConsonant(ch):
+			if tied {
+				parts[len(parts)-1] += ch
+				tied = false
+			} else {
+				syllableStart[len(parts)] = broken
+				parts = append(parts, prenasal+ch)
+			}
+			prenasal = ""
+			broken = false
+		default: // diacritics and modifier letters
+			if len(parts) == 0 {
+				return nil, errors.New("Failed to parse word: \"" + s + "\" starts with \"" + ch + "\"")
+			}
+			parts[len(parts)-1] += ch
+		}
+	}
+
+	// vowelAt returns whether part i is a vowel in the same syllable as the part before it
+	vowelAt := func(i int) bool {
+		_, isCustom := custom[i]
+		return i < len(parts) && !isCustom && !syllableStart[i] && isIPAVowel(parts[i])
+	}
+	glideAt := func(i int) bool {
+		return strings.Contains(parts[i], nonSyllabic)
+	}
+
+	word := []Phoneme{}
+	for i := 0; i < len(parts); i++ {
+		part := parts[i]
+		if seg, isCustom := custom[i]; isCustom {
+			word = append(word, seg)
+			continue
+		}
+		if !isIPAVowel(part) {
+			c, err := NewConsonantFromIPA(part)
+			if err != nil {
+				return nil, err
+			}
+			word = append(word, c)
+			continue
+		}
+
+		// a diphthong is a nucleus with a glide on either side or both
+		last := i
+		if glideAt(last) && vowelAt(last+1) && !glideAt(last+1) {
+			last++
+		}
+		if !glideAt(last) && vowelAt(last+1) && glideAt(last+1) {
+			last++
+		}
+		if last > i {
+			d, err := NewDiphthongFromIPA(strings.Join(parts[i:last+1], ""))
+			if err != nil {
+				return nil, err
+			}
+			word = append(word, d)
+			i = last
+			continue
+		}
+		v, err := NewVowelFromIPA(part)
+		if err != nil {
+			return nil, err
+		}
+		word = append(word, v)
+	}
+
+	return word, nil
+}
+
+// segmentAt returns the custom segment with the longest symbol that the runes start with
+func segmentAt(runes []rune, segments []CustomSegment) (CustomSegment, bool) {
+	best := CustomSegment{}
+	found := false
+	for _, seg := range segments {
+		symbol := []rune(seg.Symbol)
+		if len(symbol) == 0 || len(symbol) > len(runes) || string(runes[:len(symbol)]) != seg.Symbol {
+			continue
+		}
+		if !found || len(symbol) > len([]rune(best.Symbol)) {
+			best, found = seg, true
+		}
+	}
+	return best, found
+}
+
+// WordToIPA joins a slice of phonemes into an IPA string
+func WordToIPA(word []Phoneme) string {
+	s := ""
+	for _, p := range word {
+		s += p.ToIPA()
+	}
+	return s
+}
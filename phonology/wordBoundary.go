@@ -1,13 +1,14 @@
 package phonology
 
 // WordBoundary is used as a dummy Phoneme for phonotactic trees to
-// mark word boundaries and boundaries between syllables
+// mark the start and end of a word. As a pattern, WordBoundary{} matches
+// either boundary, while setting Initial or Final matches only that one
 type WordBoundary struct {
 	Initial bool `json:"initial"`
+	Final   bool `json:"final"`
 }
 
-// ToIPA for SyllableBoundary, either a "." for word-medial syllable breaks,
-// or empty string for word-boundaries
+// ToIPA for WordBoundary is an empty string, since word boundaries are not written
 func (b WordBoundary) ToIPA() string {
 	return ""
 }
@@ -22,10 +23,39 @@ func (b WordBoundary) asConsonant() (Consonant, bool) {
 	return Consonant{}, false
 }
 
-// Match always returns whether a target Phoneme is a WordBoundary
-// by checking that the target is not a Consonant or Vowel
-func (b WordBoundary) Match(target Phoneme) bool {
-	_, isVowel := target.asVowel()
-	_, isConsonant := target.asConsonant()
-	return !isVowel && !isConsonant
+// Match returns whether the pattern is a WordBoundary at the same edge of the word as
+// the receiver, so that WordBoundary{Final: true} matches only the end of a word. A
+// pattern with neither Initial nor Final set matches both boundaries
+func (b WordBoundary) Match(pattern Phoneme) bool {
+	p, isWordBoundary := pattern.(WordBoundary)
+	if !isWordBoundary {
+		return false
+	}
+	return (!p.Initial || b.Initial) && (!p.Final || b.Final)
+}
+
+// SyllableBoundary is a dummy Phoneme marking a break between syllables, for use in
+// patterns like a consonant at the end of a syllable. Phonotactic trees mark syllable
+// boundaries on their edges rather than with nodes
+type SyllableBoundary struct{}
+
+// ToIPA for SyllableBoundary is ".", the IPA syllable break
+func (b SyllableBoundary) ToIPA() string {
+	return "."
+}
+
+// AsVowel always returns an empty Vowel and false for a SyllableBoundary receiver
+func (b SyllableBoundary) asVowel() (Vowel, bool) {
+	return Vowel{}, false
+}
+
+// AsConsonant always returns an empty Consonant and false for a SyllableBoundary receiver
+func (b SyllableBoundary) asConsonant() (Consonant, bool) {
+	return Consonant{}, false
+}
+
+// Match returns whether the pattern is a SyllableBoundary
+func (b SyllableBoundary) Match(pattern Phoneme) bool {
+	_, isSyllableBoundary := pattern.(SyllableBoundary)
+	return isSyllableBoundary
 }
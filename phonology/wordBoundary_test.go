@@ -0,0 +1,42 @@
+package phonology
+
+import "testing"
+
+func TestBoundaryMatch(t *testing.T) {
+	start, end, either := WordBoundary{Initial: true}, WordBoundary{Final: true}, WordBoundary{}
+	for _, tc := range []struct {
+		name            string
+		target, pattern Phoneme
+		want            bool
+	}{
+		{"start as start", start, start, true},
+		{"start as end", start, end, false},
+		{"start as either", start, either, true},
+		{"end as start", end, start, false},
+		{"end as end", end, end, true},
+		{"end as either", end, either, true},
+		// a boundary of unknown edge only matches a pattern for either
+		{"either as start", either, start, false},
+		{"either as either", either, either, true},
+		{"start as syllable boundary", start, SyllableBoundary{}, false},
+		{"start as vowel", start, Vowel{}, false},
+		{"syllable boundary", SyllableBoundary{}, SyllableBoundary{}, true},
+		{"syllable boundary as word boundary", SyllableBoundary{}, either, false},
+		{"syllable boundary as consonant", SyllableBoundary{}, Consonant{}, false},
+		{"vowel as word boundary", Vowel{Height: OpenVH}, either, false},
+	} {
+		if got := tc.target.Match(tc.pattern); got != tc.want {
+			t.Errorf("%s: Match is %t", tc.name, got)
+		}
+		// as patterns, boundaries match the same targets
+		if pattern, isPattern := tc.pattern.(Pattern); isPattern {
+			if got := pattern.Matches(tc.target); got != tc.want {
+				t.Errorf("%s: Matches is %t", tc.name, got)
+			}
+		}
+	}
+
+	if (SyllableBoundary{}).ToIPA() != "." || end.ToIPA() != "" {
+		t.Errorf("boundaries are written %q and %q", (SyllableBoundary{}).ToIPA(), end.ToIPA())
+	}
+}
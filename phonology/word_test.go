@@ -0,0 +1,54 @@
+package phonology
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseWord(t *testing.T) {
+	ch := CustomSegment{Symbol: "ch", Name: "voiceless palatal affricate"}
+	c := CustomSegment{Symbol: "c", Name: "voiceless velar stop"}
+	for _, tc := range []struct {
+		word     string
+		segments []CustomSegment
+		want     []string // the IPA of each phoneme, or nil if the word must not parse
+		custom   int      // how many of them are custom segments
+	}{
+		{word: "ai̯", want: []string{"ai̯"}},
+		{word: "i̯a", want: []string{"i̯a"}},
+		{word: "u̯ai̯", want: []string{"u̯ai̯"}},
+		{word: "bai̯.ðinsˤ", want: []string{"b", "ai̯", "ð", "i", "n", "sˤ"}},
+		{word: "ai̯e", want: []string{"ai̯", "e"}},
+		{word: "ai", want: []string{"a", "i"}},
+		{word: "a.i̯", want: []string{"a", "i"}}, // a glide cannot join a vowel across a syllable break
+		{word: "t͡ʃau̯", want: []string{"t͡ʃ", "au̯"}},
+		{word: "cha", want: []string{"c", "h", "a"}},
+		{word: "cha", segments: []CustomSegment{ch}, want: []string{"ch", "a"}, custom: 1},
+		{word: "chac", segments: []CustomSegment{c, ch}, want: []string{"ch", "a", "c"}, custom: 2},
+		{word: "chai̯", segments: []CustomSegment{ch}, want: []string{"ch", "ai̯"}, custom: 1},
+		{word: "chːa", segments: []CustomSegment{ch}},
+	} {
+		word, err := ParseWordWithSegments(tc.word, tc.segments)
+		if tc.want == nil {
+			if err == nil {
+				t.Errorf("/%s/ parsed as /%s/, want an error", tc.word, WordToIPA(word))
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("/%s/: %v", tc.word, err)
+			continue
+		}
+		got := []string{}
+		custom := 0
+		for _, p := range word {
+			got = append(got, p.ToIPA())
+			if _, ok := p.(CustomSegment); ok {
+				custom++
+			}
+		}
+		if strings.Join(got, " ") != strings.Join(tc.want, " ") || custom != tc.custom {
+			t.Errorf("/%s/ parsed as %q with %d custom segments, want %q with %d", tc.word, got, custom, tc.want, tc.custom)
+		}
+	}
+}
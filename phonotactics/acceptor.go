@@ -0,0 +1,72 @@
+package phonotactics
+
+import "github.com/jheredos/langgen/phonology"
+
+// Accepts returns whether a word could have been generated by the phonotactic tree, i.e. whether
+// there is a path from the receiver (the word start) to the word end through nodes matching each
+// phoneme of the word in turn. Edges with a weight of 0, like those forbidden by a NeverRF rule,
+// are treated as absent. Syllable breaks need not be marked, since any path will do
+func (n *PhonotacticTreeNode) Accepts(word []phonology.Phoneme) bool {
+	states := map[*PhonotacticTreeNode]bool{n: true}
+
+	for _, p := range word {
+		next := map[*PhonotacticTreeNode]bool{}
+		for node := range states {
+			for _, edge := range node.Children {
+				if edge.Weight > 0 && edge.Boundary != WordEndPC && samePhoneme(edge.ChildNode.Val, p) {
+					next[edge.ChildNode] = true
+				}
+			}
+		}
+		if len(next) == 0 {
+			return false
+		}
+		states = next
+	}
+
+	for node := range states {
+		for _, edge := range node.Children {
+			if edge.Weight > 0 && edge.Boundary == WordEndPC {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// samePhoneme compares phonemes by their IPA, since phonemes decoded from the frontend and
+// parsed from IPA can differ in features that the IPA does not distinguish
+func samePhoneme(a, b phonology.Phoneme) bool {
+	return a.ToIPA() == b.ToIPA()
+}
+
+// AllowsSequence returns whether phoneme a may be directly followed by phoneme b anywhere in
+// a word, within a syllable or across a syllable boundary, by finding the pattern in the tree.
+// It only looks at the pair, so a word can contain only allowed sequences and still be illegal
+func (n *PhonotacticTreeNode) AllowsSequence(a, b phonology.Phoneme) bool {
+	patternA, foundA := n.treePhoneme(a)
+	patternB, foundB := n.treePhoneme(b)
+	if !foundA || !foundB {
+		return false
+	}
+
+	_, edges := n.findPattern(patternA, patternB, []PhonotacticContext{OnsetPC, NucleusPC, CodaPC, SyllableBoundaryPC})
+	for _, edge := range edges {
+		if edge.Weight > 0 && samePhoneme(edge.ChildNode.Val, b) {
+			return true
+		}
+	}
+	return false
+}
+
+// treePhoneme returns the tree's own value for a phoneme, so that it can be used as a fully
+// specified pattern even if the phoneme was parsed from IPA with slightly different features
+func (n *PhonotacticTreeNode) treePhoneme(p phonology.Phoneme) (phonology.Phoneme, bool) {
+	var found phonology.Phoneme
+	n.walk(func(node *PhonotacticTreeNode) {
+		if found == nil && node.Constituent != BoundarySC && samePhoneme(node.Val, p) {
+			found = node.Val
+		}
+	})
+	return found, found != nil
+}
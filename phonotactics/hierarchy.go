@@ -4,21 +4,32 @@ import "github.com/jheredos/langgen/phonology"
 
 // ConsonantHierarchy is a ranked list of subsets of the consonant inventory,
 // with lower indices indicating lower sonority and vice versa. If Onset is
-// true, the hierarchy applies to syllable onset, otherwise to coda
+// true, the hierarchy applies to syllable onset, otherwise to coda. Custom
+// segments are ranked alongside the consonants, CustomTiers[i] joining Tiers[i]
 type ConsonantHierarchy struct {
-	Onset     bool                    `json:"onset"`
-	NoCluster []phonology.Consonant   `json:"noCluster"`
-	Tiers     [][]phonology.Consonant `json:"tiers"`
+	Onset       bool                        `json:"onset"`
+	NoCluster   []phonology.Consonant       `json:"noCluster"`
+	Tiers       [][]phonology.Consonant     `json:"tiers"`
+	CustomTiers [][]phonology.CustomSegment `json:"customTiers"`
 }
 
 // NucleusHierarchy contains the phonemes that can form a syllabic nucleus.
 // Onglides and Offglides pair with Nuclei to form diphthongs and triphthongs.
 // Monopthongs includes all of a language's vowels. Consonants are any phonemes
-// that act as syllabic consonants.
+// that act as syllabic consonants. Diphthongs and Custom are diphthongs and custom
+// segments the language treats as single phonemes, each filling the nucleus alone
 type NucleusHierarchy struct {
-	Onglides     []phonology.Vowel     `json:"onglides"`
-	Nuclei       []phonology.Vowel     `json:"nuclei"`
-	Offglides    []phonology.Vowel     `json:"offglides"`
-	Monophthongs []phonology.Vowel     `json:"monophthongs"`
-	Consonants   []phonology.Consonant `json:"consonants"`
+	Onglides     []phonology.Vowel         `json:"onglides"`
+	Nuclei       []phonology.Vowel         `json:"nuclei"`
+	Offglides    []phonology.Vowel         `json:"offglides"`
+	Monophthongs []phonology.Vowel         `json:"monophthongs"`
+	Consonants   []phonology.Consonant     `json:"consonants"`
+	Diphthongs   []phonology.Diphthong     `json:"diphthongs"`
+	Custom       []phonology.CustomSegment `json:"custom"`
+}
+
+// HasNuclei returns whether the hierarchy has any phoneme that can stand alone as a nucleus,
+// without which no syllable can be built
+func (h NucleusHierarchy) HasNuclei() bool {
+	return len(h.Monophthongs)+len(h.Nuclei)+len(h.Consonants)+len(h.Diphthongs)+len(h.Custom) > 0
 }
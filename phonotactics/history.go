@@ -0,0 +1,94 @@
+package phonotactics
+
+// wordHistory records the nodes generated so far in a word along with the syllable each
+// belongs to and the context of the edge each was reached across. The phonotactic tree
+// itself only knows the previous node, so constraints that look further back, like the
+// OCP and sequence constraints, are applied by reweighting edges against it.
+// A nil *wordHistory is valid and leaves edge weights as they are
+type wordHistory struct {
+	nodes     []*PhonotacticTreeNode
+	syllables []int
+	contexts  []PhonotacticContext
+	syllable  int
+	ocp       []OCPConstraint
+	sequences []SequenceConstraint
+}
+
+// historyMark is a saved length of a wordHistory, used to discard syllables
+// that were generated and then rejected
+type historyMark struct {
+	length   int
+	syllable int
+}
+
+// newWordHistory creates an empty history for a new word, or nil if the
+// generator has no constraints that need one
+func (g *WordGenerator) newWordHistory() *wordHistory {
+	if len(g.OCP) == 0 && len(g.Sequences) == 0 {
+		return nil
+	}
+	return &wordHistory{
+		nodes:     []*PhonotacticTreeNode{},
+		syllables: []int{},
+		contexts:  []PhonotacticContext{},
+		ocp:       g.OCP,
+		sequences: g.Sequences,
+	}
+}
+
+// push adds a node to the history, reached across an edge with the given context.
+// Word boundaries are not recorded, so distances are counted in segments
+func (h *wordHistory) push(n *PhonotacticTreeNode, boundary PhonotacticContext) {
+	if h == nil {
+		return
+	}
+	if boundary == SyllableBoundaryPC {
+		h.syllable++
+	}
+	if n.Constituent == BoundarySC {
+		return
+	}
+	h.nodes = append(h.nodes, n)
+	h.syllables = append(h.syllables, h.syllable)
+	h.contexts = append(h.contexts, boundary)
+}
+
+func (h *wordHistory) mark() historyMark {
+	if h == nil {
+		return historyMark{}
+	}
+	return historyMark{length: len(h.nodes), syllable: h.syllable}
+}
+
+func (h *wordHistory) reset(m historyMark) {
+	if h == nil {
+		return
+	}
+	h.nodes = h.nodes[:m.length]
+	h.syllables = h.syllables[:m.length]
+	h.contexts = h.contexts[:m.length]
+	h.syllable = m.syllable
+}
+
+// weight returns the weight of an edge given the nodes generated so far
+func (h *wordHistory) weight(edge *PhonotacticTreeEdge) float32 {
+	if h == nil {
+		return edge.Weight
+	}
+
+	syllable := h.syllable
+	if edge.Boundary == SyllableBoundaryPC {
+		syllable++
+	}
+
+	w := edge.Weight
+	for _, c := range h.ocp {
+		if c.violatedBy(edge.ChildNode, syllable, h) {
+			w *= c.penalty()
+		}
+	}
+	for _, c := range h.sequences {
+		w *= c.factor(edge, h)
+	}
+	return w
+}
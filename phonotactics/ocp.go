@@ -0,0 +1,77 @@
+package phonotactics
+
+import "github.com/jheredos/langgen/phonology"
+
+// OCPSimilarity is how alike two consonants must be to violate an OCP constraint
+type OCPSimilarity uint8
+
+// OCPSimilarity values
+const (
+	UnspecifiedOS OCPSimilarity = iota
+	IdenticalOS                 // the same phoneme, e.g. /p_p/
+	SamePlaceOS                 // the same place of articulation, e.g. /p_b/ or /p_m/
+)
+
+// OCPConstraint is an Obligatory Contour Principle constraint, which penalizes similar
+// consonants occurring close to each other, like the ban on homorganic consonants in
+// Arabic roots. Pattern limits the constraint to a subset of consonants, e.g. laterals
+// for /l_l/, with Consonant{} covering all of them. A pair violates the constraint if
+// they are within Window segments of each other, or, with AdjacentSyllables, if they
+// are in the same or adjacent syllables. Frequency sets how often a violating consonant
+// is still allowed, relative to its siblings
+type OCPConstraint struct {
+	Pattern           phonology.Consonant `json:"pattern"`
+	Similarity        OCPSimilarity       `json:"similarity"`
+	Window            int                 `json:"window"`
+	AdjacentSyllables bool                `json:"adjacentSyllables"`
+	Frequency         RuleFrequency       `json:"frequency"`
+}
+
+// AddOCPConstraint adds an OCP constraint to be applied to every word generated
+func (g *WordGenerator) AddOCPConstraint(c OCPConstraint) {
+	g.OCP = append(g.OCP, c)
+}
+
+// violatedBy returns whether appending node n in the given syllable to the word
+// history would bring two similar consonants too close together
+func (c OCPConstraint) violatedBy(n *PhonotacticTreeNode, syllable int, h *wordHistory) bool {
+	cons, isConsonant := n.Val.(phonology.Consonant)
+	if !isConsonant || !cons.Match(c.Pattern) {
+		return false
+	}
+
+	for i := len(h.nodes) - 1; i >= 0; i-- {
+		distance := len(h.nodes) - i
+		withinWindow := c.Window > 0 && distance <= c.Window
+		withinSyllables := c.AdjacentSyllables && syllable-h.syllables[i] <= 1
+		if !withinWindow && !withinSyllables {
+			continue
+		}
+
+		prev, isConsonant := h.nodes[i].Val.(phonology.Consonant)
+		if isConsonant && prev.Match(c.Pattern) && c.similar(cons, prev) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (c OCPConstraint) similar(a, b phonology.Consonant) bool {
+	switch c.Similarity {
+	case IdenticalOS:
+		return a == b
+	case SamePlaceOS:
+		return a.Place == b.Place
+	}
+	return false
+}
+
+// penalty is the factor by which a violating edge's weight is multiplied. Unspecified
+// and Always frequencies leave the weight as it is
+func (c OCPConstraint) penalty() float32 {
+	if c.Frequency == UnspecifiedRF || c.Frequency == AlwaysRF {
+		return 1
+	}
+	return frequencyWeight(c.Frequency)
+}
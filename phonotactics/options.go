@@ -3,14 +3,19 @@ package phonotactics
 // PhonotacticOptions holds a languages suprasegmental features like
 // stress and tone, along with word length
 type PhonotacticOptions struct {
-	MinWordLength    WordLength              `json:"minWordLength"`
-	MedianWordLength WordLength              `json:"medianWordLength"`
-	MaxWordLength    WordLength              `json:"maxWordLength"`
-	StressType       `json:"stressType"`     // Is there contrastive stress, is it predictable, and where does it fall?
-	StressPosition   `json:"stressPosition"` // Is stress position determined by the stem or the whole word?
-	ToneType         `json:"toneType"`       // Is there contrastive tone? Register or contour?
-	TonePosition     `json:"tonePosition"`   // Are all syllables marked for tone, or just stressed (pitch accent)?
-	ToneCategories   []ToneCategory          `json:"toneCategories"`
+	MinWordLength    WordLength               `json:"minWordLength"`
+	MedianWordLength WordLength               `json:"medianWordLength"`
+	MaxWordLength    WordLength               `json:"maxWordLength"`
+	StressType       `json:"stressType"`      // Is there contrastive stress, is it predictable, and where does it fall?
+	StressPosition   `json:"stressPosition"`  // Is stress position determined by the stem or the whole word?
+	ToneType         `json:"toneType"`        // Is there contrastive tone? Register or contour?
+	TonePosition     `json:"tonePosition"`    // Are all syllables marked for tone, or just stressed (pitch accent)?
+	ToneCategories   []ToneCategory           `json:"toneCategories"`
+	MoraicCodas      bool                     `json:"moraicCodas"` // Do codas add a mora to the syllable, i.e. is CVC heavy?
+	LengthModel      `json:"lengthModel"`     // How are word lengths distributed between the min and max?
+	LengthHistogram  []float64                `json:"lengthHistogram"` // Relative weights of 1, 2, 3... syllables for HistogramLM
+	LengthUnit       `json:"lengthUnit"`      // Are word lengths counted in syllables or in morae?
+	Syllabification  SyllabificationPrinciple `json:"syllabification"` // How are consonants between nuclei split when syllabifying words?
 }
 
 // WordLength is a categorical clasification of word length, from
@@ -28,6 +33,45 @@ const (
 	XXLongWL                  // 15
 )
 
+// Syllables returns the number of syllables a WordLength stands for
+func (l WordLength) Syllables() int {
+	switch l {
+	case ShortWL:
+		return 2
+	case MediumWL:
+		return 3
+	case LongWL:
+		return 6
+	case XLongWL:
+		return 10
+	case XXLongWL:
+		return 15
+	}
+	return 1
+}
+
+// LengthModel is the kind of probability distribution that word lengths follow
+type LengthModel uint8
+
+// LengthModel values
+const (
+	UnspecifiedLM LengthModel = iota
+	LogNormalLM               // discretized log-normal fitted to the min, median and max
+	PoissonLM                 // Poisson shifted to start at the min, with its mean at the median
+	HistogramLM               // explicit weights for each length
+)
+
+// LengthUnit is what word lengths are counted in. With MoraLU, the word lengths and the
+// length histogram give numbers of morae rather than syllables
+type LengthUnit uint8
+
+// LengthUnit values
+const (
+	UnspecifiedLU LengthUnit = iota
+	SyllableLU
+	MoraLU
+)
+
 // StressType encompasses whether a language has stress, if it is fixed
 // or variable, and where it falls
 type StressType uint8
@@ -43,6 +87,8 @@ const (
 	PenultimateST
 	AntepenultimateST
 	VariableST
+	QuantitySensitivePenultimateST // penultimate if heavy, otherwise antepenultimate, as in Latin
+	HeaviestOfLastThreeST          // the heaviest of the last three syllables, leftmost on a tie
 )
 
 // StressPosition is whether stress is determined by the syllables of the
@@ -84,3 +130,14 @@ const (
 // represent rising/falling contour tones, 111-555 rising+falling tones, etc.
 // up to 5 places for complex tones
 type ToneCategory uint16
+
+// SyllabificationPrinciple is how a syllabifier divides the consonants between two nuclei
+// into the coda of one syllable and the onset of the next
+type SyllabificationPrinciple uint8
+
+// SyllabificationPrinciple values
+const (
+	UnspecifiedSYP  SyllabificationPrinciple = iota
+	MaximalOnsetSYP                          // as many consonants as the onset hierarchy allows go to the onset
+	SonoritySYP                              // the boundary falls before the least sonorous consonant
+)
@@ -8,11 +8,11 @@ import (
 // generating sequences of phonemes. Consonants may have separate
 // nodes for onset and coda position
 type PhonotacticTreeNode struct {
-	ID       string                 `json:"id"`
-	Val      phonology.Phoneme      `json:"val"`
-	Children []*PhonotacticTreeEdge `json:"-"`
-	ChildIDs []string               `json:"childIds"`
-	// Position?
+	ID          string                 `json:"id"`
+	Val         phonology.Phoneme      `json:"val"`
+	Constituent SyllableConstituent    `json:"constituent"`
+	Children    []*PhonotacticTreeEdge `json:"-"`
+	ChildIDs    []string               `json:"childIds"`
 }
 
 // PhonotacticTreeEdge is the edge of a phonotactic tree with three
@@ -50,22 +50,54 @@ func newPhonotacticTreeEdge(p *PhonotacticTreeNode, boundary PhonotacticContext)
 	return edge
 }
 
-func newConsonantNodeSlice(cs []phonology.Consonant) []*PhonotacticTreeNode {
+func newConsonantNodeSlice(cs []phonology.Consonant, constituent SyllableConstituent) []*PhonotacticTreeNode {
 	nodes := []*PhonotacticTreeNode{}
 	for _, c := range cs {
-		nodes = append(nodes, &PhonotacticTreeNode{Val: c})
+		nodes = append(nodes, &PhonotacticTreeNode{Val: c, Constituent: constituent})
 	}
 	return nodes
 }
 
-func newVowelNodeSlice(vs []phonology.Vowel) []*PhonotacticTreeNode {
+func newVowelNodeSlice(vs []phonology.Vowel, constituent SyllableConstituent) []*PhonotacticTreeNode {
 	nodes := []*PhonotacticTreeNode{}
 	for _, v := range vs {
-		nodes = append(nodes, &PhonotacticTreeNode{Val: v})
+		nodes = append(nodes, &PhonotacticTreeNode{Val: v, Constituent: constituent})
 	}
 	return nodes
 }
 
+func newDiphthongNodeSlice(ds []phonology.Diphthong, constituent SyllableConstituent) []*PhonotacticTreeNode {
+	nodes := []*PhonotacticTreeNode{}
+	for _, d := range ds {
+		nodes = append(nodes, &PhonotacticTreeNode{Val: d, Constituent: constituent})
+	}
+	return nodes
+}
+
+func newSegmentNodeSlice(ss []phonology.CustomSegment, constituent SyllableConstituent) []*PhonotacticTreeNode {
+	nodes := []*PhonotacticTreeNode{}
+	for _, s := range ss {
+		nodes = append(nodes, &PhonotacticTreeNode{Val: s, Constituent: constituent})
+	}
+	return nodes
+}
+
+// consonantTiers converts a consonant hierarchy's tiers to PhonotacticTreeNodes, merging each
+// tier of custom segments into the consonant tier of the same rank
+func consonantTiers(hierarchy ConsonantHierarchy, constituent SyllableConstituent) [][]*PhonotacticTreeNode {
+	tiers := [][]*PhonotacticTreeNode{}
+	for _, tier := range hierarchy.Tiers {
+		tiers = append(tiers, newConsonantNodeSlice(tier, constituent))
+	}
+	for i, tier := range hierarchy.CustomTiers {
+		if i == len(tiers) {
+			tiers = append(tiers, []*PhonotacticTreeNode{})
+		}
+		tiers[i] = append(tiers[i], newSegmentNodeSlice(tier, constituent)...)
+	}
+	return tiers
+}
+
 // NewPhonotacticTree creates a new phonotactic tree with uniformly
 // weighted edges from three sonority hierarchies for the onset,
 // nucleus, and coda, returning the root of the tree
@@ -74,14 +106,16 @@ func NewPhonotacticTree(onset ConsonantHierarchy, nucleus NucleusHierarchy, coda
 		Val: phonology.WordBoundary{
 			Initial: true,
 		},
-		Children: []*PhonotacticTreeEdge{},
+		Constituent: BoundarySC,
+		Children:    []*PhonotacticTreeEdge{},
 	}
 
 	end := &PhonotacticTreeNode{ // Word end
 		Val: phonology.WordBoundary{
-			Initial: false,
+			Final: true,
 		},
-		Children: []*PhonotacticTreeEdge{},
+		Constituent: BoundarySC,
+		Children:    []*PhonotacticTreeEdge{},
 	}
 
 	onsetRoots, onsetLeaves := createOnsets(onset)
@@ -116,11 +150,8 @@ func createOnsets(hierarchy ConsonantHierarchy) ([]*PhonotacticTreeNode, []*Phon
 	roots, leaves := []*PhonotacticTreeNode{}, []*PhonotacticTreeNode{}
 
 	// convert hierarchy to PhonotacticTreeNode
-	tiers := [][]*PhonotacticTreeNode{}
-	for _, tier := range hierarchy.Tiers {
-		tiers = append(tiers, newConsonantNodeSlice(tier))
-	}
-	noCluster := newConsonantNodeSlice(hierarchy.NoCluster)
+	tiers := consonantTiers(hierarchy, OnsetSC)
+	noCluster := newConsonantNodeSlice(hierarchy.NoCluster, OnsetSC)
 
 	// attach nodes
 	for _, tier := range tiers {
@@ -136,11 +167,13 @@ func createOnsets(hierarchy ConsonantHierarchy) ([]*PhonotacticTreeNode, []*Phon
 func createNuclei(hierarchy NucleusHierarchy) ([]*PhonotacticTreeNode, []*PhonotacticTreeNode) {
 	roots, leaves := []*PhonotacticTreeNode{}, []*PhonotacticTreeNode{}
 
-	onglides := newVowelNodeSlice(hierarchy.Onglides)
-	nuclei := newVowelNodeSlice(hierarchy.Nuclei)
-	offglides := newVowelNodeSlice(hierarchy.Offglides)
-	monophthongs := newVowelNodeSlice(hierarchy.Monophthongs)
-	consonants := newConsonantNodeSlice(hierarchy.Consonants)
+	onglides := newVowelNodeSlice(hierarchy.Onglides, OnglideSC)
+	nuclei := newVowelNodeSlice(hierarchy.Nuclei, NucleusSC)
+	offglides := newVowelNodeSlice(hierarchy.Offglides, OffglideSC)
+	monophthongs := newVowelNodeSlice(hierarchy.Monophthongs, NucleusSC)
+	consonants := newConsonantNodeSlice(hierarchy.Consonants, NucleusSC)
+	diphthongs := newDiphthongNodeSlice(hierarchy.Diphthongs, NucleusSC)
+	custom := newSegmentNodeSlice(hierarchy.Custom, NucleusSC)
 
 	// Onglides
 	roots = append(roots, onglides...)
@@ -157,6 +190,11 @@ func createNuclei(hierarchy NucleusHierarchy) ([]*PhonotacticTreeNode, []*Phonot
 	// Syllabic Consonants
 	roots = append(roots, consonants...)
 	leaves = append(leaves, consonants...)
+	// Diphthongs and custom segments, as single phonemes
+	roots = append(roots, diphthongs...)
+	leaves = append(leaves, diphthongs...)
+	roots = append(roots, custom...)
+	leaves = append(leaves, custom...)
 
 	return roots, leaves
 }
@@ -165,11 +203,8 @@ func createCodas(hierarchy ConsonantHierarchy) ([]*PhonotacticTreeNode, []*Phono
 	roots, leaves := []*PhonotacticTreeNode{}, []*PhonotacticTreeNode{}
 
 	// convert hierarchy to PhonotacticTreeNode
-	tiers := [][]*PhonotacticTreeNode{}
-	for _, tier := range hierarchy.Tiers {
-		tiers = append(tiers, newConsonantNodeSlice(tier))
-	}
-	noCluster := newConsonantNodeSlice(hierarchy.NoCluster)
+	tiers := consonantTiers(hierarchy, CodaSC)
+	noCluster := newConsonantNodeSlice(hierarchy.NoCluster, CodaSC)
 
 	for _, tier := range tiers {
 		attachNodes(roots, tier, CodaPC)
@@ -0,0 +1,359 @@
+package phonotactics
+
+import (
+	"sort"
+
+	"github.com/jheredos/langgen/phonology"
+)
+
+// EditType is a kind of edit made to repair a word that breaks a language's phonotactics
+type EditType uint8
+
+// EditType values
+const (
+	UnspecifiedET    EditType = iota
+	EpenthesisET              // the epenthetic vowel inserted
+	DeletionET                // a consonant deleted
+	SimplificationET          // a complex consonant made plain, or a cluster of two merged into one
+)
+
+// Edit is a single change made to a word. Position is the index in the original word of the
+// segment deleted or simplified, or of the segment before which a vowel was inserted. Segment
+// is the IPA of what was inserted, deleted, or simplified, and Result is what a simplification
+// produced
+type Edit struct {
+	Type     EditType `json:"type"`
+	Position int      `json:"position"`
+	Segment  string   `json:"segment"`
+	Result   string   `json:"result,omitempty"`
+}
+
+// MaxRepairCost is the highest MaxCost a search for repairs may be given, and maxRepairStates
+// the most candidate words it queues, since the candidates multiply with every edit
+const (
+	MaxRepairCost   = 6
+	maxRepairStates = 20000
+)
+
+// RepairOptions configure the search for repairs. Epenthetic is the vowel inserted by epenthesis,
+// which is skipped if it is left unspecified. Each kind of edit has a cost, defaulting to 1, and
+// the search gives up on repairs costing more than MaxCost, which defaults to 3 and is capped at
+// MaxRepairCost. Boundaries, if given, limits the edits to the morpheme boundaries before those
+// indices of the word: epenthesis at a boundary, and deletion or simplification of a consonant
+// on either side of one
+type RepairOptions struct {
+	Epenthetic         phonology.Vowel `json:"epenthetic"`
+	EpenthesisCost     int             `json:"epenthesisCost"`
+	DeletionCost       int             `json:"deletionCost"`
+	SimplificationCost int             `json:"simplificationCost"`
+	MaxCost            int             `json:"maxCost"`
+	Boundaries         []int           `json:"boundaries,omitempty"`
+}
+
+// Repair is a legal version of a word along with the edits that produced it
+type Repair struct {
+	IPA   string              `json:"ipa"`
+	Word  []phonology.Phoneme `json:"-"`
+	Edits []Edit              `json:"edits"`
+	Cost  int                 `json:"cost"`
+}
+
+// RepairResult is the outcome of repairing a word. Best is the cheapest legal repair, and
+// Alternatives the other legal repairs found that cost at most one more. Legal is false if
+// no repair was found within the max cost, and Considered is the number of candidate words
+// the search checked. Truncated is true if the search reached its limit on candidates, in
+// which case cheaper or alternative repairs may have been missed
+type RepairResult struct {
+	Original     string   `json:"original"`
+	Best         Repair   `json:"best"`
+	Alternatives []Repair `json:"alternatives"`
+	Legal        bool     `json:"legal"`
+	Considered   int      `json:"considered"`
+	Truncated    bool     `json:"truncated"`
+}
+
+// repairSegment is a segment of a word being repaired, along with its index in the original
+// word, or -1 if it was inserted
+type repairSegment struct {
+	phoneme phonology.Phoneme
+	origin  int
+}
+
+// repairState is a candidate word in the search for a repair
+type repairState struct {
+	segments []repairSegment
+	edits    []Edit
+	cost     int
+}
+
+// Repair finds the cheapest set of edits that makes a word legal according to the phonotactic
+// tree, searching candidates in order of cost. The edits are vowel epenthesis, consonant deletion,
+// and simplification, which either strips a consonant of aspiration, coarticulation, gemination
+// or ejection, or merges two adjacent consonants into one of the tree's consonants with the
+// manner of the first and the place of the second, e.g. /np/ to /m/
+func (n *PhonotacticTreeNode) Repair(word []phonology.Phoneme, options RepairOptions) RepairResult {
+	options = options.withDefaults()
+	result := RepairResult{
+		Original:     phonology.WordToIPA(word),
+		Alternatives: []Repair{},
+	}
+
+	start := repairState{segments: []repairSegment{}, edits: []Edit{}}
+	for i, p := range word {
+		start.segments = append(start.segments, repairSegment{phoneme: p, origin: i})
+	}
+
+	consonants := n.treeConsonants()
+	buckets := map[int][]repairState{0: {start}}
+	queued := 1
+	seen := map[string]bool{}
+	found := []Repair{}
+	bestCost := -1
+
+	for cost := 0; cost <= options.MaxCost; cost++ {
+		if bestCost >= 0 && cost > bestCost+1 {
+			break
+		}
+		for _, state := range buckets[cost] {
+			ipa := state.ipa()
+			if seen[ipa] {
+				continue
+			}
+			seen[ipa] = true
+			result.Considered++
+
+			if n.Accepts(state.word()) {
+				if !redundantRepair(state.edits, found) {
+					found = append(found, Repair{IPA: ipa, Word: state.word(), Edits: state.edits, Cost: state.cost})
+				}
+				if bestCost < 0 {
+					bestCost = cost
+				}
+				continue
+			}
+
+			for _, next := range state.successors(options, consonants) {
+				if next.cost > options.MaxCost || !options.allows(next.edits[len(next.edits)-1]) {
+					continue
+				}
+				if queued == maxRepairStates {
+					result.Truncated = true
+					break
+				}
+				buckets[next.cost] = append(buckets[next.cost], next)
+				queued++
+			}
+		}
+	}
+
+	if len(found) == 0 {
+		return result
+	}
+	sort.SliceStable(found, func(i, j int) bool {
+		return found[i].Cost < found[j].Cost
+	})
+	result.Legal = true
+	result.Best = found[0]
+	result.Alternatives = found[1:]
+	return result
+}
+
+// redundantRepair returns whether a set of edits includes all the edits of a repair already
+// found, in which case the extra edits were unnecessary
+func redundantRepair(edits []Edit, found []Repair) bool {
+	for _, r := range found {
+		if len(r.Edits) == 0 {
+			return true
+		}
+		contained := true
+		for _, e := range r.Edits {
+			if !containsEdit(edits, e) {
+				contained = false
+				break
+			}
+		}
+		if contained {
+			return true
+		}
+	}
+	return false
+}
+
+func containsEdit(edits []Edit, e Edit) bool {
+	for _, edit := range edits {
+		if edit == e {
+			return true
+		}
+	}
+	return false
+}
+
+func (o RepairOptions) withDefaults() RepairOptions {
+	if o.EpenthesisCost <= 0 {
+		o.EpenthesisCost = 1
+	}
+	if o.DeletionCost <= 0 {
+		o.DeletionCost = 1
+	}
+	if o.SimplificationCost <= 0 {
+		o.SimplificationCost = 1
+	}
+	if o.MaxCost <= 0 {
+		o.MaxCost = 3
+	}
+	if o.MaxCost > MaxRepairCost {
+		o.MaxCost = MaxRepairCost
+	}
+	return o
+}
+
+// allows returns whether an edit falls at one of the options' morpheme boundaries, if any are given
+func (o RepairOptions) allows(e Edit) bool {
+	if len(o.Boundaries) == 0 {
+		return true
+	}
+	for _, b := range o.Boundaries {
+		if e.Position == b || (e.Type != EpenthesisET && e.Position == b-1) {
+			return true
+		}
+	}
+	return false
+}
+
+// successors returns every state one edit away from the receiver. Simplifications come first,
+// then epenthesis, then deletion, so that among repairs of equal cost the search finds the ones
+// that keep the most of the original word first
+func (s repairState) successors(options RepairOptions, consonants []phonology.Consonant) []repairState {
+	next := []repairState{}
+
+	for i, seg := range s.segments {
+		c, isConsonant := seg.phoneme.(phonology.Consonant)
+		if !isConsonant || seg.origin < 0 {
+			continue
+		}
+
+		// simplification of a single complex consonant
+		if plain := plainConsonant(c); plain != c {
+			simplified := append([]repairSegment{}, s.segments...)
+			simplified[i] = repairSegment{phoneme: plain, origin: seg.origin}
+			next = append(next, s.with(simplified, Edit{Type: SimplificationET, Position: seg.origin, Segment: c.ToIPA(), Result: plain.ToIPA()}, options.SimplificationCost))
+		}
+
+		// simplification of a cluster of two into one
+		if i+1 < len(s.segments) {
+			d, alsoConsonant := s.segments[i+1].phoneme.(phonology.Consonant)
+			if !alsoConsonant {
+				continue
+			}
+			for _, merged := range mergeConsonants(c, d, consonants) {
+				simplified := append(append(append([]repairSegment{}, s.segments[:i]...), repairSegment{phoneme: merged, origin: seg.origin}), s.segments[i+2:]...)
+				next = append(next, s.with(simplified, Edit{Type: SimplificationET, Position: seg.origin, Segment: c.ToIPA() + d.ToIPA(), Result: merged.ToIPA()}, options.SimplificationCost))
+			}
+		}
+	}
+
+	// epenthesis, at every position including the end
+	if options.Epenthetic != (phonology.Vowel{}) {
+		for i := 0; i <= len(s.segments); i++ {
+			position := s.originAt(i)
+			inserted := append(append(append([]repairSegment{}, s.segments[:i]...), repairSegment{phoneme: options.Epenthetic, origin: -1}), s.segments[i:]...)
+			next = append(next, s.with(inserted, Edit{Type: EpenthesisET, Position: position, Segment: options.Epenthetic.ToIPA()}, options.EpenthesisCost))
+		}
+	}
+
+	// deletion
+	for i, seg := range s.segments {
+		c, isConsonant := seg.phoneme.(phonology.Consonant)
+		if isConsonant && seg.origin >= 0 {
+			deleted := append(append([]repairSegment{}, s.segments[:i]...), s.segments[i+1:]...)
+			next = append(next, s.with(deleted, Edit{Type: DeletionET, Position: seg.origin, Segment: c.ToIPA()}, options.DeletionCost))
+		}
+	}
+
+	return next
+}
+
+func (s repairState) with(segments []repairSegment, edit Edit, cost int) repairState {
+	return repairState{
+		segments: segments,
+		edits:    append(append([]Edit{}, s.edits...), edit),
+		cost:     s.cost + cost,
+	}
+}
+
+// originAt returns the original index of the first segment at or after i that was in the
+// original word, or the length of the original word if there is none
+func (s repairState) originAt(i int) int {
+	for ; i < len(s.segments); i++ {
+		if s.segments[i].origin >= 0 {
+			return s.segments[i].origin
+		}
+	}
+	last := -1
+	for _, seg := range s.segments {
+		if seg.origin > last {
+			last = seg.origin
+		}
+	}
+	return last + 1
+}
+
+func (s repairState) word() []phonology.Phoneme {
+	word := []phonology.Phoneme{}
+	for _, seg := range s.segments {
+		word = append(word, seg.phoneme)
+	}
+	return word
+}
+
+func (s repairState) ipa() string {
+	return phonology.WordToIPA(s.word())
+}
+
+// plainConsonant strips a consonant of its secondary features
+func plainConsonant(c phonology.Consonant) phonology.Consonant {
+	if c.Aspirated == phonology.AspiratedCA {
+		c.Aspirated = phonology.UnaspiratedCA
+	}
+	if c.Coarticulation != phonology.UnspecifiedCC {
+		c.Coarticulation = phonology.NoneCC
+	}
+	if c.Geminate == phonology.GeminateCG {
+		c.Geminate = phonology.SingletonCG
+	}
+	if c.NonPulmonic == phonology.EjectiveCNP {
+		c.NonPulmonic = phonology.PulmonicCNP
+	}
+	return c
+}
+
+// mergeConsonants returns the consonants a cluster of two could be merged into: the first,
+// if they are identical, or any of the tree's consonants with the manner and voicing of
+// the first and the place of the second, other than the two themselves, since that would
+// just be a deletion
+func mergeConsonants(a, b phonology.Consonant, consonants []phonology.Consonant) []phonology.Consonant {
+	if samePhoneme(a, b) {
+		return []phonology.Consonant{a}
+	}
+	merged := []phonology.Consonant{}
+	for _, c := range consonants {
+		if c.Manner == a.Manner && c.Voiced == a.Voiced && c.Place == b.Place && !samePhoneme(c, a) && !samePhoneme(c, b) {
+			merged = append(merged, c)
+		}
+	}
+	return merged
+}
+
+// treeConsonants returns the distinct consonants in a phonotactic tree
+func (n *PhonotacticTreeNode) treeConsonants() []phonology.Consonant {
+	consonants := []phonology.Consonant{}
+	seen := map[string]bool{}
+	n.walk(func(node *PhonotacticTreeNode) {
+		c, isConsonant := node.Val.(phonology.Consonant)
+		if isConsonant && !seen[c.ToIPA()] {
+			seen[c.ToIPA()] = true
+			consonants = append(consonants, c)
+		}
+	})
+	return consonants
+}
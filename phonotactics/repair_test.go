@@ -0,0 +1,58 @@
+package phonotactics
+
+import (
+	"testing"
+
+	"github.com/jheredos/langgen/phonology"
+)
+
+func parseWord(t *testing.T, ipa string) []phonology.Phoneme {
+	t.Helper()
+	word, err := phonology.ParseWord(ipa)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return word
+}
+
+func TestRepairMaxCostIsCapped(t *testing.T) {
+	g := openSyllableGenerator(t, "p", "t")
+	a, _ := phonology.NewVowelFromIPA("a")
+
+	result := g.Root.Repair(parseWord(t, "ptptptptptptptptptpt"), RepairOptions{Epenthetic: a, MaxCost: 1000000000})
+	if result.Considered > maxRepairStates || !result.Truncated {
+		t.Errorf("considered %d candidates, want the search cut off at %d", result.Considered, maxRepairStates)
+	}
+	if (RepairOptions{MaxCost: 1000000000}).withDefaults().MaxCost != MaxRepairCost {
+		t.Errorf("max cost should be capped at %d", MaxRepairCost)
+	}
+}
+
+func TestRepairAtBoundaries(t *testing.T) {
+	g := openSyllableGenerator(t, "p", "t")
+	a, _ := phonology.NewVowelFromIPA("a")
+	word := parseWord(t, "patpa")
+
+	result := g.Root.Repair(word, RepairOptions{Epenthetic: a, MaxCost: 1, Boundaries: []int{3}})
+	if !result.Legal {
+		t.Fatal("patpa should be repaired at the boundary before /p/")
+	}
+	for _, r := range append([]Repair{result.Best}, result.Alternatives...) {
+		for _, e := range r.Edits {
+			if e.Position != 3 && !(e.Type != EpenthesisET && e.Position == 2) {
+				t.Errorf("%s edits %+v, away from the boundary", r.IPA, e)
+			}
+		}
+	}
+	if result.Best.IPA != "patapa" {
+		t.Errorf("got %s, want epenthesis in patapa", result.Best.IPA)
+	}
+	if phonology.WordToIPA(result.Best.Word) != result.Best.IPA {
+		t.Errorf("repaired word %v does not match %s", result.Best.Word, result.Best.IPA)
+	}
+
+	// the illegal cluster is nowhere near a boundary after the first vowel
+	if result := g.Root.Repair(word, RepairOptions{Epenthetic: a, MaxCost: 1, Boundaries: []int{1}}); result.Legal {
+		t.Errorf("got %s, want no repair", result.Best.IPA)
+	}
+}
@@ -25,12 +25,12 @@ const (
 // like Yoruba. In others it is illegal, like Hawaiian or Arabic (though they often give
 // the appearance of null onsets with a simple glottal stop as the onset)
 func (n *PhonotacticTreeNode) SetInitialNullOnset(frequency RuleFrequency) {
-	n.SetFrequencyForPattern(frequency, phonology.WordBoundary{}, phonology.Vowel{}, WordStartPC)
+	n.SetFrequencyForPattern(frequency, phonology.WordBoundary{Initial: true}, phonology.Vowel{})
 }
 
 // SetFinalNullCoda sets the likelihood of a word ending in a vowel
 func (n *PhonotacticTreeNode) SetFinalNullCoda(frequency RuleFrequency) {
-	n.SetFrequencyForPattern(frequency, phonology.Vowel{}, phonology.WordBoundary{}, WordEndPC)
+	n.SetFrequencyForPattern(frequency, phonology.Vowel{}, phonology.WordBoundary{Final: true})
 }
 
 // SetHiatus sets the frequency of a vowel across a syllable boundary. This tends to be
@@ -38,3 +38,61 @@ func (n *PhonotacticTreeNode) SetFinalNullCoda(frequency RuleFrequency) {
 func (n *PhonotacticTreeNode) SetHiatus(frequency RuleFrequency) {
 	n.SetFrequencyForPattern(frequency, phonology.Vowel{}, phonology.Vowel{}, SyllableBoundaryPC)
 }
+
+// SetGemination sets the frequency of a coda being followed by the same consonant as the
+// next onset, i.e. a geminate spanning the syllable boundary, as in Italian or Japanese.
+// Trends from VerySeldomRF to VeryOftenRF only reweight the geminates. AlwaysRF is much
+// stronger: a coda before an onset must be the first half of a geminate, so every other
+// coda-onset cluster is ruled out, as with the obstruent codas of Japanese
+func (n *PhonotacticTreeNode) SetGemination(frequency RuleFrequency) {
+	n.SetCodaOnsetFrequency(frequency, IdenticalCOR, phonology.Consonant{}, phonology.Consonant{})
+}
+
+// SetNasalPlaceAssimilation sets the frequency of a nasal coda sharing its place of articulation
+// with a following stop, like /mp/ and /nt/. With AlwaysRF, heterorganic clusters like /np/ are
+// ruled out entirely, as in Japanese
+func (n *PhonotacticTreeNode) SetNasalPlaceAssimilation(frequency RuleFrequency) {
+	n.SetCodaOnsetFrequency(frequency, HomorganicCOR, phonology.Consonant{Manner: phonology.NasalCM}, phonology.Consonant{Manner: phonology.StopCM})
+}
+
+// PhonotacticRules records the frequencies a language sets for the presets above, along with
+// its OCP and sequence constraints, so that they can be stored and applied to its tree and word
+// generator each time they are built. Unspecified frequencies leave the tree's uniform weights alone
+type PhonotacticRules struct {
+	InitialNullOnset       RuleFrequency        `json:"initialNullOnset"`
+	FinalNullCoda          RuleFrequency        `json:"finalNullCoda"`
+	Hiatus                 RuleFrequency        `json:"hiatus"`
+	Gemination             RuleFrequency        `json:"gemination"` // AlwaysRF rules out every cluster but geminates
+	NasalPlaceAssimilation RuleFrequency        `json:"nasalPlaceAssimilation"`
+	OCP                    []OCPConstraint      `json:"ocp"`
+	Sequences              []SequenceConstraint `json:"sequences"`
+}
+
+// Apply sets the rules' frequencies on the phonotactic tree with the given root
+func (r PhonotacticRules) Apply(root *PhonotacticTreeNode) {
+	presets := []struct {
+		frequency RuleFrequency
+		set       func(RuleFrequency)
+	}{
+		{r.InitialNullOnset, root.SetInitialNullOnset},
+		{r.FinalNullCoda, root.SetFinalNullCoda},
+		{r.Hiatus, root.SetHiatus},
+		{r.Gemination, root.SetGemination},
+		{r.NasalPlaceAssimilation, root.SetNasalPlaceAssimilation},
+	}
+	for _, preset := range presets {
+		if preset.frequency != UnspecifiedRF {
+			preset.set(preset.frequency)
+		}
+	}
+}
+
+// ApplyToGenerator adds the rules' OCP and sequence constraints to a WordGenerator
+func (r PhonotacticRules) ApplyToGenerator(g *WordGenerator) {
+	for _, c := range r.OCP {
+		g.AddOCPConstraint(c)
+	}
+	for _, c := range r.Sequences {
+		g.AddSequenceConstraint(c)
+	}
+}
@@ -0,0 +1,60 @@
+package phonotactics
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestStoredSequenceConstraintsApplyToGenerator(t *testing.T) {
+	// no syllable after the first may start with a labial, leaving only /t/
+	data := `{
+		"hiatus": 1,
+		"sequences": [{
+			"sequence": [
+				{"pattern": {"kind": "vowel", "vowel": {"features": {}}}},
+				{"pattern": {"kind": "syllableBoundary"}},
+				{"pattern": {"kind": "features", "features": "[+labial]"}}
+			],
+			"frequency": 1
+		}]
+	}`
+	var rules PhonotacticRules
+	if err := json.Unmarshal([]byte(data), &rules); err != nil {
+		t.Fatal(err)
+	}
+
+	// the rules survive being stored again
+	encoded, err := json.Marshal(rules)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var again PhonotacticRules
+	if err := json.Unmarshal(encoded, &again); err != nil {
+		t.Fatalf("%s: %v", encoded, err)
+	}
+
+	g := openSyllableGenerator(t, "p", "t")
+	again.ApplyToGenerator(g)
+	for i := 0; i < 200; i++ {
+		word, err := g.NewWord(3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, syll := range strings.Split(word, ".")[1:] {
+			if syll != "ta" {
+				t.Fatalf("%s breaks the sequence constraint", word)
+			}
+		}
+	}
+}
+
+func TestInvalidSequenceStepJSON(t *testing.T) {
+	var step SequenceStep
+	if err := json.Unmarshal([]byte(`{"pattern": {"kind": "click"}}`), &step); err == nil {
+		t.Errorf("decoded as %#v", step)
+	}
+	if _, err := json.Marshal(SequenceStep{}); err == nil {
+		t.Error("a step without a pattern should not be encoded")
+	}
+}
@@ -0,0 +1,150 @@
+package phonotactics
+
+import (
+	"encoding/json"
+
+	"github.com/jheredos/langgen/phonology"
+)
+
+// SequenceStep is one position of a Sequence: a pattern for the phoneme there and, optionally,
+// the contexts of the edge it may be reached across from the previous phoneme, e.g. only across
+// a syllable boundary. A step with no contexts may be reached across any edge
+type SequenceStep struct {
+	Pattern  phonology.Pattern
+	Contexts []PhonotacticContext
+}
+
+// sequenceStepJSON is the JSON form of a SequenceStep, with its pattern as PatternJSON
+type sequenceStepJSON struct {
+	Pattern  phonology.PatternJSON `json:"pattern"`
+	Contexts []PhonotacticContext  `json:"contexts"`
+}
+
+// MarshalJSON implements the Marshaler interface for type SequenceStep
+func (s SequenceStep) MarshalJSON() ([]byte, error) {
+	p, err := phonology.NewPatternJSON(s.Pattern)
+	if err != nil {
+		return nil, err
+	}
+	contexts := s.Contexts
+	if contexts == nil {
+		contexts = []PhonotacticContext{}
+	}
+	return json.Marshal(sequenceStepJSON{p, contexts})
+}
+
+// UnmarshalJSON implements the Unmarshaler interface for type SequenceStep
+func (s *SequenceStep) UnmarshalJSON(data []byte) error {
+	var sj sequenceStepJSON
+	err := json.Unmarshal(data, &sj)
+	if err != nil {
+		return err
+	}
+	p, err := sj.Pattern.FromJSON()
+	if err != nil {
+		return err
+	}
+	*s = SequenceStep{Pattern: p, Contexts: sj.Contexts}
+	return nil
+}
+
+// Sequence is a pattern over consecutive phonemes of a word, of any length. A step whose pattern
+// matches WordBoundary{Initial: true} or WordBoundary{Final: true} may start or end the sequence to
+// tie it to the edge of the word, and a SyllableBoundary{} step requires the steps on either side
+// of it to be in different syllables
+type Sequence []SequenceStep
+
+// SequenceConstraint sets the frequency of a sequence, like nasal + stop + liquid, or a sibilant
+// two segments after another sibilant. Since the phonotactic tree only knows the previous
+// phoneme, sequence constraints are applied while generating each word: the edge completing the
+// sequence is reweighted by Frequency relative to its siblings, and with AlwaysRF, every sibling
+// edge that would not complete it is set to 0 once the rest of the sequence has been generated
+type SequenceConstraint struct {
+	Sequence  Sequence      `json:"sequence"`
+	Frequency RuleFrequency `json:"frequency"`
+}
+
+// AddSequenceConstraint adds a sequence constraint to be applied to every word generated
+func (g *WordGenerator) AddSequenceConstraint(c SequenceConstraint) {
+	g.Sequences = append(g.Sequences, c)
+}
+
+// across returns whether the step may be reached across an edge with the context
+func (s SequenceStep) across(context PhonotacticContext) bool {
+	if len(s.Contexts) == 0 {
+		return true
+	}
+	for _, c := range s.Contexts {
+		if c == context {
+			return true
+		}
+	}
+	return false
+}
+
+// folded returns the sequence with its SyllableBoundary steps folded into the contexts of the
+// steps following them, since the tree marks syllable boundaries on its edges. A syllable
+// boundary ending the sequence is followed by a step matching anything
+func (s Sequence) folded() Sequence {
+	res := Sequence{}
+	boundary := false
+	for _, step := range s {
+		if _, isSyllableBoundary := step.Pattern.(phonology.SyllableBoundary); isSyllableBoundary {
+			boundary = true
+			continue
+		}
+		if boundary {
+			contexts := []PhonotacticContext{SyllableBoundaryPC}
+			if !step.across(SyllableBoundaryPC) {
+				contexts = []PhonotacticContext{UnspecifiedPC} // no edge can be reached across both
+			}
+			step = SequenceStep{Pattern: step.Pattern, Contexts: contexts}
+			boundary = false
+		}
+		res = append(res, step)
+	}
+	if boundary {
+		res = append(res, SequenceStep{Pattern: phonology.AllOf(), Contexts: []PhonotacticContext{SyllableBoundaryPC}})
+	}
+	return res
+}
+
+// matchesHistory returns whether the sequence matches the end of the word generated so far,
+// with its last step matching the last segment. An empty sequence always matches
+func (s Sequence) matchesHistory(h *wordHistory) bool {
+	i := len(h.nodes) - 1
+	for j := len(s) - 1; j >= 0; j-- {
+		if i < 0 {
+			// only the word boundary before the first segment is left to match
+			return j == 0 && s[j].Pattern.Matches(phonology.WordBoundary{Initial: true})
+		}
+		if !s[j].Pattern.Matches(h.nodes[i].Val) || !s[j].across(h.contexts[i]) {
+			return false
+		}
+		i--
+	}
+	return true
+}
+
+// factor is the factor by which the constraint multiplies an edge's weight, given the
+// word generated so far. Edges are left as they are unless the rest of the sequence
+// precedes them
+func (c SequenceConstraint) factor(edge *PhonotacticTreeEdge, h *wordHistory) float32 {
+	seq := c.Sequence.folded()
+	if len(seq) == 0 || c.Frequency == UnspecifiedRF {
+		return 1
+	}
+	last := seq[len(seq)-1]
+	if !seq[:len(seq)-1].matchesHistory(h) {
+		return 1
+	}
+
+	completes := last.Pattern.Matches(edge.ChildNode.Val) && last.across(edge.Boundary)
+	switch {
+	case c.Frequency == AlwaysRF && !completes:
+		return 0
+	case c.Frequency < AlwaysRF && completes:
+		return frequencyWeight(c.Frequency)
+	}
+	return 1
+}
@@ -0,0 +1,79 @@
+package phonotactics
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jheredos/langgen/phonology"
+)
+
+func TestFoldedSequence(t *testing.T) {
+	v := phonology.Vowel{}
+	c := phonology.Consonant{}
+	for _, tc := range []struct {
+		name      string
+		seq, want Sequence
+	}{
+		{"no boundary", Sequence{{Pattern: v}, {Pattern: c}}, Sequence{{Pattern: v}, {Pattern: c}}},
+		// the step after a boundary must be reached across one
+		{"boundary", Sequence{{Pattern: v}, {Pattern: phonology.SyllableBoundary{}}, {Pattern: c}},
+			Sequence{{Pattern: v}, {Pattern: c, Contexts: []PhonotacticContext{SyllableBoundaryPC}}}},
+		{"boundary and context", Sequence{{Pattern: v}, {Pattern: phonology.SyllableBoundary{}}, {Pattern: c, Contexts: []PhonotacticContext{CodaPC, SyllableBoundaryPC}}},
+			Sequence{{Pattern: v}, {Pattern: c, Contexts: []PhonotacticContext{SyllableBoundaryPC}}}},
+		// a step that can only be reached within a syllable can't follow a boundary
+		{"contradiction", Sequence{{Pattern: v}, {Pattern: phonology.SyllableBoundary{}}, {Pattern: c, Contexts: []PhonotacticContext{CodaPC}}},
+			Sequence{{Pattern: v}, {Pattern: c, Contexts: []PhonotacticContext{UnspecifiedPC}}}},
+		// a boundary ending the sequence is followed by anything
+		{"final boundary", Sequence{{Pattern: c}, {Pattern: phonology.SyllableBoundary{}}},
+			Sequence{{Pattern: c}, {Pattern: phonology.AllOf(), Contexts: []PhonotacticContext{SyllableBoundaryPC}}}},
+	} {
+		if got := tc.seq.folded(); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: folded to %#v, want %#v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestWordBoundarySequences(t *testing.T) {
+	consonant := func(ipa string) phonology.Consonant {
+		c, err := phonology.NewConsonantFromIPA(ipa)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return c
+	}
+	p, tt := consonant("p"), consonant("t")
+
+	for _, tc := range []struct {
+		name string
+		seq  Sequence
+		// whether a word may start with /t/, or end in /ta/
+		tInitial, taFinal bool
+	}{
+		{"initial /t/", Sequence{{Pattern: phonology.WordBoundary{Initial: true}}, {Pattern: tt}}, false, true},
+		// a boundary with neither edge set stands for both
+		{"either edge before /t/", Sequence{{Pattern: phonology.WordBoundary{}}, {Pattern: tt}}, false, true},
+		// the end of a word never comes before its first consonant
+		{"final edge before /t/", Sequence{{Pattern: phonology.WordBoundary{Final: true}}, {Pattern: tt}}, true, true},
+		{"final /ta/", Sequence{{Pattern: tt}, {Pattern: phonology.Vowel{}}, {Pattern: phonology.WordBoundary{Final: true}}}, true, false},
+		{"/ta/ before the start", Sequence{{Pattern: tt}, {Pattern: phonology.Vowel{}}, {Pattern: phonology.WordBoundary{Initial: true}}}, true, true},
+		// neither applies to /p/
+		{"initial /p/", Sequence{{Pattern: phonology.WordBoundary{Initial: true}}, {Pattern: p}}, true, true},
+	} {
+		g := openSyllableGenerator(t, "p", "t")
+		g.AddSequenceConstraint(SequenceConstraint{Sequence: tc.seq, Frequency: NeverRF})
+
+		tInitial, taFinal := false, false
+		for i := 0; i < 200; i++ {
+			word, err := g.NewWord(2)
+			if err != nil {
+				t.Fatal(err)
+			}
+			tInitial = tInitial || strings.HasPrefix(word, "t")
+			taFinal = taFinal || strings.HasSuffix(word, "ta")
+		}
+		if tInitial != tc.tInitial || taFinal != tc.taFinal {
+			t.Errorf("%s: words start with /t/: %t, end in /ta/: %t", tc.name, tInitial, taFinal)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package phonotactics
+
+// StressedSyllable returns the index of the syllable that receives primary stress in a word
+// whose syllables have the given weights. It returns -1 for languages without stress, or
+// where stress is variable and cannot be predicted from the shape of the word
+func StressedSyllable(weights []SyllableWeight, stressType StressType) int {
+	n := len(weights)
+	if n == 0 {
+		return -1
+	}
+
+	// fromStart and fromEnd clamp a position to the bounds of the word,
+	// so that e.g. antepenultimate stress falls on the first syllable of
+	// a disyllabic word
+	fromStart := func(i int) int {
+		if i > n-1 {
+			return n - 1
+		}
+		return i
+	}
+	fromEnd := func(i int) int {
+		if n-1-i < 0 {
+			return 0
+		}
+		return n - 1 - i
+	}
+
+	switch stressType {
+	case InitialST:
+		return 0
+	case SecondST:
+		return fromStart(1)
+	case ThirdST:
+		return fromStart(2)
+	case FinalST:
+		return n - 1
+	case PenultimateST:
+		return fromEnd(1)
+	case AntepenultimateST:
+		return fromEnd(2)
+	case QuantitySensitivePenultimateST:
+		penult := fromEnd(1)
+		if weights[penult] >= HeavySW {
+			return penult
+		}
+		return fromEnd(2)
+	case HeaviestOfLastThreeST:
+		stressed := fromEnd(2)
+		for i := stressed + 1; i < n; i++ {
+			if weights[i] > weights[stressed] {
+				stressed = i
+			}
+		}
+		return stressed
+	}
+
+	return -1
+}
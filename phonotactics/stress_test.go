@@ -0,0 +1,41 @@
+package phonotactics
+
+import "testing"
+
+func TestStressedSyllable(t *testing.T) {
+	L, H, S := LightSW, HeavySW, SuperheavySW
+	for _, tc := range []struct {
+		stress  StressType
+		weights []SyllableWeight
+		want    int
+	}{
+		{InitialST, []SyllableWeight{L, L, L}, 0},
+		{SecondST, []SyllableWeight{L, L, L}, 1},
+		{SecondST, []SyllableWeight{L}, 0},
+		{ThirdST, []SyllableWeight{L, L, L, L}, 2},
+		{ThirdST, []SyllableWeight{L, L}, 1},
+		{FinalST, []SyllableWeight{L, L, L}, 2},
+		{PenultimateST, []SyllableWeight{L, L, L}, 1},
+		{PenultimateST, []SyllableWeight{L}, 0},
+		{AntepenultimateST, []SyllableWeight{L, L, L, L}, 1},
+		{AntepenultimateST, []SyllableWeight{L, L}, 0},
+		// Latin: a heavy penult takes the stress, otherwise the antepenult does
+		{QuantitySensitivePenultimateST, []SyllableWeight{L, H, L}, 1},
+		{QuantitySensitivePenultimateST, []SyllableWeight{L, L, L, L}, 1},
+		{QuantitySensitivePenultimateST, []SyllableWeight{H, L, L}, 0},
+		{QuantitySensitivePenultimateST, []SyllableWeight{L, L}, 0},
+		{HeaviestOfLastThreeST, []SyllableWeight{H, L, L, L}, 1},
+		{HeaviestOfLastThreeST, []SyllableWeight{L, L, H, L}, 2},
+		{HeaviestOfLastThreeST, []SyllableWeight{L, H, L, S}, 3},
+		{HeaviestOfLastThreeST, []SyllableWeight{L, H, H}, 1},
+		// no stress, or stress that the shape of the word does not predict
+		{UnspecifiedST, []SyllableWeight{L, L}, -1},
+		{NoneST, []SyllableWeight{L, L}, -1},
+		{VariableST, []SyllableWeight{L, L}, -1},
+		{InitialST, []SyllableWeight{}, -1},
+	} {
+		if got := StressedSyllable(tc.weights, tc.stress); got != tc.want {
+			t.Errorf("stress type %d over %v: got %d, want %d", tc.stress, tc.weights, got, tc.want)
+		}
+	}
+}
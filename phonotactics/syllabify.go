@@ -0,0 +1,384 @@
+package phonotactics
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/jheredos/langgen/phonology"
+)
+
+// Syllabifier splits words into syllables according to a language's hierarchies. Principle
+// decides how consonants between two nuclei are divided, defaulting to MaximalOnsetSYP, while
+// MoraicCodas and Stress determine the weight and stress of each syllable as in WordGenerator
+type Syllabifier struct {
+	Onset       ConsonantHierarchy
+	Nucleus     NucleusHierarchy
+	Coda        ConsonantHierarchy
+	Principle   SyllabificationPrinciple
+	MoraicCodas bool
+	Stress      StressType
+}
+
+// NewSyllabifier creates a new Syllabifier from the hierarchies for the onset,
+// nucleus, and coda
+func NewSyllabifier(onset ConsonantHierarchy, nucleus NucleusHierarchy, coda ConsonantHierarchy) *Syllabifier {
+	return &Syllabifier{
+		Onset:   onset,
+		Nucleus: nucleus,
+		Coda:    coda,
+	}
+}
+
+// SetOptions applies the syllabification, syllable weight and stress settings of a
+// language's PhonotacticOptions to the Syllabifier
+func (s *Syllabifier) SetOptions(options PhonotacticOptions) {
+	s.Principle = options.Syllabification
+	s.MoraicCodas = options.MoraicCodas
+	s.Stress = options.StressType
+}
+
+// ParsedSyllable is one syllable of a syllabified word, split into its constituents.
+// The nucleus includes any onglide or offglide. Start is the index in the word of the
+// syllable's first segment
+type ParsedSyllable struct {
+	Onset    []phonology.Phoneme
+	Nucleus  []phonology.Phoneme
+	Coda     []phonology.Phoneme
+	Start    int
+	Morae    int
+	Weight   SyllableWeight
+	Stressed bool
+}
+
+// MarshalJSON to implement Marshaler interface for type ParsedSyllable,
+// writing each constituent as IPA
+func (ps ParsedSyllable) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&struct {
+		Onset    string         `json:"onset"`
+		Nucleus  string         `json:"nucleus"`
+		Coda     string         `json:"coda"`
+		Start    int            `json:"start"`
+		Morae    int            `json:"morae"`
+		Weight   SyllableWeight `json:"weight"`
+		Stressed bool           `json:"stressed"`
+	}{
+		Onset:    phonology.WordToIPA(ps.Onset),
+		Nucleus:  phonology.WordToIPA(ps.Nucleus),
+		Coda:     phonology.WordToIPA(ps.Coda),
+		Start:    ps.Start,
+		Morae:    ps.Morae,
+		Weight:   ps.Weight,
+		Stressed: ps.Stressed,
+	})
+}
+
+// Syllabification is a word split into syllables. Boundaries holds the index in the word at
+// which each syllable after the first begins, and Stressed the index of the stressed syllable,
+// or -1. Legal is false if any constituent is not allowed by the hierarchies, in which case
+// the syllabification is only a best guess
+type Syllabification struct {
+	Syllables  []ParsedSyllable `json:"syllables"`
+	Boundaries []int            `json:"boundaries"`
+	Stressed   int              `json:"stressed"`
+	IPA        string           `json:"ipa"`
+	Legal      bool             `json:"legal"`
+}
+
+// nucleusSpan is the part of a word making up a syllable's nucleus
+type nucleusSpan struct {
+	start        int
+	constituents []SyllableConstituent // one per segment, starting at start
+	legal        bool
+}
+
+func (span nucleusSpan) end() int {
+	return span.start + len(span.constituents)
+}
+
+// Syllabify splits a word into syllables. Every vowel, along with any glides the nucleus
+// hierarchy pairs with it, is the nucleus of a syllable, as is every diphthong and custom vowel
+// segment, and any syllabic consonant with no vowel next to it. Consonants before the first nucleus form its onset and those after the last
+// its coda, while those between two nuclei are divided according to the Syllabifier's principle,
+// preferring divisions that the onset and coda hierarchies allow
+func (s *Syllabifier) Syllabify(word []phonology.Phoneme) (Syllabification, error) {
+	spans := s.nuclei(word)
+	if len(spans) == 0 {
+		return Syllabification{}, errors.New("word has no nucleus to syllabify")
+	}
+
+	result := Syllabification{
+		Syllables:  []ParsedSyllable{},
+		Boundaries: []int{},
+		Legal:      true,
+	}
+
+	// the start of each syllable, and the index of the first segment after its nucleus
+	starts := []int{0}
+	for i := 1; i < len(spans); i++ {
+		cluster := word[spans[i-1].end():spans[i].start]
+		j, legal := s.divide(cluster)
+		result.Legal = result.Legal && legal
+		starts = append(starts, spans[i-1].end()+j)
+		result.Boundaries = append(result.Boundaries, spans[i-1].end()+j)
+	}
+	starts = append(starts, len(word))
+
+	sylls := []Syllable{}
+	for i, span := range spans {
+		onset := word[starts[i]:span.start]
+		nucleus := word[span.start:span.end()]
+		coda := word[span.end():starts[i+1]]
+		if i == 0 && !clusterAllowed(s.Onset, onset) || i == len(spans)-1 && !clusterAllowed(s.Coda, coda) {
+			result.Legal = false
+		}
+		result.Legal = result.Legal && span.legal
+
+		// wrap the segments in nodes so that they can be weighed and written like generated syllables
+		nodes := []*PhonotacticTreeNode{}
+		for _, p := range onset {
+			nodes = append(nodes, &PhonotacticTreeNode{Val: p, Constituent: OnsetSC})
+		}
+		for k, p := range nucleus {
+			nodes = append(nodes, &PhonotacticTreeNode{Val: p, Constituent: span.constituents[k]})
+		}
+		for _, p := range coda {
+			nodes = append(nodes, &PhonotacticTreeNode{Val: p, Constituent: CodaSC})
+		}
+		syll := newWeightedSyllable(nodes, s.MoraicCodas)
+		sylls = append(sylls, syll)
+
+		result.Syllables = append(result.Syllables, ParsedSyllable{
+			Onset:   onset,
+			Nucleus: nucleus,
+			Coda:    coda,
+			Start:   starts[i],
+			Morae:   syll.Morae,
+			Weight:  syll.Weight,
+		})
+	}
+
+	weights := []SyllableWeight{}
+	for _, syll := range sylls {
+		weights = append(weights, syll.Weight)
+	}
+	result.Stressed = StressedSyllable(weights, s.Stress)
+	if result.Stressed >= 0 {
+		result.Syllables[result.Stressed].Stressed = true
+	}
+	result.IPA = (&WordGenerator{Stress: s.Stress}).syllablesToIPA(sylls)
+
+	return result, nil
+}
+
+// nuclei finds the nucleus of every syllable in a word, in order
+func (s *Syllabifier) nuclei(word []phonology.Phoneme) []nucleusSpan {
+	spans := []nucleusSpan{}
+	for i := 0; i < len(word); {
+		if _, isVowel := word[i].(phonology.Vowel); isVowel {
+			span := s.vowelNucleus(word, i)
+			spans = append(spans, span)
+			i = span.end()
+			continue
+		}
+		if s.isSegmentNucleus(word[i]) {
+			spans = append(spans, nucleusSpan{start: i, constituents: []SyllableConstituent{NucleusSC}, legal: s.allowsSegmentNucleus(word[i])})
+			i++
+			continue
+		}
+
+		if s.syllabicConsonant(word, i) {
+			spans = append(spans, nucleusSpan{start: i, constituents: []SyllableConstituent{NucleusSC}, legal: true})
+		}
+		i++
+	}
+	return spans
+}
+
+// vowelNucleus returns the nucleus beginning with the vowel at index i, which takes in an
+// onglide before or an offglide after the vowel wherever the nucleus hierarchy pairs them
+func (s *Syllabifier) vowelNucleus(word []phonology.Phoneme, i int) nucleusSpan {
+	at := func(k int, vs []phonology.Vowel) bool {
+		return k < len(word) && containsPhoneme(vowelsToPhonemes(vs), word[k])
+	}
+
+	switch {
+	case at(i, s.Nucleus.Onglides) && at(i+1, s.Nucleus.Nuclei):
+		span := nucleusSpan{start: i, constituents: []SyllableConstituent{OnglideSC, NucleusSC}, legal: true}
+		if at(i+2, s.Nucleus.Offglides) {
+			span.constituents = append(span.constituents, OffglideSC)
+		}
+		return span
+	case at(i, s.Nucleus.Nuclei) && at(i+1, s.Nucleus.Offglides):
+		return nucleusSpan{start: i, constituents: []SyllableConstituent{NucleusSC, OffglideSC}, legal: true}
+	}
+
+	return nucleusSpan{
+		start:        i,
+		constituents: []SyllableConstituent{NucleusSC},
+		legal:        at(i, s.Nucleus.Monophthongs) || at(i, s.Nucleus.Nuclei),
+	}
+}
+
+// isSegmentNucleus returns whether a phoneme other than a vowel fills a nucleus on its own:
+// a diphthong, or a custom segment that the nucleus hierarchy lists or that has vowel features
+func (s *Syllabifier) isSegmentNucleus(p phonology.Phoneme) bool {
+	switch p := p.(type) {
+	case phonology.Diphthong:
+		return true
+	case phonology.CustomSegment:
+		return p.Vowel != (phonology.Vowel{}) || s.allowsSegmentNucleus(p)
+	}
+	return false
+}
+
+// allowsSegmentNucleus returns whether the nucleus hierarchy lists a diphthong or custom segment
+func (s *Syllabifier) allowsSegmentNucleus(p phonology.Phoneme) bool {
+	return containsPhoneme(diphthongsToPhonemes(s.Nucleus.Diphthongs), p) || containsPhoneme(segmentsToPhonemes(s.Nucleus.Custom), p)
+}
+
+// syllabicConsonant returns whether the consonant at index i is one the nucleus hierarchy
+// allows as a nucleus, with no vowel on either side of it to take that role instead
+func (s *Syllabifier) syllabicConsonant(word []phonology.Phoneme, i int) bool {
+	if !containsPhoneme(consonantsToPhonemes(s.Nucleus.Consonants), word[i]) {
+		return false
+	}
+	for _, k := range []int{i - 1, i + 1} {
+		if k >= 0 && k < len(word) {
+			if _, isVowel := word[k].(phonology.Vowel); isVowel || s.isSegmentNucleus(word[k]) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// divide returns the index at which a cluster of consonants between two nuclei is split
+// into a coda and an onset, and whether that split is allowed by the hierarchies
+func (s *Syllabifier) divide(cluster []phonology.Phoneme) (int, bool) {
+	legal := []int{}
+	for j := 0; j <= len(cluster); j++ {
+		if clusterAllowed(s.Coda, cluster[:j]) && clusterAllowed(s.Onset, cluster[j:]) {
+			legal = append(legal, j)
+		}
+	}
+
+	if s.Principle == SonoritySYP {
+		ideal := sonorityMinimum(cluster)
+		if len(legal) == 0 {
+			return ideal, false
+		}
+		best := legal[0]
+		for _, j := range legal {
+			if abs(j-ideal) < abs(best-ideal) {
+				best = j
+			}
+		}
+		return best, true
+	}
+
+	if len(legal) > 0 {
+		return legal[0], true
+	}
+	for j := 0; j <= len(cluster); j++ {
+		if clusterAllowed(s.Onset, cluster[j:]) {
+			return j, false
+		}
+	}
+	return sonorityMinimum(cluster), false
+}
+
+// sonorityMinimum returns the index of the last of the least sonorous segments in a cluster,
+// where the sonority sequencing principle puts the start of the onset. Segments of equal
+// sonority are split, since neither rises toward the nucleus
+func sonorityMinimum(cluster []phonology.Phoneme) int {
+	min := 0
+	for j, p := range cluster {
+		if phonology.Sonority(p) <= phonology.Sonority(cluster[min]) {
+			min = j
+		}
+	}
+	return min
+}
+
+// clusterAllowed returns whether a sequence of consonants is allowed by a hierarchy. A single
+// consonant may come from any tier or from NoCluster, while a cluster must climb the tiers,
+// with each consonant on a higher tier than the one before it. Custom segments are ranked on
+// the tier of their CustomTiers
+func clusterAllowed(h ConsonantHierarchy, cluster []phonology.Phoneme) bool {
+	if len(cluster) == 0 {
+		return true
+	}
+	if len(cluster) == 1 && containsPhoneme(consonantsToPhonemes(h.NoCluster), cluster[0]) {
+		return true
+	}
+
+	prev := -1
+	for _, p := range cluster {
+		tier := -1
+		for t, cs := range h.Tiers {
+			if containsPhoneme(consonantsToPhonemes(cs), p) {
+				tier = t
+				break
+			}
+		}
+		for t, segs := range h.CustomTiers {
+			if tier < 0 && containsPhoneme(segmentsToPhonemes(segs), p) {
+				tier = t
+				break
+			}
+		}
+		if tier <= prev {
+			return false
+		}
+		prev = tier
+	}
+	return true
+}
+
+func containsPhoneme(ps []phonology.Phoneme, p phonology.Phoneme) bool {
+	for _, q := range ps {
+		if samePhoneme(p, q) {
+			return true
+		}
+	}
+	return false
+}
+
+func consonantsToPhonemes(cs []phonology.Consonant) []phonology.Phoneme {
+	ps := []phonology.Phoneme{}
+	for _, c := range cs {
+		ps = append(ps, c)
+	}
+	return ps
+}
+
+func diphthongsToPhonemes(ds []phonology.Diphthong) []phonology.Phoneme {
+	ps := []phonology.Phoneme{}
+	for _, d := range ds {
+		ps = append(ps, d)
+	}
+	return ps
+}
+
+func segmentsToPhonemes(ss []phonology.CustomSegment) []phonology.Phoneme {
+	ps := []phonology.Phoneme{}
+	for _, s := range ss {
+		ps = append(ps, s)
+	}
+	return ps
+}
+
+func vowelsToPhonemes(vs []phonology.Vowel) []phonology.Phoneme {
+	ps := []phonology.Phoneme{}
+	for _, v := range vs {
+		ps = append(ps, v)
+	}
+	return ps
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,157 @@
+package phonotactics
+
+import (
+	"testing"
+
+	"github.com/jheredos/langgen/phonology"
+)
+
+// testSyllabifier allows onsets climbing from /s/ through the stops to /l/, single codas, the
+// glides /i/ before and /u/ after /a/, and a syllabic /r/
+func testSyllabifier(t *testing.T) *Syllabifier {
+	return NewSyllabifier(
+		ConsonantHierarchy{
+			Onset:     true,
+			NoCluster: consonantList(t, "m", "n"),
+			Tiers:     [][]phonology.Consonant{consonantList(t, "s"), consonantList(t, "p", "t", "k"), consonantList(t, "l")},
+		},
+		NucleusHierarchy{
+			Onglides:     vowelList(t, "i"),
+			Nuclei:       vowelList(t, "a"),
+			Offglides:    vowelList(t, "u"),
+			Monophthongs: vowelList(t, "a", "i", "u", "aː"),
+			Consonants:   consonantList(t, "r"),
+		},
+		ConsonantHierarchy{NoCluster: consonantList(t, "n", "s", "l", "t")},
+	)
+}
+
+func TestSyllabify(t *testing.T) {
+	for _, tc := range []struct {
+		word        string
+		principle   SyllabificationPrinciple
+		stress      StressType
+		moraicCodas bool
+		ipa         string
+		legal       bool
+		morae       []int
+	}{
+		// consonants between nuclei go to the onset as far as the onset hierarchy allows
+		{word: "patla", ipa: "pa.tla", legal: true, morae: []int{1, 1}},
+		{word: "asta", ipa: "a.sta", legal: true, morae: []int{1, 1}},
+		{word: "anta", ipa: "an.ta", legal: true, morae: []int{1, 1}},
+		// or split where sonority is lowest, since /t/ is less sonorous than /s/
+		{word: "asta", principle: SonoritySYP, ipa: "as.ta", legal: true, morae: []int{1, 1}},
+		{word: "patla", principle: SonoritySYP, ipa: "pa.tla", legal: true, morae: []int{1, 1}},
+		// constituents the hierarchies do not allow are a best guess
+		{word: "aptka", ipa: "apt.ka", legal: false, morae: []int{1, 1}},
+		{word: "lpa", ipa: "lpa", legal: false, morae: []int{1}},
+		{word: "pank", ipa: "pank", legal: false, morae: []int{1}},
+		{word: "pe", ipa: "pe", legal: false, morae: []int{1}},
+		// a syllabic consonant is a nucleus only with no vowel beside it
+		{word: "prta", ipa: "pr.ta", legal: true, morae: []int{1, 1}},
+		{word: "para", ipa: "par.a", legal: false, morae: []int{1, 1}},
+		// glides join the nucleus where the nucleus hierarchy pairs them, and are syllables otherwise
+		{word: "pia", ipa: "pia", legal: true, morae: []int{1}},
+		{word: "pau", ipa: "pau", legal: true, morae: []int{2}},
+		{word: "piau", ipa: "piau", legal: true, morae: []int{2}},
+		{word: "pai", ipa: "pa.i", legal: true, morae: []int{1, 1}},
+		// weight and stress
+		{word: "paːn", moraicCodas: true, ipa: "paːn", legal: true, morae: []int{3}},
+		{word: "patala", stress: PenultimateST, ipa: "paˈta.la", legal: true, morae: []int{1, 1, 1}},
+		{word: "patala", stress: QuantitySensitivePenultimateST, ipa: "ˈpa.ta.la", legal: true, morae: []int{1, 1, 1}},
+		{word: "pataːla", stress: QuantitySensitivePenultimateST, ipa: "paˈtaː.la", legal: true, morae: []int{1, 2, 1}},
+		{word: "pantala", stress: QuantitySensitivePenultimateST, moraicCodas: true, ipa: "ˈpan.ta.la", legal: true, morae: []int{2, 1, 1}},
+	} {
+		s := testSyllabifier(t)
+		s.Principle, s.Stress, s.MoraicCodas = tc.principle, tc.stress, tc.moraicCodas
+		result, err := s.Syllabify(parseWord(t, tc.word))
+		if err != nil {
+			t.Errorf("%s: %v", tc.word, err)
+			continue
+		}
+		morae := []int{}
+		for _, syll := range result.Syllables {
+			morae = append(morae, syll.Morae)
+		}
+		if result.IPA != tc.ipa || result.Legal != tc.legal || !equalInts(morae, tc.morae) {
+			t.Errorf("%s: got %s, legal %t, morae %v, want %s, legal %t, morae %v", tc.word, result.IPA, result.Legal, morae, tc.ipa, tc.legal, tc.morae)
+		}
+		if tc.stress == UnspecifiedST && result.Stressed != -1 {
+			t.Errorf("%s: syllable %d stressed without a stress type", tc.word, result.Stressed)
+		}
+	}
+}
+
+func TestSyllabifyWithoutNucleus(t *testing.T) {
+	if result, err := testSyllabifier(t).Syllabify(parseWord(t, "pst")); err == nil {
+		t.Errorf("got %s, want an error", result.IPA)
+	}
+}
+
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// diphthongs and custom segments are single phonemes, checked against the nucleus hierarchy's
+// diphthongs and custom segments and the consonant hierarchies' custom tiers
+func TestSyllabifyDiphthongsAndSegments(t *testing.T) {
+	ai, err := phonology.NewDiphthongFromIPA("ai̯")
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := vowelList(t, "ə")[0]
+	ch := phonology.CustomSegment{Symbol: "ch", Name: "affricate"}
+	schwa := phonology.CustomSegment{Symbol: "E", Name: "reduced vowel", Vowel: e}
+	oe := phonology.CustomSegment{Symbol: "Ö", Name: "unlisted vowel", Vowel: e}
+	segments := []phonology.CustomSegment{ch, schwa, oe}
+
+	s := testSyllabifier(t)
+	s.Nucleus.Diphthongs = []phonology.Diphthong{ai}
+	s.Nucleus.Custom = []phonology.CustomSegment{schwa}
+	s.Onset.CustomTiers = [][]phonology.CustomSegment{{}, {ch}}
+
+	for _, tc := range []struct {
+		word  string
+		ipa   string
+		legal bool
+		morae []int
+	}{
+		{"pai̯", "pai̯", true, []int{2}},
+		{"pai̯pa", "pai̯.pa", true, []int{2, 1}},
+		{"ai̯a", "ai̯.a", true, []int{2, 1}},
+		{"pei̯", "pei̯", false, []int{2}},
+		{"chai̯", "chai̯", true, []int{2}},
+		{"aschla", "a.schla", true, []int{1, 1}},
+		{"pE", "pE", true, []int{1}},
+		{"pEta", "pE.ta", true, []int{1, 1}},
+		{"pÖ", "pÖ", false, []int{1}},
+		// /r/ beside a diphthong is no syllabic consonant
+		{"prai̯", "prai̯", false, []int{2}},
+	} {
+		word, err := phonology.ParseWordWithSegments(tc.word, segments)
+		if err != nil {
+			t.Fatal(err)
+		}
+		result, err := s.Syllabify(word)
+		if err != nil {
+			t.Errorf("%s: %v", tc.word, err)
+			continue
+		}
+		morae := []int{}
+		for _, syll := range result.Syllables {
+			morae = append(morae, syll.Morae)
+		}
+		if result.IPA != tc.ipa || result.Legal != tc.legal || !equalInts(morae, tc.morae) {
+			t.Errorf("%s: got %s, legal %t, morae %v, want %s, legal %t, morae %v", tc.word, result.IPA, result.Legal, morae, tc.ipa, tc.legal, tc.morae)
+		}
+	}
+}
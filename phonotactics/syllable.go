@@ -0,0 +1,93 @@
+package phonotactics
+
+import "github.com/jheredos/langgen/phonology"
+
+// SyllableConstituent is the part of a syllable that a phonotactic tree node
+// fills, which is needed to count morae and to tell codas from onsets when
+// both are consonants
+type SyllableConstituent uint8
+
+// SyllableConstituent values
+const (
+	UnspecifiedSC SyllableConstituent = iota
+	OnsetSC
+	OnglideSC
+	NucleusSC
+	OffglideSC
+	CodaSC
+	BoundarySC // word start and word end
+)
+
+// SyllableWeight is the classification of a syllable by its number of morae
+type SyllableWeight uint8
+
+// SyllableWeight values
+const (
+	UnspecifiedSW SyllableWeight = iota
+	LightSW                      // 1 mora, e.g. CV
+	HeavySW                      // 2 morae, e.g. CVV, or CVC when codas are moraic
+	SuperheavySW                 // 3 or more morae, e.g. CVVC
+)
+
+// Syllable is a sequence of phonotactic tree nodes making up one syllable
+// of a generated word, along with its weight
+type Syllable struct {
+	Nodes  []*PhonotacticTreeNode
+	Morae  int
+	Weight SyllableWeight
+}
+
+// newWeightedSyllable counts the morae of a path of nodes and wraps them in a Syllable.
+// The moraicCodas param determines whether codas contribute a mora (CVC is heavy) or not
+func newWeightedSyllable(nodes []*PhonotacticTreeNode, moraicCodas bool) Syllable {
+	morae := 0
+	for _, n := range nodes {
+		morae += n.morae(moraicCodas)
+	}
+	return Syllable{
+		Nodes:  nodes,
+		Morae:  morae,
+		Weight: WeightFromMorae(morae),
+	}
+}
+
+// morae returns the number of morae a node contributes to its syllable. Onsets and
+// onglides are never moraic, long vowels and diphthongs with an offglide count twice,
+// and codas count only if the language treats them as moraic
+func (n *PhonotacticTreeNode) morae(moraicCodas bool) int {
+	switch n.Constituent {
+	case NucleusSC:
+		if v, isVowel := n.Val.(phonology.Vowel); isVowel {
+			if v.Length == phonology.LongVL || v.Length == phonology.ExtraLongVL {
+				return 2
+			}
+		}
+		if d, isDiphthong := n.Val.(phonology.Diphthong); isDiphthong {
+			if d.Offglide != (phonology.Vowel{}) || d.Nucleus.Length == phonology.LongVL || d.Nucleus.Length == phonology.ExtraLongVL {
+				return 2
+			}
+		}
+		return 1
+	case OffglideSC:
+		return 1
+	case CodaSC:
+		if moraicCodas {
+			return 1
+		}
+	}
+	return 0
+}
+
+// WeightFromMorae classifies a number of morae as a light, heavy, or superheavy syllable
+func WeightFromMorae(morae int) SyllableWeight {
+	switch {
+	case morae <= 0:
+		return UnspecifiedSW
+	case morae == 1:
+		return LightSW
+	case morae == 2:
+		return HeavySW
+	default:
+		return SuperheavySW
+	}
+}
@@ -0,0 +1,55 @@
+package phonotactics
+
+import "github.com/jheredos/langgen/phonology"
+
+// CodaOnsetRelation is a relation between the coda of one syllable and the onset of the
+// next, used to condition C -> O edges across a syllable boundary on the coda's features
+type CodaOnsetRelation uint8
+
+// CodaOnsetRelation values
+const (
+	UnspecifiedCOR CodaOnsetRelation = iota // no relation, just the coda and onset patterns
+	IdenticalCOR                            // the onset repeats the coda, i.e. a geminate VC.CV
+	HomorganicCOR                           // the coda and onset share a place of articulation
+)
+
+// SetCodaOnsetFrequency finds all edges from a coda matching the coda pattern to an onset matching
+// the onset pattern across a syllable boundary, where the two also satisfy the relation, and sets
+// their weights according to the desired frequency. With AlwaysRF, the coda's other edges to onsets
+// matching the pattern are set to 0, e.g. nasal codas may only be followed by homorganic stops.
+// With NeverRF and no relation, it forbids the combination outright
+func (n *PhonotacticTreeNode) SetCodaOnsetFrequency(frequency RuleFrequency, relation CodaOnsetRelation, coda phonology.Pattern, onset phonology.Pattern) {
+	for _, c := range n.findPhoneme(coda) {
+		if c.Constituent != CodaSC {
+			continue
+		}
+		for _, edge := range c.Children {
+			if edge.Boundary != SyllableBoundaryPC || edge.ChildNode.Constituent != OnsetSC {
+				continue
+			}
+			if !onset.Matches(edge.ChildNode.Val) {
+				continue
+			}
+			if relation.holds(c.Val, edge.ChildNode.Val) {
+				if frequency < AlwaysRF {
+					edge.Weight = frequencyWeight(frequency)
+				}
+			} else if frequency == AlwaysRF {
+				edge.Weight = 0
+			}
+		}
+	}
+}
+
+// holds returns whether a coda and the following onset satisfy the relation
+func (r CodaOnsetRelation) holds(coda phonology.Phoneme, onset phonology.Phoneme) bool {
+	switch r {
+	case IdenticalCOR:
+		return coda == onset
+	case HomorganicCOR:
+		c, isConsonant := coda.(phonology.Consonant)
+		o, alsoConsonant := onset.(phonology.Consonant)
+		return isConsonant && alsoConsonant && c.Place == o.Place
+	}
+	return true
+}
@@ -0,0 +1,67 @@
+package phonotactics
+
+import (
+	"testing"
+
+	"github.com/jheredos/langgen/phonology"
+)
+
+// codaOnsetWeights builds a CVC tree with /p/ and /t/ as both onsets and codas, applies the
+// gemination frequency, and returns the weight of each coda-onset cluster by its IPA
+func codaOnsetWeights(t *testing.T, frequency RuleFrequency) map[string]float32 {
+	t.Helper()
+	cs := []phonology.Consonant{}
+	for _, s := range []string{"p", "t"} {
+		c, err := phonology.NewConsonantFromIPA(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cs = append(cs, c)
+	}
+	a, err := phonology.NewVowelFromIPA("a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	root, err := NewPhonotacticTree(
+		ConsonantHierarchy{Onset: true, NoCluster: cs},
+		NucleusHierarchy{Monophthongs: []phonology.Vowel{a}},
+		ConsonantHierarchy{NoCluster: cs},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root.SetGemination(frequency)
+
+	weights := map[string]float32{}
+	for _, coda := range root.findPhoneme(phonology.Consonant{}) {
+		if coda.Constituent != CodaSC {
+			continue
+		}
+		for _, edge := range coda.Children {
+			if edge.Boundary == SyllableBoundaryPC && edge.ChildNode.Constituent == OnsetSC {
+				weights[coda.Val.ToIPA()+edge.ChildNode.Val.ToIPA()] = edge.Weight
+			}
+		}
+	}
+	return weights
+}
+
+func TestGeminationTrendsOnlyReweightGeminates(t *testing.T) {
+	weights := codaOnsetWeights(t, VeryOftenRF)
+	if weights["pp"] != frequencyWeight(VeryOftenRF) || weights["tt"] != frequencyWeight(VeryOftenRF) {
+		t.Errorf("geminates should be weighted %v, got %v", frequencyWeight(VeryOftenRF), weights)
+	}
+	if weights["pt"] != 1 || weights["tp"] != 1 {
+		t.Errorf("other clusters should be left alone, got %v", weights)
+	}
+}
+
+func TestAlwaysGeminationRulesOutOtherClusters(t *testing.T) {
+	weights := codaOnsetWeights(t, AlwaysRF)
+	if weights["pp"] != 1 || weights["tt"] != 1 {
+		t.Errorf("geminates should keep their weight, got %v", weights)
+	}
+	if weights["pt"] != 0 || weights["tp"] != 0 {
+		t.Errorf("other clusters should be ruled out, got %v", weights)
+	}
+}
@@ -0,0 +1,78 @@
+package phonotactics
+
+import (
+	"testing"
+
+	"github.com/jheredos/langgen/phonology"
+)
+
+func consonantList(t *testing.T, ipa ...string) []phonology.Consonant {
+	t.Helper()
+	cs := []phonology.Consonant{}
+	for _, s := range ipa {
+		c, err := phonology.NewConsonantFromIPA(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cs = append(cs, c)
+	}
+	return cs
+}
+
+func vowelList(t *testing.T, ipa ...string) []phonology.Vowel {
+	t.Helper()
+	vs := []phonology.Vowel{}
+	for _, s := range ipa {
+		v, err := phonology.NewVowelFromIPA(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		vs = append(vs, v)
+	}
+	return vs
+}
+
+func TestMorae(t *testing.T) {
+	a := vowelList(t, "a")[0]
+	long := vowelList(t, "aː")[0]
+	ai, err := phonology.NewDiphthongFromIPA("ai̯")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ia, err := phonology.NewDiphthongFromIPA("i̯a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := consonantList(t, "p")[0]
+
+	for _, tc := range []struct {
+		val         phonology.Phoneme
+		constituent SyllableConstituent
+		moraicCodas bool
+		want        int
+	}{
+		{p, OnsetSC, true, 0},
+		{a, OnglideSC, false, 0},
+		{a, NucleusSC, false, 1},
+		{long, NucleusSC, false, 2},
+		{ai, NucleusSC, false, 2},
+		{ia, NucleusSC, false, 1},
+		{a, OffglideSC, false, 1},
+		{p, CodaSC, false, 0},
+		{p, CodaSC, true, 1},
+		{phonology.WordBoundary{Final: true}, BoundarySC, true, 0},
+	} {
+		n := &PhonotacticTreeNode{Val: tc.val, Constituent: tc.constituent}
+		if got := n.morae(tc.moraicCodas); got != tc.want {
+			t.Errorf("/%s/ as constituent %d: got %d morae, want %d", tc.val.ToIPA(), tc.constituent, got, tc.want)
+		}
+	}
+}
+
+func TestWeightFromMorae(t *testing.T) {
+	for morae, want := range []SyllableWeight{UnspecifiedSW, LightSW, HeavySW, SuperheavySW, SuperheavySW} {
+		if got := WeightFromMorae(morae); got != want {
+			t.Errorf("%d morae: got weight %d, want %d", morae, got, want)
+		}
+	}
+}
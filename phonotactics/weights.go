@@ -4,9 +4,11 @@ import "github.com/jheredos/langgen/phonology"
 
 // SetFrequencyForPattern finds all occurrences in a phonotactic tree of phoneme pattern A followed
 // by pattern B and sets the weights of those edges according to the desired frequency. The patterns
-// can be Vowels or Consonants, fully or partially described, and the caller can optionally specify
-// the context(s), i.e. syllable internal or cross-syllable
-func (n *PhonotacticTreeNode) SetFrequencyForPattern(frequency RuleFrequency, patternA phonology.Phoneme, patternB phonology.Phoneme, contexts ...PhonotacticContext) {
+// can be Vowels or Consonants, fully or partially described, feature bundles, or combinations of
+// them, and the caller can optionally specify the context(s), i.e. syllable internal or cross-syllable.
+// Longer sequences can't be set on the tree, which only knows the previous phoneme, and are added to
+// the WordGenerator as SequenceConstraints instead
+func (n *PhonotacticTreeNode) SetFrequencyForPattern(frequency RuleFrequency, patternA phonology.Pattern, patternB phonology.Pattern, contexts ...PhonotacticContext) {
 	nodes, edges := n.findPattern(patternA, patternB, contexts)
 	setWeights(nodes, edges, frequency)
 }
@@ -14,18 +16,18 @@ func (n *PhonotacticTreeNode) SetFrequencyForPattern(frequency RuleFrequency, pa
 // findPattern finds a pattern of two phonemes, a and b, across given contexts (syllable internal,
 // word-final, etc). The phonemes can be consonants, vowels, or word boundaries, including partially
 // specified phonemes, like unvoiced consonants or rounded vowels
-func (n *PhonotacticTreeNode) findPattern(patternA phonology.Phoneme, patternB phonology.Phoneme, contexts []PhonotacticContext) ([]*PhonotacticTreeNode, []*PhonotacticTreeEdge) {
+func (n *PhonotacticTreeNode) findPattern(patternA phonology.Pattern, patternB phonology.Pattern, contexts []PhonotacticContext) ([]*PhonotacticTreeNode, []*PhonotacticTreeEdge) {
 	aNodes := n.findPhoneme(patternA)
 	edges := []*PhonotacticTreeEdge{}
 
 	for _, node := range aNodes {
 		for _, edge := range node.Children {
-			if len(contexts) == 0 && edge.ChildNode.Val.Match(patternB) {
+			if len(contexts) == 0 && patternB.Matches(edge.ChildNode.Val) {
 				edges = append(edges, edge)
 				continue
 			}
 			for _, context := range contexts {
-				if edge.Boundary == context && edge.ChildNode.Val.Match(patternB) {
+				if edge.Boundary == context && patternB.Matches(edge.ChildNode.Val) {
 					edges = append(edges, edge)
 					break
 				}
@@ -36,19 +38,38 @@ func (n *PhonotacticTreeNode) findPattern(patternA phonology.Phoneme, patternB p
 	return aNodes, edges
 }
 
-// findPhoneme finds all occurrences of a given phoneme in a phonotactic tree. The phoneme
-// can be partially specified, like all unvoiced consonants (i.e. Consonant{Voiced: VoicedCV})
-// or all rounded vowels (Vowel{Rounding: RoundedVR})
-func (n *PhonotacticTreeNode) findPhoneme(pattern phonology.Phoneme) []*PhonotacticTreeNode {
+// findPhoneme finds all occurrences of phonemes matching a pattern in a phonotactic tree, like
+// all unvoiced consonants (i.e. Consonant{Voiced: VoicedCV}) or all rounded vowels
+// (Vowel{Rounding: RoundedVR})
+func (n *PhonotacticTreeNode) findPhoneme(pattern phonology.Pattern) []*PhonotacticTreeNode {
 	nodes := []*PhonotacticTreeNode{}
+	n.walk(func(node *PhonotacticTreeNode) {
+		if pattern.Matches(node.Val) {
+			nodes = append(nodes, node)
+		}
+	})
+	return nodes
+}
+
+// FindConstituent finds all nodes in a phonotactic tree that fill the given syllable constituent
+func (n *PhonotacticTreeNode) FindConstituent(constituent SyllableConstituent) []*PhonotacticTreeNode {
+	nodes := []*PhonotacticTreeNode{}
+	n.walk(func(node *PhonotacticTreeNode) {
+		if node.Constituent == constituent {
+			nodes = append(nodes, node)
+		}
+	})
+	return nodes
+}
+
+// walk visits every node in a phonotactic tree once, breadth first
+func (n *PhonotacticTreeNode) walk(visit func(*PhonotacticTreeNode)) {
 	seen := map[*PhonotacticTreeNode]bool{}
 
 	row, nextRow := []*PhonotacticTreeNode{n}, []*PhonotacticTreeNode{}
 	for len(row) > 0 {
 		for _, node := range row {
-			if node.Val.Match(pattern) {
-				nodes = append(nodes, node)
-			}
+			visit(node)
 			for _, edge := range node.Children {
 				if _, alreadySeen := seen[edge.ChildNode]; alreadySeen {
 					continue
@@ -59,29 +80,12 @@ func (n *PhonotacticTreeNode) findPhoneme(pattern phonology.Phoneme) []*Phonotac
 		}
 		row, nextRow = nextRow, []*PhonotacticTreeNode{}
 	}
-
-	return nodes
 }
 
 // setWeights adjusts the weights of a slice of phonotactic tree edges by a float32 weight
 func setWeights(nodes []*PhonotacticTreeNode, edges []*PhonotacticTreeEdge, frequency RuleFrequency) {
-	var weight float32 = 1
-	var factor float32 = 2
-	switch frequency {
-	case NeverRF:
-		weight = 0
-	case VerySeldomRF:
-		weight = 1 / (factor * factor) // factor ^ -2
-	case SeldomRF:
-		weight = 1 / factor // factor ^ -1
-	case SometimesRF:
-		weight = 1 // factor ^ 0
-	case OftenRF:
-		weight = factor // factor ^ 1
-	case VeryOftenRF:
-		weight = factor * factor // factor ^ 2
-	}
 	if frequency < AlwaysRF {
+		weight := frequencyWeight(frequency)
 		for _, edge := range edges {
 			edge.Weight = weight
 		}
@@ -99,3 +103,25 @@ func setWeights(nodes []*PhonotacticTreeNode, edges []*PhonotacticTreeEdge, freq
 		}
 	}
 }
+
+// frequencyWeight converts a RuleFrequency into an edge weight relative to the default
+// weight of 1. AlwaysRF has no single weight, since it is applied by zeroing sibling edges
+func frequencyWeight(frequency RuleFrequency) float32 {
+	var weight float32 = 1
+	var factor float32 = 2
+	switch frequency {
+	case NeverRF:
+		weight = 0
+	case VerySeldomRF:
+		weight = 1 / (factor * factor) // factor ^ -2
+	case SeldomRF:
+		weight = 1 / factor // factor ^ -1
+	case SometimesRF:
+		weight = 1 // factor ^ 0
+	case OftenRF:
+		weight = factor // factor ^ 1
+	case VeryOftenRF:
+		weight = factor * factor // factor ^ 2
+	}
+	return weight
+}
@@ -1,13 +1,22 @@
 package phonotactics
 
 import (
+	"errors"
 	"math/rand"
 	"time"
 )
 
-// WordGenerator wraps a Phonotactic tree
+// WordGenerator wraps a Phonotactic tree. MoraicCodas determines whether codas
+// add to a syllable's weight, and Stress, if specified, marks the stressed syllable
+// of each generated word. LengthUnit is what NewWordOfLength counts. OCP and sequence
+// constraints are applied while generating each word
 type WordGenerator struct {
-	Root *PhonotacticTreeNode
+	Root        *PhonotacticTreeNode
+	MoraicCodas bool
+	Stress      StressType
+	LengthUnit  LengthUnit
+	OCP         []OCPConstraint
+	Sequences   []SequenceConstraint
 }
 
 // NewWordGenerator creates a new WordGenerator from the root
@@ -22,18 +31,162 @@ func NewWordGenerator(root *PhonotacticTreeNode) *WordGenerator {
 	return wg
 }
 
+// SetOptions applies the syllable weight, stress and length unit settings of a language's
+// PhonotacticOptions to the WordGenerator
+func (g *WordGenerator) SetOptions(options PhonotacticOptions) {
+	g.MoraicCodas = options.MoraicCodas
+	g.Stress = options.StressType
+	g.LengthUnit = options.LengthUnit
+}
+
+// NewWordOfLength generates a new word of the specified length as a string, counted in
+// morae if the generator's LengthUnit is MoraLU and in syllables otherwise
+func (g *WordGenerator) NewWordOfLength(length int) (string, error) {
+	if g.LengthUnit == MoraLU {
+		return g.NewWordInMorae(length)
+	}
+	return g.NewWord(length)
+}
+
+// ErrNoWord is returned when the constraints on a word leave no way to complete it, e.g. an OCP
+// constraint set to NeverRF that rules out every onset of its second syllable
+var ErrNoWord = errors.New("the phonotactic constraints leave no way to complete the word")
+
+// maxSyllableAttempts limits how many times a syllable that runs into a dead end is regenerated,
+// and maxWordAttempts how many times a word is restarted when one of its syllables cannot be
+const (
+	maxSyllableAttempts = 20
+	maxWordAttempts     = 20
+)
+
 // NewWord generates a new word of the specified number of syllables as a string
-func (g *WordGenerator) NewWord(syllables int) string {
-	syllable := []*PhonotacticTreeNode{}
+func (g *WordGenerator) NewWord(syllables int) (string, error) {
+	sylls, err := g.NewSyllables(syllables)
+	if err != nil {
+		return "", err
+	}
+	return g.syllablesToIPA(sylls), nil
+}
+
+// NewSyllables generates the syllables of a new word of the specified length,
+// each weighed in morae. A syllable that cannot be completed without breaking a
+// constraint is regenerated, and the word restarted if that keeps failing
+func (g *WordGenerator) NewSyllables(syllables int) ([]Syllable, error) {
+	for attempt := 0; attempt < maxWordAttempts; attempt++ {
+		if sylls, ok := g.trySyllables(syllables); ok {
+			return sylls, nil
+		}
+	}
+	return nil, ErrNoWord
+}
+
+func (g *WordGenerator) trySyllables(syllables int) ([]Syllable, bool) {
+	sylls := []Syllable{}
 	prev := g.Root
-	word := ""
+	history := g.newWordHistory()
 
 	for i := 0; i < syllables; i++ {
-		syllable, prev = prev.newSyllable(i == syllables-1)
-		word += phonotacticPathToIPA(syllable)
-		if i < syllables-1 {
+		nodes, next, ok := prev.retrySyllable(i == syllables-1, history)
+		if !ok {
+			return nil, false
+		}
+		sylls = append(sylls, newWeightedSyllable(nodes, g.MoraicCodas))
+		prev = next
+	}
+
+	return sylls, true
+}
+
+// maxMoraAttempts limits how many times NewWordInMorae may regenerate a syllable
+// that overshoots the target before settling for the closest word it can make
+const maxMoraAttempts = 100
+
+// NewWordInMorae generates a new word with the specified number of morae as a string,
+// for languages where word length is better measured in morae than in syllables.
+// Syllables that would overshoot the target are regenerated, so the word may fall
+// short of or exceed the target only if the tree cannot produce an exact fit
+func (g *WordGenerator) NewWordInMorae(morae int) (string, error) {
+	for attempt := 0; attempt < maxWordAttempts; attempt++ {
+		if sylls, ok := g.trySyllablesInMorae(morae); ok {
+			return g.syllablesToIPA(sylls), nil
+		}
+	}
+	return "", ErrNoWord
+}
+
+func (g *WordGenerator) trySyllablesInMorae(morae int) ([]Syllable, bool) {
+	sylls := []Syllable{}
+	prev := g.Root
+	total := 0
+	history := g.newWordHistory()
+
+	for total < morae {
+		remaining := morae - total
+		var syll Syllable
+		var next *PhonotacticTreeNode
+		found := false
+
+		for attempt := 0; attempt < 2*maxMoraAttempts && !found; attempt++ {
+			// try to end the word with this syllable first, settling for any ending once out of attempts
+			mark := history.mark()
+			nodes, end, ok := prev.newSyllable(true, history)
+			if ok {
+				syll, next = newWeightedSyllable(nodes, g.MoraicCodas), end
+				if syll.Morae == remaining || attempt >= maxMoraAttempts {
+					found = true
+					break
+				}
+			}
+			history.reset(mark)
+			nodes, end, ok = prev.newSyllable(false, history)
+			if ok {
+				syll, next = newWeightedSyllable(nodes, g.MoraicCodas), end
+				found = syll.Morae > 0 && syll.Morae < remaining
+			}
+			if !found {
+				history.reset(mark)
+			}
+		}
+		if !found {
+			return nil, false
+		}
+
+		sylls = append(sylls, syll)
+		total += syll.Morae
+		prev = next
+		if syll.Morae == 0 || isWordEnd(next) {
+			break
+		}
+	}
+
+	return sylls, true
+}
+
+// isWordEnd returns whether a node is the WordBoundary at the end of the tree
+func isWordEnd(n *PhonotacticTreeNode) bool {
+	return n.Constituent == BoundarySC && len(n.Children) == 0
+}
+
+// syllablesToIPA joins a word's syllables with "." as a syllable break, or with the
+// stress mark before the stressed syllable if the generator has a StressType
+func (g *WordGenerator) syllablesToIPA(sylls []Syllable) string {
+	weights := []SyllableWeight{}
+	for _, syll := range sylls {
+		weights = append(weights, syll.Weight)
+	}
+	stressed := StressedSyllable(weights, g.Stress)
+	if len(sylls) < 2 {
+		stressed = -1
+	}
+
+	word := ""
+	for i, syll := range sylls {
+		if i == stressed {
+			word += "ˈ"
+		} else if i > 0 {
 			word += "."
 		}
+		word += phonotacticPathToIPA(syll.Nodes)
 	}
 
 	return word
@@ -47,68 +200,84 @@ func phonotacticPathToIPA(path []*PhonotacticTreeNode) string {
 	return s
 }
 
+// retrySyllable generates a syllable from the receiver node as newSyllable does, regenerating it
+// if it runs into a dead end. It returns false if every attempt did
+func (n *PhonotacticTreeNode) retrySyllable(final bool, history *wordHistory) ([]*PhonotacticTreeNode, *PhonotacticTreeNode, bool) {
+	mark := history.mark()
+	for attempt := 0; attempt < maxSyllableAttempts; attempt++ {
+		if syll, next, ok := n.newSyllable(final, history); ok {
+			return syll, next, true
+		}
+		history.reset(mark)
+	}
+	return nil, nil, false
+}
+
 // newSyllable generates random nodes from the receiver node until hitting a syllable or word boundary.
 // It returns a slice of nodes and the final node that crossed the boundary, either WordBoundary or the
-// first node of the next syllable. The final param allows the caller to determine when to end the word
-func (n *PhonotacticTreeNode) newSyllable(final bool) ([]*PhonotacticTreeNode, *PhonotacticTreeNode) {
+// first node of the next syllable. The final param allows the caller to determine when to end the word.
+// The history param records the nodes generated so far in the word, so that constraints like the OCP
+// and sequence constraints can reach back across syllable boundaries. It may be nil if there are no such constraints.
+// It returns false if it reaches a node with no edge it may take, leaving the history for the caller to reset
+func (n *PhonotacticTreeNode) newSyllable(final bool, history *wordHistory) ([]*PhonotacticTreeNode, *PhonotacticTreeNode, bool) {
+	// a syllable begun by a nucleus, after hiatus, may end right after it
+	end := SyllableBoundaryPC
+	if final {
+		end = WordEndPC
+	}
+
 	syll := []*PhonotacticTreeNode{n}
-	node, boundary := n.randomNode(WordStartPC, OnsetPC, NucleusPC, CodaPC)
+	node, boundary, ok := n.randomNode(history, WordStartPC, OnsetPC, NucleusPC, CodaPC, end)
+	if !ok {
+		return nil, nil, false
+	}
+	history.push(node, boundary)
 
 	for boundary != WordEndPC && boundary != SyllableBoundaryPC {
 		syll = append(syll, node)
-		if final {
-			node, boundary = node.randomNode(OnsetPC, NucleusPC, CodaPC, WordEndPC)
-		} else {
-			node, boundary = node.randomNode(OnsetPC, NucleusPC, CodaPC, SyllableBoundaryPC)
+		node, boundary, ok = node.randomNode(history, OnsetPC, NucleusPC, CodaPC, end)
+		if !ok {
+			return nil, nil, false
 		}
+		history.push(node, boundary)
 	}
 
-	return syll, node
+	return syll, node, true
 }
 
 // randomNode returns a random child of the receiver node over any PhonotacticContext specified
-// in the params, according to the weights of those edges
-func (n *PhonotacticTreeNode) randomNode(boundaries ...PhonotacticContext) (*PhonotacticTreeNode, PhonotacticContext) {
+// in the params, according to the weights of those edges as adjusted by the word's history.
+// It returns false if there is no such edge with a positive weight, e.g. when an OCP constraint
+// set to NeverRF rules out every one of them
+func (n *PhonotacticTreeNode) randomNode(history *wordHistory, boundaries ...PhonotacticContext) (*PhonotacticTreeNode, PhonotacticContext, bool) {
 	var wsum float32 = 0
 	edges := []*PhonotacticTreeEdge{}
+	weights := []float32{}
 
 	for _, edge := range n.Children {
 		for _, b := range boundaries {
 			if edge.Boundary == b {
+				w := history.weight(edge)
+				if w <= 0 {
+					continue
+				}
 				edges = append(edges, edge)
-				wsum += edge.Weight
+				weights = append(weights, w)
+				wsum += w
 			}
 		}
 	}
+	if len(edges) == 0 {
+		return nil, UnspecifiedPC, false
+	}
 
 	k := rand.Float32() * wsum
-	for _, edge := range edges {
-		k -= edge.Weight
+	for i, edge := range edges {
+		k -= weights[i]
 		if k <= 0 {
-			return edge.ChildNode, edge.Boundary
+			return edge.ChildNode, edge.Boundary, true
 		}
 	}
 
-	return edges[len(edges)-1].ChildNode, edges[len(edges)-1].Boundary
-}
-
-func round(n float32) int {
-	return int(n*10) / 10
-}
-
-//
-func skewedRand(max int) int {
-	a := rand.Float32() * float32(max)
-	b := rand.Float32() * float32(max)
-	if a < b {
-		return round(a)
-	}
-	return round(b)
-}
-
-// GetWordLength ... this seems to skew really high...
-func GetWordLength(min, median, max WordLength) int {
-	lens := []int{1, 1, 2, 3, 6, 10, 15}
-	n := lens[median] + skewedRand(lens[median]/2+1) - skewedRand(lens[median]/2+1)
-	return n - skewedRand(lens[min]) + skewedRand(lens[max])
+	return edges[len(edges)-1].ChildNode, edges[len(edges)-1].Boundary, true
 }
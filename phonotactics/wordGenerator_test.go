@@ -0,0 +1,182 @@
+package phonotactics
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jheredos/langgen/phonology"
+)
+
+// openSyllableGenerator builds a generator for CV syllables from the onsets, with /a/ as the only
+// nucleus and no null onsets or hiatus, so that every syllable after the first needs an onset
+func openSyllableGenerator(t *testing.T, onsets ...string) *WordGenerator {
+	t.Helper()
+	cs := []phonology.Consonant{}
+	for _, s := range onsets {
+		c, err := phonology.NewConsonantFromIPA(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cs = append(cs, c)
+	}
+	a, err := phonology.NewVowelFromIPA("a")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	root, err := NewPhonotacticTree(
+		ConsonantHierarchy{Onset: true, NoCluster: cs},
+		NucleusHierarchy{Monophthongs: []phonology.Vowel{a}},
+		ConsonantHierarchy{},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root.SetInitialNullOnset(NeverRF)
+	root.SetHiatus(NeverRF)
+	return NewWordGenerator(root)
+}
+
+func TestNeverOCPIsNotViolated(t *testing.T) {
+	g := openSyllableGenerator(t, "p", "t")
+	g.AddOCPConstraint(OCPConstraint{Similarity: IdenticalOS, AdjacentSyllables: true, Frequency: NeverRF})
+
+	for i := 0; i < 200; i++ {
+		word, err := g.NewWord(2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sylls := strings.Split(word, ".")
+		if len(sylls) != 2 || sylls[0][0] == sylls[1][0] {
+			t.Fatalf("%s breaks the OCP constraint", word)
+		}
+	}
+}
+
+func TestUnsatisfiableConstraintsReportNoWord(t *testing.T) {
+	g := openSyllableGenerator(t, "p")
+	g.AddOCPConstraint(OCPConstraint{Similarity: IdenticalOS, AdjacentSyllables: true, Frequency: NeverRF})
+	if word, err := g.NewWord(1); err != nil {
+		t.Fatalf("one syllable should be possible, got %v", err)
+	} else if word != "pa" {
+		t.Fatalf("got %s, want pa", word)
+	}
+	if word, err := g.NewWord(2); err != ErrNoWord {
+		t.Fatalf("got %q, %v, want ErrNoWord", word, err)
+	}
+	// words in morae may fall short of the target rather than break the constraint
+	if word, err := g.NewWordInMorae(2); err != nil || word != "pa" {
+		t.Fatalf("got %q, %v, want pa", word, err)
+	}
+
+	// every syllable must be followed by /t/, which the language does not have
+	tStop, err := phonology.NewConsonantFromIPA("t")
+	if err != nil {
+		t.Fatal(err)
+	}
+	g = openSyllableGenerator(t, "p")
+	g.AddSequenceConstraint(SequenceConstraint{
+		Sequence:  Sequence{{Pattern: phonology.Vowel{}}, {Pattern: phonology.SyllableBoundary{}}, {Pattern: tStop}},
+		Frequency: AlwaysRF,
+	})
+	if word, err := g.NewWord(2); err != ErrNoWord {
+		t.Fatalf("got %q, %v, want ErrNoWord", word, err)
+	}
+}
+
+func TestRandomNodeWithoutEdges(t *testing.T) {
+	leaf := &PhonotacticTreeNode{Val: phonology.WordBoundary{Final: true}, Constituent: BoundarySC}
+	if node, _, ok := leaf.randomNode(nil, OnsetPC, WordEndPC); ok || node != nil {
+		t.Fatalf("got %v, want no node", node)
+	}
+}
+
+// in a language without codas, a syllable begun by its nucleus, after hiatus or as a null onset,
+// has nothing to add before it ends, so it must be able to end right away
+func TestNucleusOnlySyllablesEnd(t *testing.T) {
+	tStop, err := phonology.NewConsonantFromIPA("t")
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, err := phonology.NewVowelFromIPA("a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	root, err := NewPhonotacticTree(
+		ConsonantHierarchy{Onset: true, NoCluster: []phonology.Consonant{tStop}},
+		NucleusHierarchy{Monophthongs: []phonology.Vowel{a}},
+		ConsonantHierarchy{},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	PhonotacticRules{InitialNullOnset: VeryOftenRF, Hiatus: VeryOftenRF}.Apply(root)
+	g := NewWordGenerator(root)
+
+	nucleusOnly := false
+	for i := 0; i < 200; i++ {
+		word, err := g.NewWord(2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sylls := strings.Split(word, ".")
+		if len(sylls) != 2 {
+			t.Fatalf("%s does not have 2 syllables", word)
+		}
+		for _, syll := range sylls {
+			if syll != "ta" && syll != "a" {
+				t.Fatalf("%s has the syllable %s, want ta or a", word, syll)
+			}
+			nucleusOnly = nucleusOnly || syll == "a"
+		}
+	}
+	if !nucleusOnly {
+		t.Fatal("no syllable was begun by its nucleus")
+	}
+}
+
+// with light /pa/ and heavy /paː/ syllables, every number of morae can be hit exactly
+func TestNewWordInMorae(t *testing.T) {
+	root, err := NewPhonotacticTree(
+		ConsonantHierarchy{Onset: true, NoCluster: consonantList(t, "p")},
+		NucleusHierarchy{Monophthongs: vowelList(t, "a", "aː")},
+		ConsonantHierarchy{},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root.SetInitialNullOnset(NeverRF)
+	root.SetHiatus(NeverRF)
+	g := NewWordGenerator(root)
+
+	morae := func(word string) (int, int) {
+		sylls := strings.Split(word, ".")
+		return len(sylls), len(sylls) + strings.Count(word, "ː")
+	}
+	for target := 1; target <= 6; target++ {
+		for i := 0; i < 50; i++ {
+			word, err := g.NewWordInMorae(target)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, got := morae(word); got != target {
+				t.Fatalf("%s has %d morae, want %d", word, got, target)
+			}
+		}
+	}
+
+	// NewWordOfLength counts in the generator's unit
+	for _, unit := range []LengthUnit{SyllableLU, MoraLU} {
+		g.SetOptions(PhonotacticOptions{LengthUnit: unit})
+		for i := 0; i < 50; i++ {
+			word, err := g.NewWordOfLength(3)
+			if err != nil {
+				t.Fatal(err)
+			}
+			syllables, m := morae(word)
+			if unit == SyllableLU && syllables != 3 || unit == MoraLU && m != 3 {
+				t.Fatalf("%s has %d syllables and %d morae, want 3 in unit %d", word, syllables, m, unit)
+			}
+		}
+	}
+}
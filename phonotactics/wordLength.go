@@ -0,0 +1,157 @@
+package phonotactics
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+)
+
+// WordLengthDistribution is a probability mass function over word lengths,
+// where PMF[i] is the probability of a word having i syllables, or i morae for languages
+// whose LengthUnit is MoraLU
+type WordLengthDistribution struct {
+	PMF []float64 `json:"pmf"`
+}
+
+// lengthQuantile is the z-score of the 99th percentile of a normal distribution,
+// used to place the language's max word length in the tail of the log-normal
+const lengthQuantile = 2.326
+
+// NewWordLengthDistribution creates the word length distribution described by a language's
+// PhonotacticOptions. Unspecified word lengths default to a min of 1 syllable, a median of
+// 2, and a max of 3, and an unspecified LengthModel defaults to LogNormalLM
+func NewWordLengthDistribution(options PhonotacticOptions) (WordLengthDistribution, error) {
+	min, median, max := options.MinWordLength, options.MedianWordLength, options.MaxWordLength
+	if min == UnspecifiedWL {
+		min = MonosyllabicWL
+	}
+	if median == UnspecifiedWL {
+		median = ShortWL
+	}
+	if max == UnspecifiedWL {
+		max = MediumWL
+	}
+
+	switch options.LengthModel {
+	case PoissonLM:
+		return PoissonWordLengths(min, median, max), nil
+	case HistogramLM:
+		return HistogramWordLengths(options.LengthHistogram)
+	}
+	return LogNormalWordLengths(min, median, max), nil
+}
+
+// LogNormalWordLengths creates a discretized log-normal distribution truncated to the range
+// min to max, with its median at the median word length and the max at its 99th percentile
+// (or the min at its 1st, if the min is further from the median)
+func LogNormalWordLengths(min, median, max WordLength) WordLengthDistribution {
+	lo, mid, hi := clampLengths(min.Syllables(), median.Syllables(), max.Syllables())
+
+	mu := math.Log(float64(mid))
+	sigma := math.Max(math.Log(float64(hi)+0.5)-mu, mu-math.Log(float64(lo)-0.5)) / lengthQuantile
+
+	pmf := make([]float64, hi+1)
+	for k := lo; k <= hi; k++ {
+		pmf[k] = normalCDF(math.Log(float64(k)+0.5), mu, sigma) - normalCDF(math.Log(float64(k)-0.5), mu, sigma)
+	}
+
+	return normalizeLengths(pmf)
+}
+
+// PoissonWordLengths creates a Poisson distribution shifted to start at the min word length
+// with its mean at the median, truncated at the max
+func PoissonWordLengths(min, median, max WordLength) WordLengthDistribution {
+	lo, mid, hi := clampLengths(min.Syllables(), median.Syllables(), max.Syllables())
+
+	lambda := float64(mid - lo)
+	pmf := make([]float64, hi+1)
+	p := math.Exp(-lambda) // P(X = 0)
+	for k := lo; k <= hi; k++ {
+		pmf[k] = p
+		p *= lambda / float64(k-lo+1)
+	}
+
+	return normalizeLengths(pmf)
+}
+
+// HistogramWordLengths creates a distribution from explicit relative weights, where
+// weights[i] is the weight of words of i+1 syllables
+func HistogramWordLengths(weights []float64) (WordLengthDistribution, error) {
+	pmf := make([]float64, len(weights)+1)
+	sum := 0.0
+	for i, w := range weights {
+		if w < 0 || math.IsNaN(w) || math.IsInf(w, 0) {
+			return WordLengthDistribution{}, errors.New("word length histogram weights must be non-negative numbers")
+		}
+		pmf[i+1] = w
+		sum += w
+	}
+	if sum == 0 {
+		return WordLengthDistribution{}, errors.New("word length histogram must have at least one positive weight")
+	}
+
+	return normalizeLengths(pmf), nil
+}
+
+// Sample returns a random word length in syllables drawn from the distribution
+func (d WordLengthDistribution) Sample() int {
+	k := rand.Float64()
+	for length, p := range d.PMF {
+		k -= p
+		if k < 0 {
+			return length
+		}
+	}
+
+	// rounding errors can leave k slightly above 0, so fall back on the longest length
+	for length := len(d.PMF) - 1; length > 0; length-- {
+		if d.PMF[length] > 0 {
+			return length
+		}
+	}
+	return 1
+}
+
+// Mean returns the expected word length in syllables
+func (d WordLengthDistribution) Mean() float64 {
+	mean := 0.0
+	for length, p := range d.PMF {
+		mean += float64(length) * p
+	}
+	return mean
+}
+
+// clampLengths makes sure that 1 <= lo <= mid <= hi
+func clampLengths(lo, mid, hi int) (int, int, int) {
+	if lo < 1 {
+		lo = 1
+	}
+	if mid < lo {
+		mid = lo
+	}
+	if hi < mid {
+		hi = mid
+	}
+	return lo, mid, hi
+}
+
+func normalizeLengths(pmf []float64) WordLengthDistribution {
+	sum := 0.0
+	for _, p := range pmf {
+		sum += p
+	}
+	for i := range pmf {
+		pmf[i] /= sum
+	}
+	return WordLengthDistribution{PMF: pmf}
+}
+
+func normalCDF(x, mu, sigma float64) float64 {
+	if sigma == 0 {
+		if x < mu {
+			return 0
+		}
+		return 1
+	}
+	return 0.5 * math.Erfc(-(x-mu)/(sigma*math.Sqrt2))
+}
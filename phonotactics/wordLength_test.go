@@ -0,0 +1,128 @@
+package phonotactics
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+// lengthSamples is the number of word lengths drawn from each distribution
+const lengthSamples = 50000
+
+// frequencyTolerance is how far the frequency of a length drawn may be from the one expected
+const frequencyTolerance = 0.01
+
+// sampleLengths draws word lengths from the distribution with a fixed seed, returning them
+// sorted along with the frequency of each length
+func sampleLengths(d WordLengthDistribution) ([]int, map[int]float64) {
+	rand.Seed(1)
+	lengths := make([]int, lengthSamples)
+	freqs := map[int]float64{}
+	for i := range lengths {
+		lengths[i] = d.Sample()
+		freqs[lengths[i]] += 1.0 / lengthSamples
+	}
+	sort.Ints(lengths)
+	return lengths, freqs
+}
+
+func TestWordLengthDistributions(t *testing.T) {
+	poisson := func(k, lo int, lambda float64) float64 {
+		return math.Exp(-lambda) * math.Pow(lambda, float64(k-lo)) / math.Gamma(float64(k-lo+1))
+	}
+
+	for _, tc := range []struct {
+		name     string
+		options  PhonotacticOptions
+		min, max int
+		median   int
+		mean     float64 // 0 to skip
+		expected map[int]float64
+	}{
+		{
+			name:    "log-normal default",
+			options: PhonotacticOptions{},
+			min:     1, median: 2, max: 3,
+		},
+		{
+			name:    "log-normal long",
+			options: PhonotacticOptions{MinWordLength: MonosyllabicWL, MedianWordLength: MediumWL, MaxWordLength: XLongWL, LengthModel: LogNormalLM},
+			min:     1, median: 3, max: 10,
+		},
+		{
+			name:    "log-normal without monosyllables",
+			options: PhonotacticOptions{MinWordLength: ShortWL, MedianWordLength: MediumWL, MaxWordLength: LongWL, LengthModel: LogNormalLM},
+			min:     2, median: 3, max: 6,
+		},
+		{
+			name:    "poisson",
+			options: PhonotacticOptions{MinWordLength: MonosyllabicWL, MedianWordLength: ShortWL, MaxWordLength: XLongWL, LengthModel: PoissonLM},
+			min:     1, median: 2, max: 10,
+			mean: 2,
+			expected: map[int]float64{
+				1: poisson(1, 1, 1), 2: poisson(2, 1, 1), 3: poisson(3, 1, 1), 4: poisson(4, 1, 1),
+			},
+		},
+		{
+			name:    "poisson from two syllables",
+			options: PhonotacticOptions{MinWordLength: ShortWL, MedianWordLength: LongWL, MaxWordLength: XXLongWL, LengthModel: PoissonLM},
+			min:     2, median: 6, max: 15,
+			mean: 6,
+		},
+		{
+			name:    "histogram",
+			options: PhonotacticOptions{LengthModel: HistogramLM, LengthHistogram: []float64{1, 3, 2, 0, 4}},
+			min:     1, median: 3, max: 5,
+			expected: map[int]float64{1: 0.1, 2: 0.3, 3: 0.2, 4: 0, 5: 0.4},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			d, err := NewWordLengthDistribution(tc.options)
+			if err != nil {
+				t.Fatal(err)
+			}
+			lengths, freqs := sampleLengths(d)
+
+			if lengths[0] < tc.min {
+				t.Errorf("drew a word of %d syllables, below the min of %d", lengths[0], tc.min)
+			}
+			if last := lengths[len(lengths)-1]; last > tc.max {
+				t.Errorf("drew a word of %d syllables, above the max of %d", last, tc.max)
+			}
+			if median := lengths[len(lengths)/2]; median != tc.median {
+				t.Errorf("median of %d syllables, want %d", median, tc.median)
+			}
+			if tc.mean > 0 {
+				sum := 0
+				for _, l := range lengths {
+					sum += l
+				}
+				if mean := float64(sum) / lengthSamples; math.Abs(mean-tc.mean) > 0.05 {
+					t.Errorf("mean of %.3f syllables, want %.3f", mean, tc.mean)
+				}
+			}
+
+			// every length is drawn about as often as the distribution says, and lengths the
+			// test expects a frequency for as often as that
+			for length, p := range d.PMF {
+				if math.Abs(freqs[length]-p) > frequencyTolerance {
+					t.Errorf("%d syllables drawn with frequency %.4f, want %.4f", length, freqs[length], p)
+				}
+			}
+			for length, p := range tc.expected {
+				if math.Abs(freqs[length]-p) > frequencyTolerance {
+					t.Errorf("%d syllables drawn with frequency %.4f, want %.4f", length, freqs[length], p)
+				}
+			}
+		})
+	}
+}
+
+func TestInvalidWordLengthHistograms(t *testing.T) {
+	for _, weights := range [][]float64{{}, {0, 0}, {1, -1}, {math.NaN()}, {math.Inf(1)}} {
+		if _, err := HistogramWordLengths(weights); err == nil {
+			t.Errorf("histogram %v should be rejected", weights)
+		}
+	}
+}
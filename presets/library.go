@@ -0,0 +1,260 @@
+package presets
+
+import "github.com/jheredos/langgen/phonotactics"
+
+// library is every preset, in the order they are listed. Each is a simplified phonology in
+// the style of a natural language, not a faithful description of it
+var library = []Preset{hawaiian, japanese, georgian, arabic, mandarin, spanish, finnish}
+
+var hawaiian = Preset{
+	ID:          "hawaiian",
+	Name:        "Hawaiian",
+	Description: "A tiny consonant inventory, long vowels and diphthongs, and strictly open syllables",
+
+	Consonants: []string{"p", "k", "ʔ", "h", "m", "n", "w", "l"},
+	Vowels:     []string{"a", "e", "i", "o", "u", "aː", "eː", "iː", "oː", "uː"},
+	Diphthongs: []string{"ai̯", "ae̯", "ao̯", "au̯", "ei̯", "eu̯", "oi̯", "ou̯", "iu̯"},
+
+	Onsets: Tiers{NoCluster: []string{"p", "k", "ʔ", "h", "m", "n", "w", "l"}},
+	Nucleus: Nucleus{
+		Monophthongs: []string{"a", "e", "i", "o", "u", "aː", "eː", "iː", "oː", "uː"},
+		Diphthongs:   []string{"ai̯", "ae̯", "ao̯", "au̯", "ei̯", "eu̯", "oi̯", "ou̯", "iu̯"},
+	},
+
+	Options: phonotactics.PhonotacticOptions{
+		MinWordLength:    phonotactics.MonosyllabicWL,
+		MedianWordLength: phonotactics.MediumWL,
+		MaxWordLength:    phonotactics.LongWL,
+		StressType:       phonotactics.PenultimateST,
+		StressPosition:   phonotactics.WordSP,
+		ToneType:         phonotactics.NoneTT,
+	},
+	Rules: phonotactics.PhonotacticRules{
+		InitialNullOnset: phonotactics.SometimesRF,
+		Hiatus:           phonotactics.SeldomRF,
+	},
+}
+
+var japanese = Preset{
+	ID:          "japanese",
+	Name:        "Japanese",
+	Description: "Five vowels with length, palatalized onsets, and a moraic nasal as the only coda",
+
+	Consonants: []string{"p", "b", "t", "d", "k", "g", "t͡s", "t͡ɕ", "d͡ʑ", "s", "z", "ɕ", "h", "m", "n", "ɾ", "j", "w"},
+	Vowels:     []string{"a", "i", "ɯ", "e", "o", "aː", "iː", "ɯː", "eː", "oː"},
+
+	Onsets: Tiers{
+		NoCluster: []string{"t͡s", "t͡ɕ", "d͡ʑ", "s", "z", "ɕ", "w"},
+		Tiers: [][]string{
+			{"p", "b", "t", "d", "k", "g", "h", "m", "n", "ɾ"},
+			{"j"},
+		},
+	},
+	Nucleus: Nucleus{
+		Monophthongs: []string{"a", "i", "ɯ", "e", "o", "aː", "iː", "ɯː", "eː", "oː"},
+	},
+	Codas: Tiers{NoCluster: []string{"n"}},
+
+	Options: phonotactics.PhonotacticOptions{
+		MinWordLength:    phonotactics.MonosyllabicWL,
+		MedianWordLength: phonotactics.MediumWL,
+		MaxWordLength:    phonotactics.LongWL,
+		StressType:       phonotactics.NoneST,
+		ToneType:         phonotactics.NoneTT,
+		MoraicCodas:      true,
+	},
+	Rules: phonotactics.PhonotacticRules{
+		InitialNullOnset: phonotactics.SometimesRF,
+		FinalNullCoda:    phonotactics.VeryOftenRF,
+		Hiatus:           phonotactics.SometimesRF,
+	},
+}
+
+var georgian = Preset{
+	ID:          "georgian",
+	Name:        "Georgian",
+	Description: "Three-way voiced, aspirated and ejective obstruents, with long onset clusters ordered front to back",
+
+	Consonants: []string{"b", "pʰ", "pʼ", "d", "tʰ", "tʼ", "g", "kʰ", "kʼ", "qʼ", "d͡z", "t͡sʰ", "t͡sʼ", "d͡ʒ", "t͡ʃʰ", "t͡ʃʼ",
+		"v", "s", "z", "ʃ", "ʒ", "x", "ɣ", "h", "m", "n", "r", "l"},
+	Vowels: []string{"a", "e", "i", "o", "u"},
+
+	Onsets: Tiers{
+		NoCluster: []string{"h", "n"},
+		Tiers: [][]string{
+			{"b", "pʰ", "pʼ", "d", "tʰ", "tʼ", "d͡z", "t͡sʰ", "t͡sʼ", "d͡ʒ", "t͡ʃʰ", "t͡ʃʼ", "s", "z", "ʃ", "ʒ", "m"},
+			{"g", "kʰ", "kʼ", "qʼ", "x", "ɣ"},
+			{"v", "r", "l"},
+		},
+	},
+	Nucleus: Nucleus{
+		Monophthongs: []string{"a", "e", "i", "o", "u"},
+	},
+	Codas: Tiers{NoCluster: []string{"m", "n", "r", "l", "s"}},
+
+	Options: phonotactics.PhonotacticOptions{
+		MinWordLength:    phonotactics.MonosyllabicWL,
+		MedianWordLength: phonotactics.MediumWL,
+		MaxWordLength:    phonotactics.LongWL,
+		StressType:       phonotactics.InitialST,
+		StressPosition:   phonotactics.WordSP,
+		ToneType:         phonotactics.NoneTT,
+	},
+	Rules: phonotactics.PhonotacticRules{
+		InitialNullOnset: phonotactics.SometimesRF,
+		FinalNullCoda:    phonotactics.VeryOftenRF,
+		Hiatus:           phonotactics.SeldomRF,
+	},
+}
+
+var arabic = Preset{
+	ID:          "arabic",
+	Name:        "Arabic",
+	Description: "Emphatic and pharyngeal consonants, three vowels with length, no onset clusters, and geminates",
+
+	Consonants: []string{"b", "t", "d", "k", "q", "ʔ", "f", "θ", "ð", "s", "z", "ʃ", "x", "ɣ", "ħ", "ʕ", "h",
+		"tˤ", "dˤ", "sˤ", "ðˤ", "d͡ʒ", "m", "n", "l", "r", "j", "w"},
+	Vowels:     []string{"a", "i", "u", "aː", "iː", "uː"},
+	Diphthongs: []string{"ai̯", "au̯"},
+
+	Onsets: Tiers{NoCluster: []string{"b", "t", "d", "k", "q", "ʔ", "f", "θ", "ð", "s", "z", "ʃ", "x", "ɣ", "ħ", "ʕ", "h",
+		"tˤ", "dˤ", "sˤ", "ðˤ", "d͡ʒ", "m", "n", "l", "r", "j", "w"}},
+	Nucleus: Nucleus{
+		Monophthongs: []string{"a", "i", "u", "aː", "iː", "uː"},
+		Diphthongs:   []string{"ai̯", "au̯"},
+	},
+	Codas: Tiers{
+		Tiers: [][]string{
+			{"m", "n", "l", "r"},
+			{"b", "t", "d", "k", "q", "ʔ", "f", "θ", "ð", "s", "z", "ʃ", "x", "ɣ", "ħ", "ʕ", "h", "tˤ", "dˤ", "sˤ", "ðˤ", "d͡ʒ"},
+		},
+	},
+
+	Options: phonotactics.PhonotacticOptions{
+		MinWordLength:    phonotactics.MonosyllabicWL,
+		MedianWordLength: phonotactics.ShortWL,
+		MaxWordLength:    phonotactics.LongWL,
+		StressType:       phonotactics.HeaviestOfLastThreeST,
+		StressPosition:   phonotactics.WordSP,
+		ToneType:         phonotactics.NoneTT,
+		MoraicCodas:      true,
+	},
+	Rules: phonotactics.PhonotacticRules{
+		InitialNullOnset: phonotactics.NeverRF,
+		FinalNullCoda:    phonotactics.SometimesRF,
+		Hiatus:           phonotactics.NeverRF,
+		Gemination:       phonotactics.OftenRF,
+	},
+}
+
+var mandarin = Preset{
+	ID:          "mandarin",
+	Name:        "Mandarin",
+	Description: "Aspirated and retroflex affricates, glides before and after the nucleus, two nasal codas, and contour tones",
+
+	Consonants: []string{"p", "pʰ", "t", "tʰ", "k", "kʰ", "t͡s", "t͡sʰ", "ʈ͡ʂ", "ʈ͡ʂʰ", "t͡ɕ", "t͡ɕʰ",
+		"f", "s", "ʂ", "ɕ", "x", "ʐ", "m", "n", "ŋ", "l"},
+	Vowels: []string{"a", "o", "e", "ə", "ɤ", "i", "u", "y"},
+
+	Onsets: Tiers{NoCluster: []string{"p", "pʰ", "t", "tʰ", "k", "kʰ", "t͡s", "t͡sʰ", "ʈ͡ʂ", "ʈ͡ʂʰ", "t͡ɕ", "t͡ɕʰ",
+		"f", "s", "ʂ", "ɕ", "x", "ʐ", "m", "n", "l"}},
+	Nucleus: Nucleus{
+		Onglides:     []string{"i", "u", "y"},
+		Nuclei:       []string{"a", "o", "e", "ə"},
+		Offglides:    []string{"i", "u"},
+		Monophthongs: []string{"a", "o", "ɤ", "i", "u", "y"},
+	},
+	Codas: Tiers{NoCluster: []string{"n", "ŋ"}},
+
+	Options: phonotactics.PhonotacticOptions{
+		MinWordLength:    phonotactics.MonosyllabicWL,
+		MedianWordLength: phonotactics.ShortWL,
+		MaxWordLength:    phonotactics.MediumWL,
+		StressType:       phonotactics.NoneST,
+		ToneType:         phonotactics.ContourTT,
+		TonePosition:     phonotactics.SyllableTP,
+		ToneCategories:   []phonotactics.ToneCategory{55, 35, 214, 51},
+	},
+	Rules: phonotactics.PhonotacticRules{
+		InitialNullOnset: phonotactics.SeldomRF,
+		FinalNullCoda:    phonotactics.OftenRF,
+		Hiatus:           phonotactics.NeverRF,
+	},
+}
+
+var spanish = Preset{
+	ID:          "spanish",
+	Name:        "Spanish",
+	Description: "Five vowels, rising and falling diphthongs, stop and liquid onset clusters, and a tap and trill contrast",
+
+	Consonants: []string{"p", "b", "t", "d", "k", "g", "f", "θ", "s", "x", "t͡ʃ", "ʝ", "m", "n", "ɲ", "l", "ɾ", "r"},
+	Vowels:     []string{"a", "e", "i", "o", "u"},
+	Diphthongs: []string{"ai̯", "ei̯", "oi̯", "au̯", "eu̯", "i̯a", "i̯e", "i̯o", "u̯a", "u̯e", "u̯o"},
+
+	Onsets: Tiers{
+		NoCluster: []string{"θ", "s", "x", "t͡ʃ", "ʝ", "m", "n", "ɲ", "r"},
+		Tiers: [][]string{
+			{"p", "b", "t", "d", "k", "g", "f"},
+			{"l", "ɾ"},
+		},
+	},
+	Nucleus: Nucleus{
+		Monophthongs: []string{"a", "e", "i", "o", "u"},
+		Diphthongs:   []string{"ai̯", "ei̯", "oi̯", "au̯", "eu̯", "i̯a", "i̯e", "i̯o", "u̯a", "u̯e", "u̯o"},
+	},
+	Codas: Tiers{
+		NoCluster: []string{"d", "θ"},
+		Tiers: [][]string{
+			{"n", "l", "ɾ"},
+			{"s"},
+		},
+	},
+
+	Options: phonotactics.PhonotacticOptions{
+		MinWordLength:    phonotactics.MonosyllabicWL,
+		MedianWordLength: phonotactics.MediumWL,
+		MaxWordLength:    phonotactics.LongWL,
+		StressType:       phonotactics.PenultimateST,
+		StressPosition:   phonotactics.WordSP,
+		ToneType:         phonotactics.NoneTT,
+	},
+	Rules: phonotactics.PhonotacticRules{
+		InitialNullOnset: phonotactics.SometimesRF,
+		FinalNullCoda:    phonotactics.OftenRF,
+		Hiatus:           phonotactics.SeldomRF,
+	},
+}
+
+var finnish = Preset{
+	ID:          "finnish",
+	Name:        "Finnish",
+	Description: "Eight vowels with length and many diphthongs, simple onsets, geminates, and initial stress",
+
+	Consonants: []string{"p", "t", "k", "d", "s", "h", "m", "n", "ŋ", "l", "r", "j", "ʋ"},
+	Vowels:     []string{"a", "e", "i", "o", "u", "y", "æ", "ø", "aː", "eː", "iː", "oː", "uː", "yː", "æː", "øː"},
+	Diphthongs: []string{"ai̯", "ei̯", "oi̯", "ui̯", "yi̯", "æi̯", "øi̯", "au̯", "eu̯", "iu̯", "ou̯", "æy̯", "øy̯", "ie̯", "uo̯", "yø̯"},
+
+	Onsets: Tiers{NoCluster: []string{"p", "t", "k", "d", "s", "h", "m", "n", "l", "r", "j", "ʋ"}},
+	Nucleus: Nucleus{
+		Monophthongs: []string{"a", "e", "i", "o", "u", "y", "æ", "ø", "aː", "eː", "iː", "oː", "uː", "yː", "æː", "øː"},
+		Diphthongs:   []string{"ai̯", "ei̯", "oi̯", "ui̯", "yi̯", "æi̯", "øi̯", "au̯", "eu̯", "iu̯", "ou̯", "æy̯", "øy̯", "ie̯", "uo̯", "yø̯"},
+	},
+	Codas: Tiers{NoCluster: []string{"p", "t", "k", "s", "h", "m", "n", "ŋ", "l", "r"}},
+
+	Options: phonotactics.PhonotacticOptions{
+		MinWordLength:    phonotactics.MonosyllabicWL,
+		MedianWordLength: phonotactics.MediumWL,
+		MaxWordLength:    phonotactics.XLongWL,
+		StressType:       phonotactics.InitialST,
+		StressPosition:   phonotactics.WordSP,
+		ToneType:         phonotactics.NoneTT,
+		MoraicCodas:      true,
+	},
+	Rules: phonotactics.PhonotacticRules{
+		InitialNullOnset:       phonotactics.SometimesRF,
+		FinalNullCoda:          phonotactics.VeryOftenRF,
+		Hiatus:                 phonotactics.SeldomRF,
+		Gemination:             phonotactics.OftenRF,
+		NasalPlaceAssimilation: phonotactics.AlwaysRF,
+	},
+}
@@ -0,0 +1,289 @@
+// Package presets is a library of starter languages in the style of natural languages, each
+// with a complete inventory, hierarchies, and rules, so that new users need not start from an
+// empty inventory. Presets are written in IPA and built into languages when instantiated
+package presets
+
+import (
+	"fmt"
+
+	"github.com/jheredos/langgen/phonology"
+	"github.com/jheredos/langgen/phonotactics"
+	"github.com/jheredos/langgen/storage"
+)
+
+// Preset is a starter language. Its hierarchies may only place phonemes of its inventory,
+// written the same way, and must place every one of them
+type Preset struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+
+	Consonants []string `json:"consonants"`
+	Vowels     []string `json:"vowels"`
+	Diphthongs []string `json:"diphthongs"`
+
+	Onsets  Tiers   `json:"onsets"`
+	Nucleus Nucleus `json:"nucleus"`
+	Codas   Tiers   `json:"codas"`
+
+	Options phonotactics.PhonotacticOptions `json:"options"`
+	Rules   phonotactics.PhonotacticRules   `json:"rules"`
+}
+
+// Tiers is a ConsonantHierarchy written in IPA
+type Tiers struct {
+	NoCluster []string   `json:"noCluster"`
+	Tiers     [][]string `json:"tiers"`
+}
+
+// Nucleus is a NucleusHierarchy written in IPA
+type Nucleus struct {
+	Onglides     []string `json:"onglides"`
+	Nuclei       []string `json:"nuclei"`
+	Offglides    []string `json:"offglides"`
+	Monophthongs []string `json:"monophthongs"`
+	Consonants   []string `json:"consonants"`
+	Diphthongs   []string `json:"diphthongs"`
+}
+
+// All returns every preset in the library
+func All() []Preset {
+	return library
+}
+
+// Find returns the preset with the id
+func Find(id string) (Preset, bool) {
+	for _, p := range library {
+		if p.ID == id {
+			return p, true
+		}
+	}
+	return Preset{}, false
+}
+
+// inventory is a preset's inventory, parsed and looked up by the IPA it was written with
+type inventory struct {
+	consonants map[string]phonology.Consonant
+	vowels     map[string]phonology.Vowel
+	diphthongs map[string]phonology.Diphthong
+}
+
+// Language builds the preset into a language with the id
+func (p Preset) Language(id string) (storage.Language, error) {
+	lang := storage.Language{ID: id, Options: p.Options, Rules: p.Rules}
+	inv := inventory{map[string]phonology.Consonant{}, map[string]phonology.Vowel{}, map[string]phonology.Diphthong{}}
+
+	for _, s := range p.Consonants {
+		c, err := phonology.NewConsonantFromIPA(s)
+		if err != nil {
+			return lang, fmt.Errorf("preset %s: %v", p.ID, err)
+		}
+		inv.consonants[s] = c
+		lang.Consonants = append(lang.Consonants, c)
+	}
+	for _, s := range p.Vowels {
+		v, err := phonology.NewVowelFromIPA(s)
+		if err != nil {
+			return lang, fmt.Errorf("preset %s: %v", p.ID, err)
+		}
+		inv.vowels[s] = v
+		lang.Vowels = append(lang.Vowels, v)
+	}
+	for _, s := range p.Diphthongs {
+		d, err := phonology.NewDiphthongFromIPA(s)
+		if err != nil {
+			return lang, fmt.Errorf("preset %s: %v", p.ID, err)
+		}
+		inv.diphthongs[s] = d
+		lang.Diphthongs = append(lang.Diphthongs, d)
+	}
+
+	var err error
+	lang.Onsets, err = inv.consonantHierarchy(p.Onsets, true)
+	if err == nil {
+		lang.Codas, err = inv.consonantHierarchy(p.Codas, false)
+	}
+	if err == nil {
+		lang.Nuclei, err = inv.nucleusHierarchy(p.Nucleus)
+	}
+	if err != nil {
+		return lang, fmt.Errorf("preset %s: %v", p.ID, err)
+	}
+
+	if report := lang.Consistency(); !report.Consistent {
+		return lang, fmt.Errorf("preset %s: %v", p.ID, report)
+	}
+	return lang, nil
+}
+
+func (inv inventory) consonantList(ss []string) ([]phonology.Consonant, error) {
+	cs := []phonology.Consonant{}
+	for _, s := range ss {
+		c, found := inv.consonants[s]
+		if !found {
+			return nil, fmt.Errorf("/%s/ is not a consonant of the inventory", s)
+		}
+		cs = append(cs, c)
+	}
+	return cs, nil
+}
+
+func (inv inventory) vowelList(ss []string) ([]phonology.Vowel, error) {
+	vs := []phonology.Vowel{}
+	for _, s := range ss {
+		v, found := inv.vowels[s]
+		if !found {
+			return nil, fmt.Errorf("/%s/ is not a vowel of the inventory", s)
+		}
+		vs = append(vs, v)
+	}
+	return vs, nil
+}
+
+func (inv inventory) consonantHierarchy(t Tiers, onset bool) (phonotactics.ConsonantHierarchy, error) {
+	h := phonotactics.ConsonantHierarchy{Onset: onset, Tiers: [][]phonology.Consonant{}}
+	var err error
+	h.NoCluster, err = inv.consonantList(t.NoCluster)
+	if err != nil {
+		return h, err
+	}
+	for _, tier := range t.Tiers {
+		cs, err := inv.consonantList(tier)
+		if err != nil {
+			return h, err
+		}
+		h.Tiers = append(h.Tiers, cs)
+	}
+	return h, nil
+}
+
+func (inv inventory) nucleusHierarchy(n Nucleus) (phonotactics.NucleusHierarchy, error) {
+	h := phonotactics.NucleusHierarchy{Diphthongs: []phonology.Diphthong{}}
+	var err error
+	for _, list := range []struct {
+		ipa []string
+		set *[]phonology.Vowel
+	}{
+		{n.Onglides, &h.Onglides},
+		{n.Nuclei, &h.Nuclei},
+		{n.Offglides, &h.Offglides},
+		{n.Monophthongs, &h.Monophthongs},
+	} {
+		*list.set, err = inv.vowelList(list.ipa)
+		if err != nil {
+			return h, err
+		}
+	}
+	h.Consonants, err = inv.consonantList(n.Consonants)
+	if err != nil {
+		return h, err
+	}
+	for _, s := range n.Diphthongs {
+		d, found := inv.diphthongs[s]
+		if !found {
+			return h, fmt.Errorf("/%s/ is not a diphthong of the inventory", s)
+		}
+		h.Diphthongs = append(h.Diphthongs, d)
+	}
+	return h, nil
+}
+
+// Blend combines two presets into one with the phonemes of both. Each phoneme keeps its
+// place in the first preset's hierarchies if it has one there, and otherwise takes its place
+// in the second's. Rule frequencies meet halfway, leaning toward the first preset's, OCP and
+// sequence constraints are pooled, and the options are the first preset's
+func Blend(a, b Preset) Preset {
+	return Preset{
+		ID:          a.ID + "+" + b.ID,
+		Name:        a.Name + " × " + b.Name,
+		Description: fmt.Sprintf("A blend of the %s and %s presets", a.Name, b.Name),
+
+		Consonants: union(a.Consonants, b.Consonants),
+		Vowels:     union(a.Vowels, b.Vowels),
+		Diphthongs: union(a.Diphthongs, b.Diphthongs),
+
+		Onsets: blendTiers(a.Onsets, b.Onsets),
+		Nucleus: Nucleus{
+			Onglides:     union(a.Nucleus.Onglides, b.Nucleus.Onglides),
+			Nuclei:       union(a.Nucleus.Nuclei, b.Nucleus.Nuclei),
+			Offglides:    union(a.Nucleus.Offglides, b.Nucleus.Offglides),
+			Monophthongs: union(a.Nucleus.Monophthongs, b.Nucleus.Monophthongs),
+			Consonants:   union(a.Nucleus.Consonants, b.Nucleus.Consonants),
+			Diphthongs:   union(a.Nucleus.Diphthongs, b.Nucleus.Diphthongs),
+		},
+		Codas: blendTiers(a.Codas, b.Codas),
+
+		Options: a.Options,
+		Rules: phonotactics.PhonotacticRules{
+			InitialNullOnset:       blendFrequency(a.Rules.InitialNullOnset, b.Rules.InitialNullOnset),
+			FinalNullCoda:          blendFrequency(a.Rules.FinalNullCoda, b.Rules.FinalNullCoda),
+			Hiatus:                 blendFrequency(a.Rules.Hiatus, b.Rules.Hiatus),
+			Gemination:             blendFrequency(a.Rules.Gemination, b.Rules.Gemination),
+			NasalPlaceAssimilation: blendFrequency(a.Rules.NasalPlaceAssimilation, b.Rules.NasalPlaceAssimilation),
+			OCP:                    append(append([]phonotactics.OCPConstraint{}, a.Rules.OCP...), b.Rules.OCP...),
+			Sequences:              append(append([]phonotactics.SequenceConstraint{}, a.Rules.Sequences...), b.Rules.Sequences...),
+		},
+	}
+}
+
+// union returns the phonemes of a followed by those of b not in a
+func union(a, b []string) []string {
+	res := []string{}
+	seen := map[string]bool{}
+	for _, list := range [][]string{a, b} {
+		for _, s := range list {
+			if !seen[s] {
+				res = append(res, s)
+				seen[s] = true
+			}
+		}
+	}
+	return res
+}
+
+// blendTiers merges two consonant hierarchies tier by tier, leaving out the second's
+// phonemes the first already places
+func blendTiers(a, b Tiers) Tiers {
+	placed := map[string]bool{}
+	for _, s := range a.NoCluster {
+		placed[s] = true
+	}
+	for _, tier := range a.Tiers {
+		for _, s := range tier {
+			placed[s] = true
+		}
+	}
+	unplaced := func(ss []string) []string {
+		res := []string{}
+		for _, s := range ss {
+			if !placed[s] {
+				res = append(res, s)
+			}
+		}
+		return res
+	}
+
+	res := Tiers{NoCluster: union(a.NoCluster, unplaced(b.NoCluster)), Tiers: [][]string{}}
+	for i := 0; i < len(a.Tiers) || i < len(b.Tiers); i++ {
+		var tierA, tierB []string
+		if i < len(a.Tiers) {
+			tierA = a.Tiers[i]
+		}
+		if i < len(b.Tiers) {
+			tierB = unplaced(b.Tiers[i])
+		}
+		res.Tiers = append(res.Tiers, union(tierA, tierB))
+	}
+	return res
+}
+
+// blendFrequency returns the frequency halfway between two, rounded toward the first
+func blendFrequency(a, b phonotactics.RuleFrequency) phonotactics.RuleFrequency {
+	switch {
+	case a == phonotactics.UnspecifiedRF:
+		return b
+	case b == phonotactics.UnspecifiedRF:
+		return a
+	}
+	return phonotactics.RuleFrequency(int(a) + (int(b)-int(a))/2)
+}
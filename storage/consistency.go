@@ -0,0 +1,220 @@
+package storage
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jheredos/langgen/phonology"
+	"github.com/jheredos/langgen/phonotactics"
+)
+
+// ConsistencyReport compares a language's inventory with its hierarchies. Phonemes are
+// compared by their IPA. NeedsPlacement lists the phonemes of the inventory that are in
+// no hierarchy, like those just added to it, which words cannot use until they are placed.
+// Stale lists the phonemes of the hierarchies that are no longer in the inventory
+type ConsistencyReport struct {
+	Consistent     bool     `json:"consistent"`
+	NeedsPlacement []string `json:"needsPlacement"`
+	Stale          []string `json:"stale"`
+}
+
+// Error describes an inconsistent language's problems, for refusing to use it
+func (r ConsistencyReport) Error() string {
+	problems := []string{}
+	if len(r.NeedsPlacement) > 0 {
+		problems = append(problems, "phonemes in no hierarchy: /"+strings.Join(r.NeedsPlacement, "/, /")+"/")
+	}
+	if len(r.Stale) > 0 {
+		problems = append(problems, "phonemes in the hierarchies but not the inventory: /"+strings.Join(r.Stale, "/, /")+"/")
+	}
+	return fmt.Sprintf("inventory and hierarchies are inconsistent: %s", strings.Join(problems, "; "))
+}
+
+// CheckConsistency compares an inventory with the hierarchies built from it
+func CheckConsistency(inv phonology.Inventory, h Hierarchies) ConsistencyReport {
+	inInventory := map[string]bool{}
+	for _, p := range inv.Phonemes() {
+		inInventory[p.ToIPA()] = true
+	}
+	placed := map[string]bool{}
+	for _, p := range hierarchyPhonemes(h) {
+		placed[p.ToIPA()] = true
+	}
+
+	r := ConsistencyReport{NeedsPlacement: []string{}, Stale: []string{}}
+	for _, p := range inv.Phonemes() {
+		if ipa := p.ToIPA(); !placed[ipa] {
+			r.NeedsPlacement = append(r.NeedsPlacement, ipa)
+			placed[ipa] = true // listed once
+		}
+	}
+	for _, p := range hierarchyPhonemes(h) {
+		if ipa := p.ToIPA(); !inInventory[ipa] {
+			r.Stale = append(r.Stale, ipa)
+			inInventory[ipa] = true // listed once
+		}
+	}
+	r.Consistent = len(r.NeedsPlacement) == 0 && len(r.Stale) == 0
+	return r
+}
+
+// Consistency compares the language's inventory with its hierarchies
+func (l Language) Consistency() ConsistencyReport {
+	return CheckConsistency(l.Inventory(), Hierarchies{Onset: l.Onsets, Nucleus: l.Nuclei, Coda: l.Codas})
+}
+
+// Inventory returns the language's consonants, vowels, diphthongs, and custom segments
+func (l Language) Inventory() phonology.Inventory {
+	return phonology.Inventory{LanguageID: l.ID, Consonants: l.Consonants, Vowels: l.Vowels, Diphthongs: l.Diphthongs, Segments: l.Segments}
+}
+
+// SetConsonants replaces the language's consonants, removing any that are no longer in
+// the inventory from its hierarchies. New consonants are left for the user to place
+func (l *Language) SetConsonants(cs []phonology.Consonant) {
+	l.Consonants = cs
+	l.PruneHierarchies()
+}
+
+// SetVowels replaces the language's vowels, removing any that are no longer in the
+// inventory from its hierarchies. New vowels are left for the user to place
+func (l *Language) SetVowels(vs []phonology.Vowel) {
+	l.Vowels = vs
+	l.PruneHierarchies()
+}
+
+// SetDiphthongs replaces the language's diphthongs, removing any that are no longer in
+// the inventory from its hierarchies. New diphthongs are left for the user to place
+func (l *Language) SetDiphthongs(ds []phonology.Diphthong) {
+	l.Diphthongs = ds
+	l.PruneHierarchies()
+}
+
+// SetSegments replaces the language's custom segments, removing any that are no longer
+// in the inventory from its hierarchies. New segments are left for the user to place
+func (l *Language) SetSegments(ss []phonology.CustomSegment) {
+	l.Segments = ss
+	l.PruneHierarchies()
+}
+
+// PruneHierarchies removes every phoneme that is not in the language's inventory from its
+// hierarchies. Emptied tiers are kept, so that the ranks of the other tiers don't change
+func (l *Language) PruneHierarchies() {
+	inv := l.Inventory()
+	keep := map[string]bool{}
+	for _, p := range inv.Phonemes() {
+		keep[p.ToIPA()] = true
+	}
+	l.Onsets = pruneConsonantHierarchy(l.Onsets, keep)
+	l.Codas = pruneConsonantHierarchy(l.Codas, keep)
+
+	n := l.Nuclei
+	l.Nuclei = phonotactics.NucleusHierarchy{
+		Onglides:     pruneVowels(n.Onglides, keep),
+		Nuclei:       pruneVowels(n.Nuclei, keep),
+		Offglides:    pruneVowels(n.Offglides, keep),
+		Monophthongs: pruneVowels(n.Monophthongs, keep),
+		Consonants:   pruneConsonants(n.Consonants, keep),
+		Diphthongs:   pruneDiphthongs(n.Diphthongs, keep),
+		Custom:       pruneSegments(n.Custom, keep),
+	}
+}
+
+func pruneConsonantHierarchy(h phonotactics.ConsonantHierarchy, keep map[string]bool) phonotactics.ConsonantHierarchy {
+	pruned := phonotactics.ConsonantHierarchy{Onset: h.Onset, NoCluster: pruneConsonants(h.NoCluster, keep)}
+	for _, tier := range h.Tiers {
+		pruned.Tiers = append(pruned.Tiers, pruneConsonants(tier, keep))
+	}
+	for _, tier := range h.CustomTiers {
+		pruned.CustomTiers = append(pruned.CustomTiers, pruneSegments(tier, keep))
+	}
+	return pruned
+}
+
+func pruneConsonants(cs []phonology.Consonant, keep map[string]bool) []phonology.Consonant {
+	if cs == nil {
+		return nil
+	}
+	res := []phonology.Consonant{}
+	for _, c := range cs {
+		if keep[c.ToIPA()] {
+			res = append(res, c)
+		}
+	}
+	return res
+}
+
+func pruneVowels(vs []phonology.Vowel, keep map[string]bool) []phonology.Vowel {
+	if vs == nil {
+		return nil
+	}
+	res := []phonology.Vowel{}
+	for _, v := range vs {
+		if keep[v.ToIPA()] {
+			res = append(res, v)
+		}
+	}
+	return res
+}
+
+func pruneDiphthongs(ds []phonology.Diphthong, keep map[string]bool) []phonology.Diphthong {
+	if ds == nil {
+		return nil
+	}
+	res := []phonology.Diphthong{}
+	for _, d := range ds {
+		if keep[d.ToIPA()] {
+			res = append(res, d)
+		}
+	}
+	return res
+}
+
+func pruneSegments(ss []phonology.CustomSegment, keep map[string]bool) []phonology.CustomSegment {
+	if ss == nil {
+		return nil
+	}
+	res := []phonology.CustomSegment{}
+	for _, s := range ss {
+		if keep[s.ToIPA()] {
+			res = append(res, s)
+		}
+	}
+	return res
+}
+
+// hierarchyPhonemes returns every phoneme placed in the hierarchies
+func hierarchyPhonemes(h Hierarchies) []phonology.Phoneme {
+	ps := []phonology.Phoneme{}
+	for _, ch := range []phonotactics.ConsonantHierarchy{h.Onset, h.Coda} {
+		for _, c := range ch.NoCluster {
+			ps = append(ps, c)
+		}
+		for _, tier := range ch.Tiers {
+			for _, c := range tier {
+				ps = append(ps, c)
+			}
+		}
+		for _, tier := range ch.CustomTiers {
+			for _, s := range tier {
+				ps = append(ps, s)
+			}
+		}
+	}
+
+	n := h.Nucleus
+	for _, vs := range [][]phonology.Vowel{n.Onglides, n.Nuclei, n.Offglides, n.Monophthongs} {
+		for _, v := range vs {
+			ps = append(ps, v)
+		}
+	}
+	for _, c := range n.Consonants {
+		ps = append(ps, c)
+	}
+	for _, d := range n.Diphthongs {
+		ps = append(ps, d)
+	}
+	for _, s := range n.Custom {
+		ps = append(ps, s)
+	}
+	return ps
+}
@@ -0,0 +1,149 @@
+package storage
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jheredos/langgen/phonology"
+	"github.com/jheredos/langgen/phonotactics"
+)
+
+// ipaOf writes each phoneme as IPA, for comparing hierarchies by what they hold
+func ipaOf(ps ...phonology.Phoneme) []string {
+	res := []string{}
+	for _, p := range ps {
+		res = append(res, p.ToIPA())
+	}
+	return res
+}
+
+func consonantIPAs(cs []phonology.Consonant) []string {
+	ps := []phonology.Phoneme{}
+	for _, c := range cs {
+		ps = append(ps, c)
+	}
+	return ipaOf(ps...)
+}
+
+func TestCheckConsistency(t *testing.T) {
+	k, err := phonology.NewConsonantFromIPA("k")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		name                  string
+		change                func(*Language)
+		needsPlacement, stale []string
+	}{
+		{"consistent", func(l *Language) {}, nil, nil},
+		{"new phoneme", func(l *Language) { l.Consonants = append(l.Consonants, k) }, []string{"k"}, nil},
+		{"dropped phoneme", func(l *Language) { l.Vowels = l.Vowels[:1] }, nil, []string{"i"}},
+		// a phoneme is placed if it is in any hierarchy, and listed once however often it is found
+		{"placed in one hierarchy", func(l *Language) { l.Codas = phonotactics.ConsonantHierarchy{} }, nil, nil},
+		{"stale twice", func(l *Language) {
+			l.Consonants = []phonology.Consonant{l.Consonants[0], l.Consonants[1]}
+			l.Onsets.Tiers = [][]phonology.Consonant{{l.Onsets.NoCluster[2]}}
+		}, nil, []string{"s"}},
+	} {
+		l := testLanguage(t, "lang")
+		tc.change(&l)
+		r := l.Consistency()
+		if r.Consistent != (len(tc.needsPlacement) == 0 && len(tc.stale) == 0) {
+			t.Errorf("%s: reported consistent %t", tc.name, r.Consistent)
+		}
+		if tc.needsPlacement == nil {
+			tc.needsPlacement = []string{}
+		}
+		if tc.stale == nil {
+			tc.stale = []string{}
+		}
+		if !reflect.DeepEqual(r.NeedsPlacement, tc.needsPlacement) || !reflect.DeepEqual(r.Stale, tc.stale) {
+			t.Errorf("%s: got %+v, want needing placement %v and stale %v", tc.name, r, tc.needsPlacement, tc.stale)
+		}
+	}
+
+	r := ConsistencyReport{NeedsPlacement: []string{"k"}, Stale: []string{"s", "i"}}
+	want := "inventory and hierarchies are inconsistent: phonemes in no hierarchy: /k/; phonemes in the hierarchies but not the inventory: /s/, /i/"
+	if r.Error() != want {
+		t.Errorf("got %q, want %q", r.Error(), want)
+	}
+}
+
+func TestPruneHierarchies(t *testing.T) {
+	cs := map[string]phonology.Consonant{}
+	for _, ipa := range []string{"p", "t", "k", "s"} {
+		c, err := phonology.NewConsonantFromIPA(ipa)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cs[ipa] = c
+	}
+	vs := map[string]phonology.Vowel{}
+	for _, ipa := range []string{"a", "i", "u"} {
+		v, err := phonology.NewVowelFromIPA(ipa)
+		if err != nil {
+			t.Fatal(err)
+		}
+		vs[ipa] = v
+	}
+	ai, err := phonology.NewDiphthongFromIPA("ai̯")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ch := phonology.CustomSegment{Symbol: "ch", Consonant: cs["k"]}
+
+	// a /p/ with a feature the inventory's lacks is a different phoneme, by its IPA
+	pʰ := cs["p"]
+	pʰ.Aspirated = phonology.AspiratedCA
+
+	lang := Language{
+		Consonants: []phonology.Consonant{cs["p"], cs["t"]},
+		Vowels:     []phonology.Vowel{vs["a"], vs["i"]},
+		Segments:   []phonology.CustomSegment{ch},
+		Onsets: phonotactics.ConsonantHierarchy{
+			Onset:       true,
+			Tiers:       [][]phonology.Consonant{{cs["k"], cs["s"]}, {cs["p"], pʰ}, {cs["t"]}},
+			CustomTiers: [][]phonology.CustomSegment{{}, {ch, {Symbol: "sh"}}},
+		},
+		Nuclei: phonotactics.NucleusHierarchy{
+			Monophthongs: []phonology.Vowel{vs["a"], vs["i"], vs["u"]},
+			Diphthongs:   []phonology.Diphthong{ai},
+		},
+		Codas: phonotactics.ConsonantHierarchy{NoCluster: []phonology.Consonant{cs["s"], cs["t"]}},
+	}
+	lang.PruneHierarchies()
+
+	// emptied tiers are kept, so that the others keep their rank
+	tiers := [][]string{}
+	for _, tier := range lang.Onsets.Tiers {
+		tiers = append(tiers, consonantIPAs(tier))
+	}
+	if !reflect.DeepEqual(tiers, [][]string{{}, {"p"}, {"t"}}) || !lang.Onsets.Onset {
+		t.Errorf("pruned onset tiers to %v", tiers)
+	}
+	if len(lang.Onsets.CustomTiers) != 2 || len(lang.Onsets.CustomTiers[0]) != 0 ||
+		!reflect.DeepEqual(ipaOf(lang.Onsets.CustomTiers[1][0]), []string{"ch"}) || len(lang.Onsets.CustomTiers[1]) != 1 {
+		t.Errorf("pruned onset custom tiers to %+v", lang.Onsets.CustomTiers)
+	}
+	if got := consonantIPAs(lang.Codas.NoCluster); !reflect.DeepEqual(got, []string{"t"}) {
+		t.Errorf("pruned codas to %v", got)
+	}
+	monophthongs := []phonology.Phoneme{}
+	for _, v := range lang.Nuclei.Monophthongs {
+		monophthongs = append(monophthongs, v)
+	}
+	if got := ipaOf(monophthongs...); !reflect.DeepEqual(got, []string{"a", "i"}) {
+		t.Errorf("pruned monophthongs to %v", got)
+	}
+	// the diphthong is not in the inventory, though its vowels are
+	if len(lang.Nuclei.Diphthongs) != 0 {
+		t.Errorf("kept diphthongs %+v", lang.Nuclei.Diphthongs)
+	}
+	// parts of the hierarchy that were never set stay unset
+	if lang.Nuclei.Onglides != nil || lang.Codas.Tiers != nil {
+		t.Errorf("set unset parts to %+v and %+v", lang.Nuclei.Onglides, lang.Codas.Tiers)
+	}
+	if r := lang.Consistency(); len(r.Stale) != 0 {
+		t.Errorf("still stale after pruning: %v", r.Stale)
+	}
+}
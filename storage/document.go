@@ -0,0 +1,121 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/jheredos/langgen/phonology"
+	"github.com/jheredos/langgen/phonotactics"
+)
+
+// DocumentVersion is the version of the document format written by NewDocument.
+// Documents of later versions are rejected rather than misread
+const DocumentVersion = 1
+
+// Document is a whole language as one versioned JSON document, for backing up,
+// sharing, and version-controlling languages
+type Document struct {
+	Version    int       `json:"version"`
+	ExportedAt time.Time `json:"exportedAt"`
+	Language
+}
+
+// NewDocument wraps a language in a Document of the current version
+func NewDocument(lang Language) Document {
+	return Document{
+		Version:    DocumentVersion,
+		ExportedAt: time.Now().UTC(),
+		Language:   lang,
+	}
+}
+
+// DecodeDocument reads a Document from JSON, rejecting fields the format does not have,
+// and validates it
+func DecodeDocument(r io.Reader) (Document, error) {
+	var doc Document
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(&doc)
+	if err != nil {
+		return doc, fmt.Errorf("invalid language document: %v", err)
+	}
+	return doc, doc.Validate()
+}
+
+// Validate checks that a document is of a supported version, that its enums are in range,
+// that its custom segments and diphthongs are well formed, and that its allophonies,
+// orthography, and lexicon are written in valid IPA. It reports
+// every problem it finds at once
+func (d Document) Validate() error {
+	problems := []string{}
+	check := func(ok bool, problem string, args ...interface{}) {
+		if !ok {
+			problems = append(problems, fmt.Sprintf(problem, args...))
+		}
+	}
+
+	check(d.Version > 0, "missing version")
+	check(d.Version <= DocumentVersion, "unsupported version %d, expected at most %d", d.Version, DocumentVersion)
+
+	o := d.Options
+	for _, length := range []phonotactics.WordLength{o.MinWordLength, o.MedianWordLength, o.MaxWordLength} {
+		check(length <= phonotactics.XXLongWL, "unknown word length %d", length)
+	}
+	check(o.StressType <= phonotactics.HeaviestOfLastThreeST, "unknown stress type %d", o.StressType)
+	check(o.LengthModel <= phonotactics.HistogramLM, "unknown length model %d", o.LengthModel)
+	check(o.LengthUnit <= phonotactics.MoraLU, "unknown length unit %d", o.LengthUnit)
+	check(o.Syllabification <= phonotactics.SonoritySYP, "unknown syllabification principle %d", o.Syllabification)
+	if o.LengthModel == phonotactics.HistogramLM {
+		_, err := phonotactics.HistogramWordLengths(o.LengthHistogram)
+		check(err == nil, "%v", err)
+	}
+
+	r := d.Rules
+	for _, frequency := range []phonotactics.RuleFrequency{r.InitialNullOnset, r.FinalNullCoda, r.Hiatus, r.Gemination, r.NasalPlaceAssimilation} {
+		check(frequency <= phonotactics.AlwaysRF, "unknown rule frequency %d", frequency)
+	}
+	for i, c := range r.OCP {
+		check(c.Frequency <= phonotactics.AlwaysRF, "OCP constraint %d: unknown rule frequency %d", i, c.Frequency)
+		check(c.Similarity <= phonotactics.SamePlaceOS, "OCP constraint %d: unknown similarity %d", i, c.Similarity)
+		check(c.Window >= 0, "OCP constraint %d: negative window", i)
+	}
+	for i, c := range r.Sequences {
+		check(c.Frequency <= phonotactics.AlwaysRF, "sequence constraint %d: unknown rule frequency %d", i, c.Frequency)
+		for _, step := range c.Sequence {
+			for _, context := range step.Contexts {
+				check(context <= phonotactics.SyllableBoundaryPC, "sequence constraint %d: unknown context %d", i, context)
+			}
+		}
+	}
+
+	symbols := map[string]bool{}
+	for i, s := range d.Segments {
+		err := s.Validate()
+		check(err == nil, "custom segment %d: %v", i, err)
+		check(!symbols[s.Symbol], "custom segment %d: symbol %q is used twice", i, s.Symbol)
+		symbols[s.Symbol] = true
+	}
+	for i, dp := range d.Diphthongs {
+		check(dp.Nucleus != phonology.Vowel{}, "diphthong %d: missing nucleus", i)
+	}
+
+	for i, a := range d.Allophonies {
+		err := a.Validate()
+		check(err == nil, "allophony %d: %v", i, err)
+	}
+	err := d.Orthography.Validate()
+	check(err == nil, "orthography: %v", err)
+	for i, entry := range d.Lexicon.Entries {
+		_, err := phonology.ParseWordWithSegments(entry.Form, d.Segments)
+		check(err == nil, "lexicon entry %d: %v", i, err)
+	}
+
+	if len(problems) > 0 {
+		return errors.New("invalid language document: " + strings.Join(problems, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package storage
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestDecodeDocument(t *testing.T) {
+	lang := testLanguage(t, "lang")
+	data, err := json.Marshal(NewDocument(lang))
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, err := DecodeDocument(strings.NewReader(string(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.Version != DocumentVersion || !sameJSON(t, doc.Language, lang) {
+		t.Errorf("exported %+v, read back %+v", lang, doc.Language)
+	}
+}
+
+func TestValidateDocument(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		doc  string
+		// each problem the error must report, or none if the document is valid
+		problems []string
+	}{
+		{"valid", `{"version": 1, "lexicon": {"entries": [{"form": "pata", "gloss": "stone"}]}}`, nil},
+		{"not JSON", `{"version": 1,`, []string{"invalid language document"}},
+		{"unknown field", `{"version": 1, "dialect": "north"}`, []string{"unknown field"}},
+		{"missing version", `{}`, []string{"missing version"}},
+		{"later version", `{"version": 2}`, []string{"unsupported version 2"}},
+		{"enums", `{"version": 1, "options": {"stressType": 99, "minWordLength": 99}, "rules": {"hiatus": 99}}`,
+			[]string{"unknown stress type 99", "unknown word length 99", "unknown rule frequency 99"}},
+		{"length unit", `{"version": 1, "options": {"lengthUnit": 9}}`, []string{"unknown length unit 9"}},
+		{"histogram", `{"version": 1, "options": {"lengthModel": 3, "lengthHistogram": [0, 0]}}`, []string{"histogram"}},
+		{"OCP window", `{"version": 1, "rules": {"ocp": [{"window": -1}]}}`, []string{"OCP constraint 0: negative window"}},
+		{"lexicon", `{"version": 1, "lexicon": {"entries": [{"form": "ːpa", "gloss": "stone"}]}}`, []string{"lexicon entry 0"}},
+		{"segments", `{"version": 1, "segments": [{"symbol": "ch"}, {"symbol": " "}, {"symbol": "ch"}]}`,
+			[]string{"custom segment 1: custom segment has no symbol", "custom segment 2: symbol \"ch\" is used twice"}},
+		{"diphthong", `{"version": 1, "diphthongs": [{}]}`, []string{"diphthong 0: missing nucleus"}},
+		// a lexicon may spell words with the language's custom segments
+		{"lexicon with segments", `{"version": 1, "segments": [{"symbol": "Ch"}], "lexicon": {"entries": [{"form": "Cha", "gloss": "tea"}]}}`, nil},
+	} {
+		_, err := DecodeDocument(strings.NewReader(tc.doc))
+		if len(tc.problems) == 0 {
+			if err != nil {
+				t.Errorf("%s: %v", tc.name, err)
+			}
+			continue
+		}
+		if err == nil {
+			t.Errorf("%s: expected an error", tc.name)
+			continue
+		}
+		for _, problem := range tc.problems {
+			if !strings.Contains(err.Error(), problem) {
+				t.Errorf("%s: %q does not report %q", tc.name, err, problem)
+			}
+		}
+	}
+}
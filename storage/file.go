@@ -0,0 +1,180 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"time"
+)
+
+// FileStore is a LanguageStore that keeps each language as a JSON file named
+// after its id in a directory, with its revisions alongside in <id>.revisions.json.
+// Files are replaced atomically, so a crash mid-write never leaves a language half written
+type FileStore struct {
+	recordStore
+	dir string
+	mu  sync.Mutex // serializes updates, which read a file before rewriting it
+}
+
+// validID limits ids to characters that are safe in a file name,
+// so that an id cannot reach outside the store's directory
+var validID = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// NewFileStore creates a FileStore in the directory, creating the directory if needed
+func NewFileStore(dir string) (*FileStore, error) {
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return nil, err
+	}
+	s := &FileStore{dir: dir}
+	s.recordStore = recordStore{s}
+	return s, nil
+}
+
+func (s *FileStore) path(id string) (string, error) {
+	return s.pathWithSuffix(id, ".json")
+}
+
+func (s *FileStore) revisionsPath(id string) (string, error) {
+	return s.pathWithSuffix(id, ".revisions.json")
+}
+
+func (s *FileStore) pathWithSuffix(id string, suffix string) (string, error) {
+	if !validID.MatchString(id) {
+		return "", errors.New("invalid language id")
+	}
+	return filepath.Join(s.dir, id+suffix), nil
+}
+
+func (s *FileStore) load(id string) (Language, error) {
+	var lang Language
+	path, err := s.path(id)
+	if err != nil {
+		return lang, err
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return lang, ErrNotFound
+	}
+	if err != nil {
+		return lang, err
+	}
+
+	err = json.Unmarshal(data, &lang)
+	return lang, err
+}
+
+func (s *FileStore) update(id string, change func(*Language)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	lang, err := s.load(id)
+	if err == ErrNotFound {
+		lang, err = Language{ID: id}, nil
+	}
+	if err != nil {
+		return err
+	}
+	change(&lang)
+
+	path, err := s.path(id)
+	if err != nil {
+		return err
+	}
+	return s.write(path, lang)
+}
+
+func (s *FileStore) revise(id string, summary string, change func(*Language)) (Revision, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	lang, err := s.load(id)
+	if err == ErrNotFound {
+		lang, err = Language{ID: id}, nil
+	}
+	if err != nil {
+		return Revision{}, err
+	}
+	revisions, err := s.readRevisions(id)
+	if err != nil {
+		return Revision{}, err
+	}
+	change(&lang)
+
+	rev := Revision{Number: len(revisions) + 1, Summary: summary, Created: time.Now().UTC(), Language: lang}
+	revisionsPath, err := s.revisionsPath(id)
+	if err != nil {
+		return Revision{}, err
+	}
+	path, err := s.path(id)
+	if err != nil {
+		return Revision{}, err
+	}
+
+	// write the history first, so that the current language is never ahead of it
+	err = s.write(revisionsPath, append(revisions, rev))
+	if err != nil {
+		return Revision{}, err
+	}
+	return rev, s.write(path, lang)
+}
+
+func (s *FileStore) revisions(id string) ([]Revision, error) {
+	_, err := s.load(id)
+	if err != nil {
+		return nil, err
+	}
+	return s.readRevisions(id)
+}
+
+// readRevisions reads a language's revisions, which are empty if it has none
+func (s *FileStore) readRevisions(id string) ([]Revision, error) {
+	var revisions []Revision
+	path, err := s.revisionsPath(id)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return revisions, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal(data, &revisions)
+	return revisions, err
+}
+
+// write saves a value as JSON to a temporary file and renames it over the file at the path
+func (s *FileStore) write(path string, value interface{}) error {
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(s.dir, filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // fails harmlessly once renamed
+
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
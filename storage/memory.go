@@ -0,0 +1,68 @@
+package storage
+
+import (
+	"sync"
+	"time"
+)
+
+// MemoryStore is a LanguageStore that keeps languages in memory, for running
+// without a database. It is safe for concurrent use
+type MemoryStore struct {
+	recordStore
+	mu        sync.RWMutex
+	languages map[string]Language
+	history   map[string][]Revision
+}
+
+// NewMemoryStore creates an empty MemoryStore
+func NewMemoryStore() *MemoryStore {
+	s := &MemoryStore{languages: map[string]Language{}, history: map[string][]Revision{}}
+	s.recordStore = recordStore{s}
+	return s
+}
+
+func (s *MemoryStore) load(id string) (Language, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	lang, found := s.languages[id]
+	if !found {
+		return Language{}, ErrNotFound
+	}
+	return lang, nil
+}
+
+func (s *MemoryStore) update(id string, change func(*Language)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	lang, found := s.languages[id]
+	if !found {
+		lang = Language{ID: id}
+	}
+	change(&lang)
+	s.languages[id] = lang
+	return nil
+}
+
+func (s *MemoryStore) revise(id string, summary string, change func(*Language)) (Revision, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	lang, found := s.languages[id]
+	if !found {
+		lang = Language{ID: id}
+	}
+	change(&lang)
+
+	rev := Revision{Number: len(s.history[id]) + 1, Summary: summary, Created: time.Now().UTC(), Language: lang}
+	s.history[id] = append(s.history[id], rev)
+	s.languages[id] = lang
+	return rev, nil
+}
+
+func (s *MemoryStore) revisions(id string) ([]Revision, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if _, found := s.languages[id]; !found {
+		return nil, ErrNotFound
+	}
+	return s.history[id], nil
+}
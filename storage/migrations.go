@@ -0,0 +1,236 @@
+package storage
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/jheredos/langgen/lexicon"
+	"github.com/jheredos/langgen/phonology"
+	"github.com/jheredos/langgen/phonotactics"
+)
+
+// migration is one versioned change to the Postgres schema. Migrations are applied in
+// order, each in its own transaction, and recorded in the schema_migrations table
+type migration struct {
+	version int
+	name    string
+	apply   func(tx *sql.Tx) error
+}
+
+// migrations lists every change to the schema. New migrations go at the end,
+// and applied ones must never change
+var migrations = []migration{
+	{1, "create languages table", createLanguagesTable},
+	{2, "convert gob columns to jsonb", convertGobToJSONB},
+	{3, "add allophonies and orthography", addAllophoniesAndOrthography},
+	{4, "add revisions and parent links", addRevisions},
+	{5, "add diphthongs and custom segments", addDiphthongsAndSegments},
+}
+
+// gobColumns are the columns that held gob blobs before migration 2, each with a
+// function returning a pointer to the type stored in it
+var gobColumns = []struct {
+	name string
+	new  func() interface{}
+}{
+	{"consonants", func() interface{} { return &[]phonology.Consonant{} }},
+	{"vowels", func() interface{} { return &[]phonology.Vowel{} }},
+	{"onset_clusters", func() interface{} { return &phonotactics.ConsonantHierarchy{} }},
+	{"nucleus_clusters", func() interface{} { return &phonotactics.NucleusHierarchy{} }},
+	{"coda_clusters", func() interface{} { return &phonotactics.ConsonantHierarchy{} }},
+	{"options", func() interface{} { return &phonotactics.PhonotacticOptions{} }},
+	{"rules", func() interface{} { return &phonotactics.PhonotacticRules{} }},
+	{"lexicon", func() interface{} { return &lexicon.Lexicon{} }},
+}
+
+// Migrate applies any migrations the database has not seen yet. Each migration takes an
+// exclusive lock on schema_migrations, so that several servers starting at once apply it
+// only once
+func (s *PostgresStore) Migrate() error {
+	_, err := s.DB.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version integer PRIMARY KEY,
+		name text NOT NULL,
+		applied_at timestamptz NOT NULL DEFAULT now()
+	);`)
+	if err != nil {
+		return err
+	}
+
+	for _, m := range migrations {
+		err = s.applyMigration(m)
+		if err != nil {
+			return fmt.Errorf("migration %d (%s): %v", m.version, m.name, err)
+		}
+	}
+	return nil
+}
+
+func (s *PostgresStore) applyMigration(m migration) error {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() // no-op after commit
+
+	_, err = tx.Exec(`LOCK TABLE schema_migrations IN EXCLUSIVE MODE;`)
+	if err != nil {
+		return err
+	}
+
+	var applied bool
+	err = tx.QueryRow(`SELECT EXISTS (SELECT 1 FROM schema_migrations WHERE version=$1);`, m.version).Scan(&applied)
+	if err != nil || applied {
+		return err
+	}
+
+	err = m.apply(tx)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(`INSERT INTO schema_migrations (version, name) VALUES ($1, $2);`, m.version, m.name)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// createLanguagesTable creates the languages table as it was before migrations existed,
+// with a gob-encoded bytea column for each part of a language, or adds whatever columns
+// an existing table is missing
+func createLanguagesTable(tx *sql.Tx) error {
+	_, err := tx.Exec(`CREATE TABLE IF NOT EXISTS languages (lang_id text PRIMARY KEY);`)
+	if err != nil {
+		return err
+	}
+	for _, column := range gobColumns {
+		_, err = tx.Exec(fmt.Sprintf(`ALTER TABLE languages ADD COLUMN IF NOT EXISTS %s bytea;`, column.name))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// convertGobToJSONB replaces each gob column with a JSONB column, decoding every
+// existing row's gob and re-encoding it as JSON
+func convertGobToJSONB(tx *sql.Tx) error {
+	for _, column := range gobColumns {
+		_, err := tx.Exec(fmt.Sprintf(`ALTER TABLE languages ADD COLUMN %s_json jsonb;`, column.name))
+		if err != nil {
+			return err
+		}
+
+		err = convertColumn(tx, column.name, column.new)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(fmt.Sprintf(`ALTER TABLE languages DROP COLUMN %[1]s; ALTER TABLE languages RENAME COLUMN %[1]s_json TO %[1]s;`, column.name))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// convertColumn fills a column's new JSONB twin from its gob blobs
+func convertColumn(tx *sql.Tx, column string, new func() interface{}) error {
+	rows, err := tx.Query(fmt.Sprintf(`SELECT lang_id, %s FROM languages WHERE %[1]s IS NOT NULL;`, column))
+	if err != nil {
+		return err
+	}
+
+	// read every row before updating, since a transaction can't run statements mid-query
+	blobs := map[string][]byte{}
+	for rows.Next() {
+		var id string
+		var blob []byte
+		err = rows.Scan(&id, &blob)
+		if err != nil {
+			rows.Close()
+			return err
+		}
+		blobs[id] = blob
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	for id, blob := range blobs {
+		if len(blob) == 0 {
+			continue
+		}
+		doc, err := gobToJSON(blob, new)
+		if err != nil {
+			return fmt.Errorf("converting %s of language %s: %v", column, id, err)
+		}
+		_, err = tx.Exec(fmt.Sprintf(`UPDATE languages SET %s_json=$2::jsonb WHERE lang_id=$1;`, column), id, string(doc))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// gobToJSON re-encodes a gob blob as JSON. Since the gob columns are dropped once converted,
+// it checks that the JSON decodes to exactly what the gob did, and fails rather than lose
+// any feature the JSON encoding of the day leaves out
+func gobToJSON(blob []byte, new func() interface{}) ([]byte, error) {
+	value := new()
+	err := gob.NewDecoder(bytes.NewBuffer(blob)).Decode(value)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	decoded := new()
+	err = json.Unmarshal(doc, decoded)
+	if err != nil {
+		return nil, err
+	}
+	if !reflect.DeepEqual(value, decoded) {
+		return nil, errors.New("the JSON encoding loses part of it")
+	}
+	return doc, nil
+}
+
+// addAllophoniesAndOrthography adds JSONB columns for the parts of a language
+// added after the switch to JSONB
+func addAllophoniesAndOrthography(tx *sql.Tx) error {
+	_, err := tx.Exec(`ALTER TABLE languages ADD COLUMN IF NOT EXISTS allophonies jsonb, ADD COLUMN IF NOT EXISTS orthography jsonb;`)
+	return err
+}
+
+// addRevisions adds the language_revisions table, holding a JSONB snapshot of each revision
+// of a language, and a column linking forked languages to their parents
+func addRevisions(tx *sql.Tx) error {
+	_, err := tx.Exec(`CREATE TABLE language_revisions (
+		lang_id text NOT NULL REFERENCES languages (lang_id) ON DELETE CASCADE,
+		revision integer NOT NULL,
+		summary text NOT NULL,
+		created_at timestamptz NOT NULL DEFAULT now(),
+		language jsonb NOT NULL,
+		PRIMARY KEY (lang_id, revision)
+	);`)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(`ALTER TABLE languages ADD COLUMN IF NOT EXISTS parent jsonb;`)
+	return err
+}
+
+// addDiphthongsAndSegments adds columns for the diphthongs and custom segments a language
+// treats as single phonemes
+func addDiphthongsAndSegments(tx *sql.Tx) error {
+	_, err := tx.Exec(`ALTER TABLE languages ADD COLUMN IF NOT EXISTS diphthongs jsonb, ADD COLUMN IF NOT EXISTS segments jsonb;`)
+	return err
+}
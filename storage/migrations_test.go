@@ -0,0 +1,63 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/gob"
+	"testing"
+
+	"github.com/jheredos/langgen/lexicon"
+	"github.com/jheredos/langgen/phonology"
+	"github.com/jheredos/langgen/phonotactics"
+)
+
+func gobBlob(t *testing.T, value interface{}) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	err := gob.NewEncoder(&buf).Encode(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestGobToJSONKeepsEveryFeature(t *testing.T) {
+	labialized := phonology.Consonant{Place: phonology.VelarCP, Manner: phonology.StopCM, Coarticulation: phonology.LabialCC,
+		NonPulmonic: phonology.PulmonicCNP, Voiced: phonology.UnvoicedCV, Aspirated: phonology.UnaspiratedCA,
+		Lateral: phonology.CentralCL, Sibilant: phonology.NonsibilantCS, Geminate: phonology.GeminateCG}
+	creaky := phonology.Vowel{Height: phonology.OpenVH, Frontness: phonology.CentralVF, Phonation: phonology.CreakyVP,
+		Rounding: phonology.UnroundedVR, Nasal: phonology.NasalVN, Length: phonology.LongVL, TongueRoot: phonology.RetractedVTR,
+		Tenseness: phonology.LaxVTS, Rhoticity: phonology.RhoticVRH, Pharyngealization: phonology.PharyngealizedVPH}
+
+	values := map[string]interface{}{
+		"consonants":       &[]phonology.Consonant{labialized, {Manner: phonology.NasalCM}},
+		"vowels":           &[]phonology.Vowel{creaky, {Height: phonology.CloseVH}},
+		"onset_clusters":   &phonotactics.ConsonantHierarchy{Onset: true, NoCluster: []phonology.Consonant{labialized}, Tiers: [][]phonology.Consonant{{labialized}, {}}},
+		"nucleus_clusters": &phonotactics.NucleusHierarchy{Monophthongs: []phonology.Vowel{creaky}},
+		"coda_clusters":    &phonotactics.ConsonantHierarchy{Tiers: [][]phonology.Consonant{{labialized}}},
+		"options":          &phonotactics.PhonotacticOptions{StressType: phonotactics.InitialST, ToneCategories: []phonotactics.ToneCategory{55, 214}},
+		"rules":            &phonotactics.PhonotacticRules{Hiatus: phonotactics.NeverRF, OCP: []phonotactics.OCPConstraint{{Pattern: labialized, Window: 2, Frequency: phonotactics.SeldomRF}}},
+		"lexicon":          &lexicon.Lexicon{Entries: []lexicon.Entry{{Form: "kʷːa", Gloss: "water"}}},
+	}
+
+	for _, column := range gobColumns {
+		value, found := values[column.name]
+		if !found {
+			t.Errorf("no test value for column %s", column.name)
+			continue
+		}
+		if _, err := gobToJSON(gobBlob(t, value), column.new); err != nil {
+			t.Errorf("%s: %v", column.name, err)
+		}
+	}
+}
+
+func TestGobToJSONRefusesToLoseData(t *testing.T) {
+	type lossy struct {
+		Kept    int
+		Dropped int `json:"-"`
+	}
+	_, err := gobToJSON(gobBlob(t, &lossy{1, 2}), func() interface{} { return &lossy{} })
+	if err == nil {
+		t.Error("a field the JSON encoding drops should fail the conversion")
+	}
+}
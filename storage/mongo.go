@@ -0,0 +1,382 @@
+package storage
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	"github.com/jheredos/langgen/lexicon"
+	"github.com/jheredos/langgen/phonology"
+	"github.com/jheredos/langgen/phonotactics"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsoncodec"
+	"go.mongodb.org/mongo-driver/bson/bsonrw"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// mongoTimeout limits how long any one operation on Mongo may take
+const mongoTimeout = 10 * time.Second
+
+// MongoStore is a LanguageStore that keeps each language as a native BSON document,
+// shaped like Language, in the languages collection, and its revisions in the revisions
+// collection. Puts set only their own fields, so concurrent updates to different parts
+// of a language do not clobber each other
+type MongoStore struct {
+	Client       *mongo.Client
+	Collection   *mongo.Collection
+	Revisions    *mongo.Collection
+	Transactions bool // whether the deployment, a replica set or sharded cluster, supports transactions
+}
+
+// mongoRevision is a Revision as stored in the revisions collection, keyed by
+// language and number so that two revisions can never take the same number
+type mongoRevision struct {
+	Key      revisionKey `bson:"_id"`
+	Revision `bson:",inline"`
+}
+
+type revisionKey struct {
+	LanguageID string `bson:"lang_id"`
+	Number     int    `bson:"number"`
+}
+
+// maxReviseAttempts bounds how many times Revise retries after losing a race for a revision number
+const maxReviseAttempts = 5
+
+// NewMongoStore connects to the Mongo deployment at the uri and uses the languages
+// collection of the named database, pinging the server to fail fast if it is unreachable
+func NewMongoStore(uri string, database string) (*MongoStore, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), mongoTimeout)
+	defer cancel()
+
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri).SetRegistry(mongoRegistry()))
+	if err != nil {
+		return nil, err
+	}
+	err = client.Ping(ctx, nil)
+	if err != nil {
+		client.Disconnect(ctx)
+		return nil, err
+	}
+
+	// only replica set members, which name their set, and mongos routers support transactions
+	var hello struct {
+		SetName string `bson:"setName"`
+		Msg     string `bson:"msg"`
+	}
+	err = client.Database("admin").RunCommand(ctx, bson.D{{Key: "isMaster", Value: 1}}).Decode(&hello)
+	if err != nil {
+		client.Disconnect(ctx)
+		return nil, err
+	}
+
+	return &MongoStore{
+		Client:       client,
+		Collection:   client.Database(database).Collection("languages"),
+		Revisions:    client.Database(database).Collection("revisions"),
+		Transactions: hello.SetName != "" || hello.Msg == "isdbgrid",
+	}, nil
+}
+
+// sequenceStepDoc is the BSON form of a phonotactics.SequenceStep, whose pattern is an
+// interface BSON cannot decode into, so it is stored in its JSON form
+type sequenceStepDoc struct {
+	Pattern  phonology.PatternJSON             `bson:"pattern"`
+	Contexts []phonotactics.PhonotacticContext `bson:"contexts"`
+}
+
+// mongoRegistry is the default BSON registry, with sequence steps encoded as sequenceStepDocs
+func mongoRegistry() *bsoncodec.Registry {
+	stepType := reflect.TypeOf(phonotactics.SequenceStep{})
+	docType := reflect.TypeOf(sequenceStepDoc{})
+
+	rb := bson.NewRegistryBuilder()
+	rb.RegisterTypeEncoder(stepType, bsoncodec.ValueEncoderFunc(func(ec bsoncodec.EncodeContext, vw bsonrw.ValueWriter, val reflect.Value) error {
+		step := val.Interface().(phonotactics.SequenceStep)
+		pattern, err := phonology.NewPatternJSON(step.Pattern)
+		if err != nil {
+			return err
+		}
+		enc, err := ec.LookupEncoder(docType)
+		if err != nil {
+			return err
+		}
+		return enc.EncodeValue(ec, vw, reflect.ValueOf(sequenceStepDoc{pattern, step.Contexts}))
+	}))
+	rb.RegisterTypeDecoder(stepType, bsoncodec.ValueDecoderFunc(func(dc bsoncodec.DecodeContext, vr bsonrw.ValueReader, val reflect.Value) error {
+		var doc sequenceStepDoc
+		dec, err := dc.LookupDecoder(docType)
+		if err != nil {
+			return err
+		}
+		err = dec.DecodeValue(dc, vr, reflect.ValueOf(&doc).Elem())
+		if err != nil {
+			return err
+		}
+		pattern, err := doc.Pattern.FromJSON()
+		if err != nil {
+			return err
+		}
+		val.Set(reflect.ValueOf(phonotactics.SequenceStep{Pattern: pattern, Contexts: doc.Contexts}))
+		return nil
+	}))
+	return rb.Build()
+}
+
+// Close disconnects from the deployment
+func (s *MongoStore) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), mongoTimeout)
+	defer cancel()
+	return s.Client.Disconnect(ctx)
+}
+
+// get reads the named fields of a language's document
+func (s *MongoStore) get(id string, fields ...string) (Language, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), mongoTimeout)
+	defer cancel()
+
+	projection := bson.M{}
+	for _, field := range fields {
+		projection[field] = 1
+	}
+
+	var lang Language
+	err := s.Collection.FindOne(ctx, bson.M{"_id": id}, options.FindOne().SetProjection(projection)).Decode(&lang)
+	if err == mongo.ErrNoDocuments {
+		return lang, ErrNotFound
+	}
+	return lang, err
+}
+
+// set writes one field of a language's document, creating the document if needed
+func (s *MongoStore) set(id string, field string, value interface{}) error {
+	ctx, cancel := context.WithTimeout(context.Background(), mongoTimeout)
+	defer cancel()
+
+	_, err := s.Collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{field: value}}, options.Update().SetUpsert(true))
+	return err
+}
+
+// updateInventory applies a change to the language's inventory, writing the inventory and
+// the hierarchies it prunes in a single update, creating the document if needed
+func (s *MongoStore) updateInventory(id string, change func(*Language)) error {
+	lang, err := s.get(id, "consonants", "vowels", "diphthongs", "segments", "onsets", "nuclei", "codas")
+	if err == ErrNotFound {
+		lang, err = Language{ID: id}, nil
+	}
+	if err != nil {
+		return err
+	}
+	change(&lang)
+
+	ctx, cancel := context.WithTimeout(context.Background(), mongoTimeout)
+	defer cancel()
+
+	fields := bson.M{"consonants": lang.Consonants, "vowels": lang.Vowels, "diphthongs": lang.Diphthongs, "segments": lang.Segments,
+		"onsets": lang.Onsets, "nuclei": lang.Nuclei, "codas": lang.Codas}
+	_, err = s.Collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": fields}, options.Update().SetUpsert(true))
+	return err
+}
+
+// GetLanguage returns the whole language
+func (s *MongoStore) GetLanguage(id string) (Language, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), mongoTimeout)
+	defer cancel()
+
+	var lang Language
+	err := s.Collection.FindOne(ctx, bson.M{"_id": id}).Decode(&lang)
+	if err == mongo.ErrNoDocuments {
+		return lang, ErrNotFound
+	}
+	return lang, err
+}
+
+// PutLanguage replaces the whole language's document
+func (s *MongoStore) PutLanguage(lang Language) error {
+	ctx, cancel := context.WithTimeout(context.Background(), mongoTimeout)
+	defer cancel()
+
+	_, err := s.Collection.ReplaceOne(ctx, bson.M{"_id": lang.ID}, lang, options.Replace().SetUpsert(true))
+	return err
+}
+
+// Revise applies the change to the language and records the result as a new revision.
+// The revision is inserted under the next free number, and the whole attempt is retried
+// if another revision takes that number in the meantime. Where the deployment supports
+// transactions, the revision and the language are written in one. Elsewhere, the revision
+// is deleted again if the language cannot be written, so that history never holds a
+// revision that was not applied
+func (s *MongoStore) Revise(id string, summary string, change func(*Language)) (Revision, error) {
+	for attempt := 1; ; attempt++ {
+		rev, err := s.tryRevise(id, summary, change)
+		if !mongo.IsDuplicateKeyError(err) || attempt == maxReviseAttempts {
+			return rev, err
+		}
+	}
+}
+
+func (s *MongoStore) tryRevise(id string, summary string, change func(*Language)) (Revision, error) {
+	lang, err := s.GetLanguage(id)
+	if err == ErrNotFound {
+		lang, err = Language{ID: id}, nil
+	}
+	if err != nil {
+		return Revision{}, err
+	}
+	change(&lang)
+
+	ctx, cancel := context.WithTimeout(context.Background(), mongoTimeout)
+	defer cancel()
+
+	var last mongoRevision
+	err = s.Revisions.FindOne(ctx, bson.M{"_id.lang_id": id}, options.FindOne().SetSort(bson.M{"_id.number": -1})).Decode(&last)
+	if err != nil && err != mongo.ErrNoDocuments {
+		return Revision{}, err
+	}
+
+	rev := Revision{Number: last.Number + 1, Summary: summary, Created: time.Now().UTC(), Language: lang}
+	key := revisionKey{id, rev.Number}
+	if s.Transactions {
+		err = s.Client.UseSession(ctx, func(sc mongo.SessionContext) error {
+			_, err := sc.WithTransaction(sc, func(sc mongo.SessionContext) (interface{}, error) {
+				_, err := s.Revisions.InsertOne(sc, mongoRevision{key, rev})
+				if err != nil {
+					return nil, err
+				}
+				return s.Collection.ReplaceOne(sc, bson.M{"_id": id}, lang, options.Replace().SetUpsert(true))
+			})
+			return err
+		})
+		if err != nil {
+			return Revision{}, err
+		}
+		return rev, nil
+	}
+
+	_, err = s.Revisions.InsertOne(ctx, mongoRevision{key, rev})
+	if err != nil {
+		return Revision{}, err
+	}
+	err = s.PutLanguage(lang)
+	if err != nil {
+		// the put may have failed by timing out, so the revision is deleted under a context of its own
+		cleanup, cancelCleanup := context.WithTimeout(context.Background(), mongoTimeout)
+		defer cancelCleanup()
+		s.Revisions.DeleteOne(cleanup, bson.M{"_id": key})
+		return Revision{}, err
+	}
+	return rev, nil
+}
+
+// ListRevisions returns the language's revisions, oldest first, without their snapshots
+func (s *MongoStore) ListRevisions(id string) ([]Revision, error) {
+	_, err := s.get(id, "_id")
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), mongoTimeout)
+	defer cancel()
+
+	cursor, err := s.Revisions.Find(ctx, bson.M{"_id.lang_id": id},
+		options.Find().SetSort(bson.M{"_id.number": 1}).SetProjection(bson.M{"language": 0}))
+	if err != nil {
+		return nil, err
+	}
+	var docs []mongoRevision
+	err = cursor.All(ctx, &docs)
+	if err != nil {
+		return nil, err
+	}
+
+	revisions := []Revision{}
+	for _, doc := range docs {
+		revisions = append(revisions, doc.Revision)
+	}
+	return revisions, nil
+}
+
+// GetRevision returns one of the language's revisions
+func (s *MongoStore) GetRevision(id string, number int) (Revision, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), mongoTimeout)
+	defer cancel()
+
+	var doc mongoRevision
+	err := s.Revisions.FindOne(ctx, bson.M{"_id": revisionKey{id, number}}).Decode(&doc)
+	if err == mongo.ErrNoDocuments {
+		_, err = s.get(id, "_id")
+		if err == nil {
+			err = ErrRevisionNotFound
+		}
+	}
+	return doc.Revision, err
+}
+
+// GetInventory returns the language's consonants, vowels, diphthongs, and custom segments
+func (s *MongoStore) GetInventory(id string) (phonology.Inventory, error) {
+	lang, err := s.get(id, "consonants", "vowels", "diphthongs", "segments")
+	return phonology.Inventory{LanguageID: id, Consonants: lang.Consonants, Vowels: lang.Vowels, Diphthongs: lang.Diphthongs, Segments: lang.Segments}, err
+}
+
+// PutConsonants replaces the language's consonants, removing those dropped from its hierarchies
+func (s *MongoStore) PutConsonants(id string, cs []phonology.Consonant) error {
+	return s.updateInventory(id, func(lang *Language) { lang.SetConsonants(cs) })
+}
+
+// PutVowels replaces the language's vowels, removing those dropped from its hierarchies
+func (s *MongoStore) PutVowels(id string, vs []phonology.Vowel) error {
+	return s.updateInventory(id, func(lang *Language) { lang.SetVowels(vs) })
+}
+
+// GetHierarchies returns the language's onset, nucleus, and coda hierarchies
+func (s *MongoStore) GetHierarchies(id string) (Hierarchies, error) {
+	lang, err := s.get(id, "onsets", "nuclei", "codas")
+	return Hierarchies{Onset: lang.Onsets, Nucleus: lang.Nuclei, Coda: lang.Codas}, err
+}
+
+// PutConsonantHierarchy replaces the language's onset or coda hierarchy
+func (s *MongoStore) PutConsonantHierarchy(id string, h phonotactics.ConsonantHierarchy) error {
+	if h.Onset {
+		return s.set(id, "onsets", h)
+	}
+	return s.set(id, "codas", h)
+}
+
+// PutNucleusHierarchy replaces the language's nucleus hierarchy
+func (s *MongoStore) PutNucleusHierarchy(id string, h phonotactics.NucleusHierarchy) error {
+	return s.set(id, "nuclei", h)
+}
+
+// GetOptions returns the language's phonotactic options
+func (s *MongoStore) GetOptions(id string) (phonotactics.PhonotacticOptions, error) {
+	lang, err := s.get(id, "options")
+	return lang.Options, err
+}
+
+// PutOptions replaces the language's phonotactic options
+func (s *MongoStore) PutOptions(id string, options phonotactics.PhonotacticOptions) error {
+	return s.set(id, "options", options)
+}
+
+// GetRules returns the language's phonotactic rules
+func (s *MongoStore) GetRules(id string) (phonotactics.PhonotacticRules, error) {
+	lang, err := s.get(id, "rules")
+	return lang.Rules, err
+}
+
+// PutRules replaces the language's phonotactic rules
+func (s *MongoStore) PutRules(id string, rules phonotactics.PhonotacticRules) error {
+	return s.set(id, "rules", rules)
+}
+
+// GetLexicon returns the language's lexicon
+func (s *MongoStore) GetLexicon(id string) (lexicon.Lexicon, error) {
+	lang, err := s.get(id, "lexicon")
+	return lang.Lexicon, err
+}
+
+// PutLexicon replaces the language's lexicon
+func (s *MongoStore) PutLexicon(id string, lex lexicon.Lexicon) error {
+	return s.set(id, "lexicon", lex)
+}
@@ -0,0 +1,111 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jheredos/langgen/lexicon"
+	"github.com/jheredos/langgen/phonology"
+	"github.com/jheredos/langgen/phonotactics"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func TestSequenceConstraintsSurviveBSON(t *testing.T) {
+	lang := Language{ID: "lang", Rules: phonotactics.PhonotacticRules{
+		Sequences: []phonotactics.SequenceConstraint{{
+			Sequence: phonotactics.Sequence{
+				{Pattern: phonology.Consonant{Manner: phonology.NasalCM}},
+				{Pattern: phonology.Not(phonology.AnyOf(phonology.Vowel{}, phonology.WordBoundary{Final: true}))},
+				{Pattern: phonology.SyllableBoundary{}, Contexts: []phonotactics.PhonotacticContext{phonotactics.CodaPC}},
+			},
+			Frequency: phonotactics.SeldomRF,
+		}},
+	}}
+
+	data, err := bson.MarshalWithRegistry(mongoRegistry(), lang)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded Language
+	if err := bson.UnmarshalWithRegistry(mongoRegistry(), data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded.Rules, lang.Rules) {
+		t.Errorf("stored %#v, got back %#v", lang.Rules, decoded.Rules)
+	}
+}
+
+// newTestMongoStore connects to the deployment at MONGODB_URI, skipping the test if it is not
+// set, and gives the store a database of its own that is dropped once the test is over
+func newTestMongoStore(t *testing.T) *MongoStore {
+	uri := os.Getenv("MONGODB_URI")
+	if uri == "" {
+		t.Skip("set MONGODB_URI to test against a Mongo deployment")
+	}
+	database := fmt.Sprintf("langgen_test_%d", time.Now().UnixNano())
+	store, err := NewMongoStore(uri, database)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), mongoTimeout)
+		defer cancel()
+		store.Client.Database(database).Drop(ctx)
+		store.Close()
+	})
+	return store
+}
+
+func TestMongoStore(t *testing.T) {
+	store := newTestMongoStore(t)
+	testStore(t, store)
+	testConcurrentPuts(t, store)
+}
+
+func TestMongoReviseWithoutTransactions(t *testing.T) {
+	store := newTestMongoStore(t)
+	store.Transactions = false
+
+	// a validator on the languages collection rejects any language with a forbidden word,
+	// so that writing the language fails after its revision has been inserted
+	ctx, cancel := context.WithTimeout(context.Background(), mongoTimeout)
+	defer cancel()
+	validator := bson.M{"lexicon.entries.gloss": bson.M{"$ne": "forbidden"}}
+	err := store.Collection.Database().CreateCollection(ctx, store.Collection.Name(), options.CreateCollection().SetValidator(validator))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	addWord := func(gloss string) func(*Language) {
+		return func(l *Language) {
+			l.Lexicon.Entries = append(l.Lexicon.Entries, lexicon.Entry{Form: "pata", Gloss: gloss})
+		}
+	}
+	if _, err := store.Revise("lang", "add stone", addWord("stone")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Revise("lang", "add forbidden", addWord("forbidden")); err == nil {
+		t.Fatal("revised the language into one the collection rejects")
+	}
+
+	// the revision that could not be applied is gone, and its number is free again
+	revisions, err := store.ListRevisions("lang")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(revisions) != 1 {
+		t.Errorf("listed %+v after a failed revision", revisions)
+	}
+	rev, err := store.Revise("lang", "add water", addWord("water"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rev.Number != 2 {
+		t.Errorf("numbered the revision after a failed one %d", rev.Number)
+	}
+}
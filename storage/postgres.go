@@ -0,0 +1,378 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/jheredos/langgen/lexicon"
+	"github.com/jheredos/langgen/phonology"
+	"github.com/jheredos/langgen/phonotactics"
+	_ "github.com/lib/pq" // postgres driver
+)
+
+// PostgresStore is a LanguageStore backed by the languages table in Postgres,
+// with each part of a language stored as JSONB in its own column
+type PostgresStore struct {
+	DB *sql.DB
+}
+
+// NewPostgresStore opens a connection pool to the database at the uri,
+// migrates the schema to the latest version, and checks that the schema
+// has every column the store uses
+func NewPostgresStore(uri string) (*PostgresStore, error) {
+	db, err := sql.Open("postgres", uri)
+	if err != nil {
+		return nil, err
+	}
+	s := &PostgresStore{DB: db}
+
+	err = s.Migrate()
+	if err == nil {
+		err = s.verifySchema()
+	}
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// verifySchema checks that the languages table has every column in languageColumns, so that
+// a column the store writes without a migration creating it fails at startup, rather than
+// on every write to it
+func (s *PostgresStore) verifySchema() error {
+	rows, err := s.DB.Query(`SELECT column_name FROM information_schema.columns WHERE table_schema=current_schema() AND table_name='languages';`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	found := map[string]bool{}
+	for rows.Next() {
+		var column string
+		err = rows.Scan(&column)
+		if err != nil {
+			return err
+		}
+		found[column] = true
+	}
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	missing := []string{}
+	for _, column := range languageColumns {
+		if !found[column] {
+			missing = append(missing, column)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("the languages table has no column %s; each needs a migration creating it", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// Close closes the connection pool
+func (s *PostgresStore) Close() error {
+	return s.DB.Close()
+}
+
+// queryer is a *sql.DB or a *sql.Tx
+type queryer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// get reads the columns of a language into the destinations, which must be pointers.
+// Columns that have never been set leave their destinations untouched
+func (s *PostgresStore) get(id string, columns []string, destinations ...interface{}) error {
+	return getColumns(s.DB, id, columns, destinations...)
+}
+
+func getColumns(q queryer, id string, columns []string, destinations ...interface{}) error {
+	docs := make([][]byte, len(columns))
+	ptrs := []interface{}{}
+	for i := range docs {
+		ptrs = append(ptrs, &docs[i])
+	}
+
+	stmt := fmt.Sprintf(`SELECT %s FROM languages WHERE lang_id=$1`, strings.Join(columns, ", "))
+	err := q.QueryRow(stmt, id).Scan(ptrs...)
+	if err == sql.ErrNoRows {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	for i, doc := range docs {
+		if len(doc) == 0 {
+			continue
+		}
+		err = json.Unmarshal(doc, destinations[i])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// put writes one column of a language, creating the language's row if needed
+func (s *PostgresStore) put(id string, column string, value interface{}) error {
+	doc, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	stmt := fmt.Sprintf(`INSERT INTO languages (lang_id, %[1]s) VALUES ($1, $2::jsonb) ON CONFLICT (lang_id) DO UPDATE SET %[1]s=$2::jsonb WHERE languages.lang_id=$1;`, column)
+	_, err = s.DB.Exec(stmt, id, string(doc))
+	return err
+}
+
+// languageColumns are the columns of the languages table, in the order of the fields of
+// Language they hold
+var languageColumns = []string{"consonants", "vowels", "diphthongs", "segments", "onset_clusters", "nucleus_clusters", "coda_clusters", "options", "rules", "allophonies", "orthography", "lexicon", "parent"}
+
+// languageFields returns pointers to the fields of a Language held in languageColumns
+func languageFields(lang *Language) []interface{} {
+	return []interface{}{&lang.Consonants, &lang.Vowels, &lang.Diphthongs, &lang.Segments, &lang.Onsets, &lang.Nuclei, &lang.Codas, &lang.Options, &lang.Rules, &lang.Allophonies, &lang.Orthography, &lang.Lexicon, &lang.Parent}
+}
+
+// GetLanguage returns the whole language
+func (s *PostgresStore) GetLanguage(id string) (Language, error) {
+	return getLanguage(s.DB, id)
+}
+
+func getLanguage(q queryer, id string) (Language, error) {
+	lang := Language{ID: id}
+	err := getColumns(q, id, languageColumns, languageFields(&lang)...)
+	return lang, err
+}
+
+// PutLanguage replaces the whole language in a single statement
+func (s *PostgresStore) PutLanguage(lang Language) error {
+	return putLanguage(s.DB, lang)
+}
+
+func putLanguage(q queryer, lang Language) error {
+	args := []interface{}{lang.ID}
+	placeholders, updates := []string{}, []string{}
+	for i, field := range languageFields(&lang) {
+		doc, err := json.Marshal(field)
+		if err != nil {
+			return err
+		}
+		args = append(args, string(doc))
+		placeholders = append(placeholders, fmt.Sprintf("$%d::jsonb", i+2))
+		updates = append(updates, fmt.Sprintf("%s=$%d::jsonb", languageColumns[i], i+2))
+	}
+
+	stmt := fmt.Sprintf(`INSERT INTO languages (lang_id, %s) VALUES ($1, %s) ON CONFLICT (lang_id) DO UPDATE SET %s WHERE languages.lang_id=$1;`,
+		strings.Join(languageColumns, ", "), strings.Join(placeholders, ", "), strings.Join(updates, ", "))
+	_, err := q.Exec(stmt, args...)
+	return err
+}
+
+// lock creates the language's row if it does not exist and locks it until the transaction ends
+func lock(tx *sql.Tx, id string) error {
+	_, err := tx.Exec(`INSERT INTO languages (lang_id) VALUES ($1) ON CONFLICT (lang_id) DO NOTHING;`, id)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(`SELECT lang_id FROM languages WHERE lang_id=$1 FOR UPDATE;`, id)
+	return err
+}
+
+// update applies the change to the language in one transaction, creating the language if
+// it does not exist, for changes that must read the language to write it
+func (s *PostgresStore) update(id string, change func(*Language)) error {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() // no-op after commit
+
+	err = lock(tx, id)
+	if err != nil {
+		return err
+	}
+	lang, err := getLanguage(tx, id)
+	if err != nil {
+		return err
+	}
+	change(&lang)
+	err = putLanguage(tx, lang)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Revise applies the change to the language and records the result as a new revision, in
+// one transaction. The language's row stays locked until it commits, so that concurrent
+// revisions are numbered in turn
+func (s *PostgresStore) Revise(id string, summary string, change func(*Language)) (Revision, error) {
+	var rev Revision
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return rev, err
+	}
+	defer tx.Rollback() // no-op after commit
+
+	err = lock(tx, id)
+	if err != nil {
+		return rev, err
+	}
+
+	lang, err := getLanguage(tx, id)
+	if err != nil {
+		return rev, err
+	}
+	change(&lang)
+	err = putLanguage(tx, lang)
+	if err != nil {
+		return rev, err
+	}
+
+	doc, err := json.Marshal(lang)
+	if err != nil {
+		return rev, err
+	}
+	rev = Revision{Summary: summary, Language: lang}
+	err = tx.QueryRow(`INSERT INTO language_revisions (lang_id, revision, summary, language)
+		SELECT $1, COALESCE(MAX(revision), 0) + 1, $2, $3::jsonb FROM language_revisions WHERE lang_id=$1
+		RETURNING revision, created_at;`, id, summary, string(doc)).Scan(&rev.Number, &rev.Created)
+	if err != nil {
+		return rev, err
+	}
+	return rev, tx.Commit()
+}
+
+// ListRevisions returns the language's revisions, oldest first, without their snapshots
+func (s *PostgresStore) ListRevisions(id string) ([]Revision, error) {
+	err := s.exists(id)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.DB.Query(`SELECT revision, summary, created_at FROM language_revisions WHERE lang_id=$1 ORDER BY revision;`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	revisions := []Revision{}
+	for rows.Next() {
+		var rev Revision
+		err = rows.Scan(&rev.Number, &rev.Summary, &rev.Created)
+		if err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, rev)
+	}
+	return revisions, rows.Err()
+}
+
+// GetRevision returns one of the language's revisions
+func (s *PostgresStore) GetRevision(id string, number int) (Revision, error) {
+	rev := Revision{Number: number}
+	var doc []byte
+	err := s.DB.QueryRow(`SELECT summary, created_at, language FROM language_revisions WHERE lang_id=$1 AND revision=$2;`, id, number).Scan(&rev.Summary, &rev.Created, &doc)
+	if err == sql.ErrNoRows {
+		err = s.exists(id)
+		if err == nil {
+			err = ErrRevisionNotFound
+		}
+		return rev, err
+	}
+	if err != nil {
+		return rev, err
+	}
+	err = json.Unmarshal(doc, &rev.Language)
+	return rev, err
+}
+
+// exists returns ErrNotFound if there is no language with the id
+func (s *PostgresStore) exists(id string) error {
+	var found bool
+	err := s.DB.QueryRow(`SELECT EXISTS (SELECT 1 FROM languages WHERE lang_id=$1);`, id).Scan(&found)
+	if err == nil && !found {
+		err = ErrNotFound
+	}
+	return err
+}
+
+// GetInventory returns the language's consonants, vowels, diphthongs, and custom segments
+func (s *PostgresStore) GetInventory(id string) (phonology.Inventory, error) {
+	inv := phonology.Inventory{LanguageID: id, Consonants: []phonology.Consonant{}, Vowels: []phonology.Vowel{}, Diphthongs: []phonology.Diphthong{}, Segments: []phonology.CustomSegment{}}
+	err := s.get(id, []string{"consonants", "vowels", "diphthongs", "segments"}, &inv.Consonants, &inv.Vowels, &inv.Diphthongs, &inv.Segments)
+	return inv, err
+}
+
+// PutConsonants replaces the language's consonants, removing those dropped from its hierarchies
+func (s *PostgresStore) PutConsonants(id string, cs []phonology.Consonant) error {
+	return s.update(id, func(lang *Language) { lang.SetConsonants(cs) })
+}
+
+// PutVowels replaces the language's vowels, removing those dropped from its hierarchies
+func (s *PostgresStore) PutVowels(id string, vs []phonology.Vowel) error {
+	return s.update(id, func(lang *Language) { lang.SetVowels(vs) })
+}
+
+// GetHierarchies returns the language's onset, nucleus, and coda hierarchies
+func (s *PostgresStore) GetHierarchies(id string) (Hierarchies, error) {
+	var h Hierarchies
+	err := s.get(id, []string{"onset_clusters", "nucleus_clusters", "coda_clusters"}, &h.Onset, &h.Nucleus, &h.Coda)
+	return h, err
+}
+
+// PutConsonantHierarchy replaces the language's onset or coda hierarchy
+func (s *PostgresStore) PutConsonantHierarchy(id string, h phonotactics.ConsonantHierarchy) error {
+	if h.Onset {
+		return s.put(id, "onset_clusters", h)
+	}
+	return s.put(id, "coda_clusters", h)
+}
+
+// PutNucleusHierarchy replaces the language's nucleus hierarchy
+func (s *PostgresStore) PutNucleusHierarchy(id string, h phonotactics.NucleusHierarchy) error {
+	return s.put(id, "nucleus_clusters", h)
+}
+
+// GetOptions returns the language's phonotactic options
+func (s *PostgresStore) GetOptions(id string) (phonotactics.PhonotacticOptions, error) {
+	var options phonotactics.PhonotacticOptions
+	err := s.get(id, []string{"options"}, &options)
+	return options, err
+}
+
+// PutOptions replaces the language's phonotactic options
+func (s *PostgresStore) PutOptions(id string, options phonotactics.PhonotacticOptions) error {
+	return s.put(id, "options", options)
+}
+
+// GetRules returns the language's phonotactic rules
+func (s *PostgresStore) GetRules(id string) (phonotactics.PhonotacticRules, error) {
+	var rules phonotactics.PhonotacticRules
+	err := s.get(id, []string{"rules"}, &rules)
+	return rules, err
+}
+
+// PutRules replaces the language's phonotactic rules
+func (s *PostgresStore) PutRules(id string, rules phonotactics.PhonotacticRules) error {
+	return s.put(id, "rules", rules)
+}
+
+// GetLexicon returns the language's lexicon
+func (s *PostgresStore) GetLexicon(id string) (lexicon.Lexicon, error) {
+	var lex lexicon.Lexicon
+	err := s.get(id, []string{"lexicon"}, &lex)
+	return lex, err
+}
+
+// PutLexicon replaces the language's lexicon
+func (s *PostgresStore) PutLexicon(id string, lex lexicon.Lexicon) error {
+	return s.put(id, "lexicon", lex)
+}
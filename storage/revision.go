@@ -0,0 +1,153 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/jheredos/langgen/phonology"
+)
+
+// ErrRevisionNotFound is returned by a LanguageStore when a language exists but has
+// no revision with the requested number
+var ErrRevisionNotFound = errors.New("revision not found")
+
+// Revision is a numbered snapshot of a whole language, taken each time it is revised.
+// Revisions are numbered from 1, and never change once written
+type Revision struct {
+	Number   int       `json:"number" bson:"number"`
+	Summary  string    `json:"summary" bson:"summary"`
+	Created  time.Time `json:"created" bson:"created"`
+	Language Language  `json:"language" bson:"language"`
+}
+
+// Parent links a forked language to the language and revision it was forked from
+type Parent struct {
+	ID       string `json:"id" bson:"id"`
+	Revision int    `json:"revision" bson:"revision"` // 0 if the parent had no revisions
+}
+
+// LanguageDiff lists what changed between two revisions of a language. Phonemes are
+// compared by their IPA, while every other part is only reported as changed or not
+type LanguageDiff struct {
+	From              int      `json:"from"`
+	To                int      `json:"to"`
+	ConsonantsAdded   []string `json:"consonantsAdded"`
+	ConsonantsRemoved []string `json:"consonantsRemoved"`
+	VowelsAdded       []string `json:"vowelsAdded"`
+	VowelsRemoved     []string `json:"vowelsRemoved"`
+	Changed           []string `json:"changed"` // names of the other parts that differ
+}
+
+// Diff compares two revisions of a language
+func Diff(from, to Revision) LanguageDiff {
+	d := LanguageDiff{From: from.Number, To: to.Number, Changed: []string{}}
+	a, b := from.Language, to.Language
+
+	d.ConsonantsAdded, d.ConsonantsRemoved = diffPhonemes(consonantIPA(a.Consonants), consonantIPA(b.Consonants))
+	d.VowelsAdded, d.VowelsRemoved = diffPhonemes(vowelIPA(a.Vowels), vowelIPA(b.Vowels))
+
+	parts := []struct {
+		name     string
+		from, to interface{}
+	}{
+		{"onsets", a.Onsets, b.Onsets},
+		{"diphthongs", a.Diphthongs, b.Diphthongs},
+		{"segments", a.Segments, b.Segments},
+		{"nuclei", a.Nuclei, b.Nuclei},
+		{"codas", a.Codas, b.Codas},
+		{"options", a.Options, b.Options},
+		{"rules", a.Rules, b.Rules},
+		{"allophonies", a.Allophonies, b.Allophonies},
+		{"orthography", a.Orthography, b.Orthography},
+		{"lexicon", a.Lexicon, b.Lexicon},
+		{"parent", a.Parent, b.Parent},
+	}
+	for _, part := range parts {
+		x, _ := json.Marshal(part.from)
+		y, _ := json.Marshal(part.to)
+		if string(x) != string(y) {
+			d.Changed = append(d.Changed, part.name)
+		}
+	}
+	return d
+}
+
+// diffPhonemes returns the phonemes in to but not from, and those in from but not to
+func diffPhonemes(from, to []string) (added, removed []string) {
+	added, removed = []string{}, []string{}
+	inFrom, inTo := map[string]bool{}, map[string]bool{}
+	for _, p := range from {
+		inFrom[p] = true
+	}
+	for _, p := range to {
+		inTo[p] = true
+		if !inFrom[p] {
+			added = append(added, p)
+		}
+	}
+	for _, p := range from {
+		if !inTo[p] {
+			removed = append(removed, p)
+		}
+	}
+	return added, removed
+}
+
+func consonantIPA(cs []phonology.Consonant) []string {
+	res := []string{}
+	for _, c := range cs {
+		res = append(res, c.ToIPA())
+	}
+	return res
+}
+
+func vowelIPA(vs []phonology.Vowel) []string {
+	res := []string{}
+	for _, v := range vs {
+		res = append(res, v.ToIPA())
+	}
+	return res
+}
+
+// findRevision returns the revision with the number from a language's revisions
+func findRevision(revisions []Revision, number int) (Revision, error) {
+	for _, rev := range revisions {
+		if rev.Number == number {
+			return rev, nil
+		}
+	}
+	return Revision{}, ErrRevisionNotFound
+}
+
+// withoutLanguages strips the snapshots from revisions, for listing them
+func withoutLanguages(revisions []Revision) []Revision {
+	res := []Revision{}
+	for _, rev := range revisions {
+		res = append(res, Revision{Number: rev.Number, Summary: rev.Summary, Created: rev.Created})
+	}
+	return res
+}
+
+// Revise applies the change to the language and records the result as a new revision
+func (s recordStore) Revise(id string, summary string, change func(*Language)) (Revision, error) {
+	return s.revise(id, summary, change)
+}
+
+// ListRevisions returns the language's revisions, oldest first, without their snapshots
+func (s recordStore) ListRevisions(id string) ([]Revision, error) {
+	revisions, err := s.revisions(id)
+	if err != nil {
+		return nil, err
+	}
+	return withoutLanguages(revisions), nil
+}
+
+// GetRevision returns one of the language's revisions
+func (s recordStore) GetRevision(id string, number int) (Revision, error) {
+	revisions, err := s.revisions(id)
+	if err != nil {
+		return Revision{}, err
+	}
+	return findRevision(revisions, number)
+}
@@ -0,0 +1,63 @@
+package storage
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jheredos/langgen/lexicon"
+	"github.com/jheredos/langgen/phonology"
+	"github.com/jheredos/langgen/phonotactics"
+)
+
+func TestDiff(t *testing.T) {
+	base := testLanguage(t, "lang")
+	for _, tc := range []struct {
+		name                                string
+		change                              func(*Language)
+		consonantsAdded, consonantsRemoved  []string
+		vowelsAdded, vowelsRemoved, changed []string
+	}{
+		{"nothing", func(l *Language) {}, nil, nil, nil, nil, nil},
+		{"phonemes", func(l *Language) {
+			k, err := phonology.NewConsonantFromIPA("k")
+			if err != nil {
+				t.Fatal(err)
+			}
+			l.Consonants = append(l.Consonants[1:], k)
+			l.Vowels = l.Vowels[:1]
+		}, []string{"k"}, []string{"p"}, nil, []string{"i"}, nil},
+		{"reordered phonemes", func(l *Language) {
+			l.Consonants = []phonology.Consonant{l.Consonants[2], l.Consonants[0], l.Consonants[1]}
+		}, nil, nil, nil, nil, nil},
+		{"other parts", func(l *Language) {
+			l.Codas = phonotactics.ConsonantHierarchy{}
+			l.Lexicon.Entries = append(l.Lexicon.Entries, lexicon.Entry{Form: "sati", Gloss: "water"})
+			l.Parent = &Parent{ID: "other", Revision: 2}
+		}, nil, nil, nil, nil, []string{"codas", "lexicon", "parent"}},
+	} {
+		to := testLanguage(t, "lang")
+		tc.change(&to)
+		d := Diff(Revision{Number: 1, Language: base}, Revision{Number: 2, Language: to})
+
+		if d.From != 1 || d.To != 2 {
+			t.Errorf("%s: diffed revisions %d to %d", tc.name, d.From, d.To)
+		}
+		for _, got := range []struct {
+			part      string
+			got, want []string
+		}{
+			{"consonants added", d.ConsonantsAdded, tc.consonantsAdded},
+			{"consonants removed", d.ConsonantsRemoved, tc.consonantsRemoved},
+			{"vowels added", d.VowelsAdded, tc.vowelsAdded},
+			{"vowels removed", d.VowelsRemoved, tc.vowelsRemoved},
+			{"changed", d.Changed, tc.changed},
+		} {
+			if got.want == nil {
+				got.want = []string{}
+			}
+			if !reflect.DeepEqual(got.got, got.want) {
+				t.Errorf("%s: %s %v, want %v", tc.name, got.part, got.got, got.want)
+			}
+		}
+	}
+}
@@ -0,0 +1,238 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/jheredos/langgen/lexicon"
+	"github.com/jheredos/langgen/phonology"
+	"github.com/jheredos/langgen/phonotactics"
+)
+
+// ErrNotFound is returned by a LanguageStore when it has no language with the requested id
+var ErrNotFound = errors.New("language not found")
+
+// LanguageStore persists the parts of each language by id. Puts and revisions create the
+// language if it does not exist yet, while gets return ErrNotFound
+type LanguageStore interface {
+	GetLanguage(id string) (Language, error)
+	PutLanguage(lang Language) error // replaces the whole language with lang.ID
+
+	// Revise applies the change to the current language and stores the result both as the
+	// current language and as its next revision, atomically
+	Revise(id string, summary string, change func(*Language)) (Revision, error)
+	ListRevisions(id string) ([]Revision, error) // oldest first, with Language left empty; GetRevision returns a snapshot
+	GetRevision(id string, number int) (Revision, error)
+
+	GetInventory(id string) (phonology.Inventory, error)
+	PutConsonants(id string, cs []phonology.Consonant) error
+	PutVowels(id string, vs []phonology.Vowel) error
+
+	GetHierarchies(id string) (Hierarchies, error)
+	PutConsonantHierarchy(id string, h phonotactics.ConsonantHierarchy) error // onset or coda, according to h.Onset
+	PutNucleusHierarchy(id string, h phonotactics.NucleusHierarchy) error
+
+	GetOptions(id string) (phonotactics.PhonotacticOptions, error)
+	PutOptions(id string, options phonotactics.PhonotacticOptions) error
+
+	GetRules(id string) (phonotactics.PhonotacticRules, error)
+	PutRules(id string, rules phonotactics.PhonotacticRules) error
+
+	GetLexicon(id string) (lexicon.Lexicon, error)
+	PutLexicon(id string, lex lexicon.Lexicon) error
+}
+
+// Hierarchies are the three hierarchies a language's phonotactic tree is built from
+type Hierarchies struct {
+	Onset   phonotactics.ConsonantHierarchy `json:"onset" bson:"onset"`
+	Nucleus phonotactics.NucleusHierarchy   `json:"nucleus" bson:"nucleus"`
+	Coda    phonotactics.ConsonantHierarchy `json:"coda" bson:"coda"`
+}
+
+// Language is everything a store keeps for one language, as a single record
+// for backends that store each language whole
+type Language struct {
+	ID          string                          `json:"id" bson:"_id"`
+	Consonants  []phonology.Consonant           `json:"consonants" bson:"consonants"`
+	Vowels      []phonology.Vowel               `json:"vowels" bson:"vowels"`
+	Diphthongs  []phonology.Diphthong           `json:"diphthongs" bson:"diphthongs"`
+	Segments    []phonology.CustomSegment       `json:"segments" bson:"segments"`
+	Onsets      phonotactics.ConsonantHierarchy `json:"onsets" bson:"onsets"`
+	Nuclei      phonotactics.NucleusHierarchy   `json:"nuclei" bson:"nuclei"`
+	Codas       phonotactics.ConsonantHierarchy `json:"codas" bson:"codas"`
+	Options     phonotactics.PhonotacticOptions `json:"options" bson:"options"`
+	Rules       phonotactics.PhonotacticRules   `json:"rules" bson:"rules"`
+	Allophonies []phonology.Allophony           `json:"allophonies" bson:"allophonies"`
+	Orthography phonology.Orthography           `json:"orthography" bson:"orthography"`
+	Lexicon     lexicon.Lexicon                 `json:"lexicon" bson:"lexicon"`
+	Parent      *Parent                         `json:"parent,omitempty" bson:"parent,omitempty"`
+}
+
+// Backend values for Config
+const (
+	PostgresBackend = "postgres"
+	MemoryBackend   = "memory"
+	FileBackend     = "file"
+	MongoBackend    = "mongo"
+)
+
+// Config selects and configures a LanguageStore backend. Backend defaults to
+// PostgresBackend, Dir, for FileBackend, defaults to "data", and MongoDatabase,
+// for MongoBackend, defaults to "langgen"
+type Config struct {
+	Backend       string
+	DatabaseURL   string
+	Dir           string
+	MongoURI      string
+	MongoDatabase string
+}
+
+// ConfigFromEnv reads a Config from the STORAGE, DATABASE_URL, STORAGE_DIR,
+// MONGODB_URI, and MONGODB_DATABASE environment variables
+func ConfigFromEnv() Config {
+	return Config{
+		Backend:       os.Getenv("STORAGE"),
+		DatabaseURL:   os.Getenv("DATABASE_URL"),
+		Dir:           os.Getenv("STORAGE_DIR"),
+		MongoURI:      os.Getenv("MONGODB_URI"),
+		MongoDatabase: os.Getenv("MONGODB_DATABASE"),
+	}
+}
+
+// Open creates the LanguageStore described by the config, along with a function
+// that releases whatever resources it holds
+func Open(config Config) (LanguageStore, func(), error) {
+	switch config.Backend {
+	case "", PostgresBackend:
+		store, err := NewPostgresStore(config.DatabaseURL)
+		if err != nil {
+			return nil, nil, err
+		}
+		return store, func() { store.Close() }, nil
+	case MemoryBackend:
+		return NewMemoryStore(), func() {}, nil
+	case FileBackend:
+		dir := config.Dir
+		if dir == "" {
+			dir = "data"
+		}
+		store, err := NewFileStore(dir)
+		if err != nil {
+			return nil, nil, err
+		}
+		return store, func() {}, nil
+	case MongoBackend:
+		database := config.MongoDatabase
+		if database == "" {
+			database = "langgen"
+		}
+		store, err := NewMongoStore(config.MongoURI, database)
+		if err != nil {
+			return nil, nil, err
+		}
+		return store, func() { store.Close() }, nil
+	}
+	return nil, nil, fmt.Errorf("unknown storage backend %q", config.Backend)
+}
+
+// records is what a backend that stores each language whole must provide
+// for recordStore to implement LanguageStore on top of it
+type records interface {
+	load(id string) (Language, error)
+	update(id string, change func(*Language)) error // creates the language if it does not exist
+	revise(id string, summary string, change func(*Language)) (Revision, error)
+	revisions(id string) ([]Revision, error) // with their snapshots, which ListRevisions strips; ErrNotFound if the language does not exist
+}
+
+// recordStore implements LanguageStore for backends that store each language as one record
+type recordStore struct {
+	records
+}
+
+// GetLanguage returns the whole language
+func (s recordStore) GetLanguage(id string) (Language, error) {
+	return s.load(id)
+}
+
+// PutLanguage replaces the whole language
+func (s recordStore) PutLanguage(lang Language) error {
+	return s.update(lang.ID, func(old *Language) { *old = lang })
+}
+
+// GetInventory returns the language's consonants, vowels, diphthongs, and custom segments
+func (s recordStore) GetInventory(id string) (phonology.Inventory, error) {
+	lang, err := s.load(id)
+	if err != nil {
+		return phonology.Inventory{}, err
+	}
+	return phonology.Inventory{LanguageID: id, Consonants: lang.Consonants, Vowels: lang.Vowels, Diphthongs: lang.Diphthongs, Segments: lang.Segments}, nil
+}
+
+// PutConsonants replaces the language's consonants, removing those dropped from its hierarchies
+func (s recordStore) PutConsonants(id string, cs []phonology.Consonant) error {
+	return s.update(id, func(lang *Language) { lang.SetConsonants(cs) })
+}
+
+// PutVowels replaces the language's vowels, removing those dropped from its hierarchies
+func (s recordStore) PutVowels(id string, vs []phonology.Vowel) error {
+	return s.update(id, func(lang *Language) { lang.SetVowels(vs) })
+}
+
+// GetHierarchies returns the language's onset, nucleus, and coda hierarchies
+func (s recordStore) GetHierarchies(id string) (Hierarchies, error) {
+	lang, err := s.load(id)
+	if err != nil {
+		return Hierarchies{}, err
+	}
+	return Hierarchies{Onset: lang.Onsets, Nucleus: lang.Nuclei, Coda: lang.Codas}, nil
+}
+
+// PutConsonantHierarchy replaces the language's onset or coda hierarchy
+func (s recordStore) PutConsonantHierarchy(id string, h phonotactics.ConsonantHierarchy) error {
+	return s.update(id, func(lang *Language) {
+		if h.Onset {
+			lang.Onsets = h
+		} else {
+			lang.Codas = h
+		}
+	})
+}
+
+// PutNucleusHierarchy replaces the language's nucleus hierarchy
+func (s recordStore) PutNucleusHierarchy(id string, h phonotactics.NucleusHierarchy) error {
+	return s.update(id, func(lang *Language) { lang.Nuclei = h })
+}
+
+// GetOptions returns the language's phonotactic options
+func (s recordStore) GetOptions(id string) (phonotactics.PhonotacticOptions, error) {
+	lang, err := s.load(id)
+	return lang.Options, err
+}
+
+// PutOptions replaces the language's phonotactic options
+func (s recordStore) PutOptions(id string, options phonotactics.PhonotacticOptions) error {
+	return s.update(id, func(lang *Language) { lang.Options = options })
+}
+
+// GetRules returns the language's phonotactic rules
+func (s recordStore) GetRules(id string) (phonotactics.PhonotacticRules, error) {
+	lang, err := s.load(id)
+	return lang.Rules, err
+}
+
+// PutRules replaces the language's phonotactic rules
+func (s recordStore) PutRules(id string, rules phonotactics.PhonotacticRules) error {
+	return s.update(id, func(lang *Language) { lang.Rules = rules })
+}
+
+// GetLexicon returns the language's lexicon
+func (s recordStore) GetLexicon(id string) (lexicon.Lexicon, error) {
+	lang, err := s.load(id)
+	return lang.Lexicon, err
+}
+
+// PutLexicon replaces the language's lexicon
+func (s recordStore) PutLexicon(id string, lex lexicon.Lexicon) error {
+	return s.update(id, func(lang *Language) { lang.Lexicon = lex })
+}
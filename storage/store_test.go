@@ -0,0 +1,354 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/jheredos/langgen/lexicon"
+	"github.com/jheredos/langgen/phonology"
+	"github.com/jheredos/langgen/phonotactics"
+)
+
+// testLanguage is a small language with something in each of the parts a store keeps
+func testLanguage(t *testing.T, id string) Language {
+	t.Helper()
+	lang := Language{ID: id}
+	for _, ipa := range []string{"p", "t", "s"} {
+		c, err := phonology.NewConsonantFromIPA(ipa)
+		if err != nil {
+			t.Fatal(err)
+		}
+		lang.Consonants = append(lang.Consonants, c)
+	}
+	for _, ipa := range []string{"a", "i"} {
+		v, err := phonology.NewVowelFromIPA(ipa)
+		if err != nil {
+			t.Fatal(err)
+		}
+		lang.Vowels = append(lang.Vowels, v)
+	}
+	lang.Onsets = phonotactics.ConsonantHierarchy{Onset: true, NoCluster: lang.Consonants}
+	lang.Nuclei = phonotactics.NucleusHierarchy{Nuclei: lang.Vowels, Monophthongs: lang.Vowels}
+	lang.Codas = phonotactics.ConsonantHierarchy{NoCluster: lang.Consonants[2:]}
+	lang.Options.MoraicCodas = true
+	lang.Lexicon = lexicon.Lexicon{Entries: []lexicon.Entry{{Form: "pata", Gloss: "stone"}}}
+	return lang
+}
+
+// sameJSON reports whether a and b encode to the same JSON, which is how a
+// language has to survive a store that does not keep Go values
+func sameJSON(t *testing.T, a, b interface{}) bool {
+	t.Helper()
+	x, err := json.Marshal(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	y, err := json.Marshal(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(x) == string(y)
+}
+
+// testStore checks the behaviour every LanguageStore shares, on a store with no languages yet
+func testStore(t *testing.T, store LanguageStore) {
+	t.Run("not found", func(t *testing.T) {
+		if _, err := store.GetLanguage("missing"); err != ErrNotFound {
+			t.Errorf("GetLanguage: got %v, want ErrNotFound", err)
+		}
+		if _, err := store.GetInventory("missing"); err != ErrNotFound {
+			t.Errorf("GetInventory: got %v, want ErrNotFound", err)
+		}
+		if _, err := store.GetHierarchies("missing"); err != ErrNotFound {
+			t.Errorf("GetHierarchies: got %v, want ErrNotFound", err)
+		}
+		if _, err := store.GetOptions("missing"); err != ErrNotFound {
+			t.Errorf("GetOptions: got %v, want ErrNotFound", err)
+		}
+		if _, err := store.GetRules("missing"); err != ErrNotFound {
+			t.Errorf("GetRules: got %v, want ErrNotFound", err)
+		}
+		if _, err := store.GetLexicon("missing"); err != ErrNotFound {
+			t.Errorf("GetLexicon: got %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("put and get", func(t *testing.T) {
+		lang := testLanguage(t, "whole")
+		if err := store.PutLanguage(lang); err != nil {
+			t.Fatal(err)
+		}
+		got, err := store.GetLanguage("whole")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !sameJSON(t, got, lang) {
+			t.Errorf("stored %+v, got back %+v", lang, got)
+		}
+	})
+
+	t.Run("parts", func(t *testing.T) {
+		lang := testLanguage(t, "parts")
+		// the first put creates the language
+		if err := store.PutConsonants("parts", lang.Consonants); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.PutVowels("parts", lang.Vowels); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.PutConsonantHierarchy("parts", lang.Onsets); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.PutConsonantHierarchy("parts", lang.Codas); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.PutNucleusHierarchy("parts", lang.Nuclei); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.PutOptions("parts", lang.Options); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.PutLexicon("parts", lang.Lexicon); err != nil {
+			t.Fatal(err)
+		}
+
+		inv, err := store.GetInventory("parts")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !sameJSON(t, inv.Consonants, lang.Consonants) || !sameJSON(t, inv.Vowels, lang.Vowels) {
+			t.Errorf("got inventory %+v", inv)
+		}
+		h, err := store.GetHierarchies("parts")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !sameJSON(t, h, Hierarchies{Onset: lang.Onsets, Nucleus: lang.Nuclei, Coda: lang.Codas}) {
+			t.Errorf("got hierarchies %+v", h)
+		}
+		options, err := store.GetOptions("parts")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !sameJSON(t, options, lang.Options) {
+			t.Errorf("got options %+v, want %+v", options, lang.Options)
+		}
+		lex, err := store.GetLexicon("parts")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !sameJSON(t, lex, lang.Lexicon) {
+			t.Errorf("got lexicon %+v", lex)
+		}
+	})
+
+	t.Run("revisions", func(t *testing.T) {
+		lang := testLanguage(t, "revised")
+		summaries := []string{"add consonants", "add vowels", "add lexicon"}
+		changes := []func(*Language){
+			func(l *Language) { l.Consonants = lang.Consonants },
+			func(l *Language) { l.Vowels = lang.Vowels },
+			func(l *Language) { l.Lexicon = lang.Lexicon },
+		}
+		for i, change := range changes {
+			rev, err := store.Revise("revised", summaries[i], change)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if rev.Number != i+1 {
+				t.Errorf("revision %d was numbered %d", i+1, rev.Number)
+			}
+		}
+
+		// the current language is the last revision, which the first revision created
+		got, err := store.GetLanguage("revised")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !sameJSON(t, got.Lexicon, lang.Lexicon) || !sameJSON(t, got.Consonants, lang.Consonants) {
+			t.Errorf("got %+v after revising", got)
+		}
+
+		revisions, err := store.ListRevisions("revised")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(revisions) != len(summaries) {
+			t.Fatalf("listed %d revisions, want %d", len(revisions), len(summaries))
+		}
+		for i, rev := range revisions {
+			if rev.Number != i+1 || rev.Summary != summaries[i] || rev.Created.IsZero() {
+				t.Errorf("listed revision %d as %+v", i+1, rev)
+			}
+			if rev.Language.ID != "" {
+				t.Errorf("listed revision %d with its snapshot", i+1)
+			}
+		}
+
+		// each revision keeps the language as it was then
+		rev, err := store.GetRevision("revised", 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if rev.Summary != "add vowels" || len(rev.Language.Vowels) != len(lang.Vowels) || len(rev.Language.Lexicon.Entries) != 0 {
+			t.Errorf("got revision 2 as %+v", rev)
+		}
+
+		if _, err := store.GetRevision("revised", 9); err != ErrRevisionNotFound {
+			t.Errorf("GetRevision of a missing revision: got %v, want ErrRevisionNotFound", err)
+		}
+		if _, err := store.GetRevision("missing", 1); err != ErrNotFound {
+			t.Errorf("GetRevision of a missing language: got %v, want ErrNotFound", err)
+		}
+		if _, err := store.ListRevisions("missing"); err != ErrNotFound {
+			t.Errorf("ListRevisions of a missing language: got %v, want ErrNotFound", err)
+		}
+	})
+}
+
+// testConcurrentPuts puts different parts of one language from many goroutines at
+// once, which loses some of them unless the store serializes its updates
+func testConcurrentPuts(t *testing.T, store LanguageStore) {
+	lang := testLanguage(t, "busy")
+	const n = 20
+	var wg sync.WaitGroup
+	errs := make(chan error, 3*n)
+	for i := 0; i < n; i++ {
+		wg.Add(3)
+		go func(i int) {
+			defer wg.Done()
+			errs <- store.PutConsonants("busy", lang.Consonants)
+		}(i)
+		go func(i int) {
+			defer wg.Done()
+			entries := []lexicon.Entry{{Form: "pata", Gloss: fmt.Sprint("stone ", i)}}
+			errs <- store.PutLexicon("busy", lexicon.Lexicon{Entries: entries})
+		}(i)
+		go func(i int) {
+			defer wg.Done()
+			_, err := store.GetInventory("busy")
+			if err == ErrNotFound {
+				err = nil
+			}
+			errs <- err
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	inv, err := store.GetInventory("busy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lex, err := store.GetLexicon("busy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sameJSON(t, inv.Consonants, lang.Consonants) || len(lex.Entries) != 1 {
+		t.Errorf("lost an update: got %+v and %+v", inv, lex)
+	}
+}
+
+// testConcurrentRevisions revises one language from many goroutines at once, which
+// must still number the revisions 1 to n without gaps or repeats
+func testConcurrentRevisions(t *testing.T, store LanguageStore) {
+	const n = 20
+	var wg sync.WaitGroup
+	errs := make(chan error, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, err := store.Revise("contested", fmt.Sprint("change ", i), func(l *Language) {
+				l.Lexicon.Entries = append(l.Lexicon.Entries, lexicon.Entry{Form: "pata", Gloss: fmt.Sprint(i)})
+			})
+			errs <- err
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	revisions, err := store.ListRevisions("contested")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, rev := range revisions {
+		if rev.Number != i+1 {
+			t.Fatalf("revisions numbered %+v", revisions)
+		}
+	}
+	lang, err := store.GetLanguage("contested")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(revisions) != n || len(lang.Lexicon.Entries) != n {
+		t.Errorf("got %d revisions and %d entries after %d revisions", len(revisions), len(lang.Lexicon.Entries), n)
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	testStore(t, NewMemoryStore())
+	testConcurrentPuts(t, NewMemoryStore())
+	testConcurrentRevisions(t, NewMemoryStore())
+}
+
+func TestFileStore(t *testing.T) {
+	store, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	testStore(t, store)
+	testConcurrentPuts(t, store)
+	testConcurrentRevisions(t, store)
+}
+
+func TestFileStoreReopen(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewFileStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lang := testLanguage(t, "kept")
+	if err := store.update("kept", func(stored *Language) { *stored = lang }); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened, err := NewFileStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := reopened.load("kept")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sameJSON(t, got, lang) {
+		t.Errorf("stored %+v, got back %+v", lang, got)
+	}
+}
+
+func TestFileStoreRejectsPathIDs(t *testing.T) {
+	store, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"../escape", "a/b", ""} {
+		if err := store.PutConsonants(id, nil); err == nil {
+			t.Errorf("put %q: expected an error", id)
+		}
+		if _, err := store.GetInventory(id); err == nil || err == ErrNotFound {
+			t.Errorf("get %q: got %v, want an invalid id error", id, err)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+// Package typology judges how natural an inventory is against what is known of the world's
+// languages: implicational universals like "voiced stops imply voiceless ones", the
+// usual size and shape of inventories, rare segments, and feature economy
+package typology
+
+import (
+	"sort"
+
+	"github.com/jheredos/langgen/phonology"
+)
+
+// Severity is how strongly a finding departs from the world's languages
+type Severity uint8
+
+// Severity values
+const (
+	UnspecifiedSV Severity = iota
+	InfoSV                 // unusual but well attested, like a language without /p/
+	WarningSV              // against a strong tendency, like /g/ without /k/
+	SevereSV               // against a near universal, like an inventory without vowels
+)
+
+var severityNames = []string{"", "info", "warning", "severe"}
+
+func (s Severity) String() string {
+	if int(s) < len(severityNames) {
+		return severityNames[s]
+	}
+	return ""
+}
+
+// Warning is one finding of the analysis. Check names the check that made it, and
+// Phonemes lists the IPA of the phonemes it concerns, if any
+type Warning struct {
+	Check    string   `json:"check"`
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+	Phonemes []string `json:"phonemes,omitempty"`
+}
+
+// Report is the analysis of an inventory. FeatureEconomy is Clements' index, the number
+// of segments per distinctive feature the inventory contrasts: inventories tend to
+// combine a few features fully rather than use many features for a segment or two.
+// Markedness is the mean markedness of the inventory's segments
+type Report struct {
+	Warnings       []Warning `json:"warnings"`
+	Segments       int       `json:"segments"`
+	Features       int       `json:"features"`
+	FeatureEconomy float64   `json:"featureEconomy"`
+	Markedness     float64   `json:"markedness"`
+}
+
+// check is one of the analyses run over an inventory
+type check func(inv phonology.Inventory) []Warning
+
+// checks are run in order, and their warnings sorted by severity, most severe first
+var checks = []check{
+	checkSize,
+	checkVowelCorners,
+	checkVoicing,
+	checkNasals,
+	checkSecondarySeries,
+	checkVowelContrasts,
+	checkObstruents,
+	checkRareSegments,
+}
+
+// Analyze runs every check over the inventory and measures its feature economy
+func Analyze(inv phonology.Inventory) Report {
+	r := Report{Warnings: []Warning{}}
+	for _, c := range checks {
+		r.Warnings = append(r.Warnings, c(inv)...)
+	}
+	sort.SliceStable(r.Warnings, func(i, j int) bool { return r.Warnings[i].Severity > r.Warnings[j].Severity })
+
+	segments := inv.Phonemes()
+	r.Segments = len(segments)
+	r.Features = len(contrastiveFeatures(segments))
+	if r.Features > 0 {
+		r.FeatureEconomy = float64(r.Segments) / float64(r.Features)
+	}
+	if r.Segments > 0 {
+		total := 0
+		for _, p := range segments {
+			total += phonology.Markedness(p)
+		}
+		r.Markedness = float64(total) / float64(r.Segments)
+	}
+	return r
+}
+
+// contrastiveFeatures returns the distinctive features that take both values among the segments
+func contrastiveFeatures(segments []phonology.Phoneme) []phonology.DistinctiveFeature {
+	values := map[phonology.DistinctiveFeature]map[bool]bool{}
+	for _, p := range segments {
+		for f, v := range phonology.DistinctiveFeatures(p) {
+			if values[f] == nil {
+				values[f] = map[bool]bool{}
+			}
+			values[f][v] = true
+		}
+	}
+
+	features := []phonology.DistinctiveFeature{}
+	for f, vs := range values {
+		if vs[true] && vs[false] {
+			features = append(features, f)
+		}
+	}
+	sort.Slice(features, func(i, j int) bool { return features[i] < features[j] })
+	return features
+}
@@ -0,0 +1,345 @@
+package typology
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jheredos/langgen/phonology"
+)
+
+// Inventory sizes beyond which warnings are given. The typical language has 20 to 25
+// consonants and 5 or 6 vowel qualities
+const (
+	fewestConsonants     = 6  // as in Rotokas
+	smallConsonants      = 10 // as in Hawaiian
+	largeConsonants      = 34
+	mostConsonants       = 60
+	fewestVowelQualities = 2
+	largeVowelQualities  = 12
+	mostVowelQualities   = 14
+	mostSegments         = 100 // as in Taa
+
+	rareMarkedness = 4 // a Markedness at which a segment is rare, like clicks and pharyngeals
+)
+
+// slashed writes phonemes as "/p/, /t/"
+func slashed(ipa ...string) string {
+	return "/" + strings.Join(ipa, "/, /") + "/"
+}
+
+// ipaSet returns the IPA of every consonant and vowel of the inventory
+func ipaSet(inv phonology.Inventory) map[string]bool {
+	set := map[string]bool{}
+	for _, c := range inv.Consonants {
+		set[c.ToIPA()] = true
+	}
+	for _, v := range inv.Vowels {
+		set[v.ToIPA()] = true
+	}
+	return set
+}
+
+// vowelQualities returns the number of vowel qualities, the vowels differing in height,
+// frontness, or rounding
+func vowelQualities(vs []phonology.Vowel) int {
+	qualities := map[phonology.Vowel]bool{}
+	for _, v := range vs {
+		qualities[phonology.Vowel{Height: v.Height, Frontness: v.Frontness, Rounding: v.Rounding}] = true
+	}
+	return len(qualities)
+}
+
+func isObstruent(c phonology.Consonant) bool {
+	return c.Manner == phonology.StopCM || c.Manner == phonology.AffricateCM || c.Manner == phonology.FricativeCM
+}
+
+func isPlain(c phonology.Consonant) bool {
+	return c.NonPulmonic <= phonology.PulmonicCNP && c.Coarticulation <= phonology.NoneCC &&
+		c.Aspirated != phonology.AspiratedCA && c.Geminate != phonology.GeminateCG
+}
+
+func isVoiced(c phonology.Consonant) bool {
+	return c.Voiced == phonology.VoicedCV || c.Voiced == phonology.PrevoicedCV
+}
+
+// hasCounterpart returns whether the inventory has a consonant of the opposite voicing at
+// the same place and manner as c, in any aspiration or phonation series, as Georgian pairs
+// /d/ with /tʰ/ and /tʼ/ but has no plain /t/
+func hasCounterpart(inv phonology.Inventory, c phonology.Consonant) bool {
+	for _, other := range inv.Consonants {
+		if isVoiced(other) != isVoiced(c) && other.Place == c.Place && other.Manner == c.Manner &&
+			other.Lateral == c.Lateral && other.Sibilant == c.Sibilant && other.Coarticulation == c.Coarticulation {
+			return true
+		}
+	}
+	return false
+}
+
+// checkSize compares the number of consonants, vowel qualities, and segments with the
+// world's languages
+func checkSize(inv phonology.Inventory) []Warning {
+	ws := []Warning{}
+	consonants, qualities := len(inv.Consonants), vowelQualities(inv.Vowels)
+
+	switch {
+	case consonants == 0:
+		ws = append(ws, Warning{"size", SevereSV, "the inventory has no consonants, which every known language has", nil})
+	case consonants < fewestConsonants:
+		ws = append(ws, Warning{"size", WarningSV, fmt.Sprintf("%d consonants is fewer than any known language has", consonants), nil})
+	case consonants < smallConsonants:
+		ws = append(ws, Warning{"size", InfoSV, fmt.Sprintf("%d consonants is a very small consonant inventory", consonants), nil})
+	case consonants > mostConsonants:
+		ws = append(ws, Warning{"size", WarningSV, fmt.Sprintf("%d consonants is more than almost any known language has", consonants), nil})
+	case consonants > largeConsonants:
+		ws = append(ws, Warning{"size", InfoSV, fmt.Sprintf("%d consonants is a large consonant inventory", consonants), nil})
+	}
+
+	switch {
+	case qualities == 0 && len(inv.Diphthongs) == 0:
+		ws = append(ws, Warning{"size", SevereSV, "the inventory has no vowels, which every known language has", nil})
+	case qualities > 0 && qualities < fewestVowelQualities:
+		ws = append(ws, Warning{"size", WarningSV, "a single vowel quality is claimed for only a few languages, and disputed", nil})
+	case qualities > mostVowelQualities:
+		ws = append(ws, Warning{"size", WarningSV, fmt.Sprintf("%d vowel qualities is more than almost any known language has", qualities), nil})
+	case qualities > largeVowelQualities:
+		ws = append(ws, Warning{"size", InfoSV, fmt.Sprintf("%d vowel qualities is a large vowel inventory", qualities), nil})
+	}
+
+	if segments := len(inv.Phonemes()); segments > mostSegments {
+		ws = append(ws, Warning{"size", WarningSV, fmt.Sprintf("%d segments is an unusually large inventory", segments), nil})
+	}
+	return ws
+}
+
+// checkVowelCorners checks for the corners of the vowel space, /i a u/ or vowels near them,
+// which nearly every language has
+func checkVowelCorners(inv phonology.Inventory) []Warning {
+	if len(inv.Vowels) == 0 {
+		return nil
+	}
+	var high, low, back bool
+	for _, v := range inv.Vowels {
+		isClose := v.Height == phonology.CloseVH || v.Height == phonology.NearCloseVH
+		switch {
+		case isClose && v.Frontness == phonology.FrontVF && v.Rounding != phonology.RoundedVR:
+			high = true
+		case isClose && v.Frontness == phonology.BackVF:
+			back = true
+		case v.Height == phonology.OpenVH || v.Height == phonology.NearOpenVH:
+			low = true
+		}
+	}
+
+	ws := []Warning{}
+	if !high {
+		ws = append(ws, Warning{"vowel-corners", WarningSV, "there is no close front vowel like /i/, a corner of nearly every vowel system", []string{"i"}})
+	}
+	if !low {
+		ws = append(ws, Warning{"vowel-corners", WarningSV, "there is no open vowel like /a/, a corner of nearly every vowel system", []string{"a"}})
+	}
+	if !back {
+		ws = append(ws, Warning{"vowel-corners", WarningSV, "there is no close back vowel like /u/, a corner of nearly every vowel system", []string{"u"}})
+	}
+	return ws
+}
+
+// checkVoicing checks that voiced obstruents have voiceless counterparts, and voiceless
+// sonorants voiced ones, as in nearly every language. A voiced stop series that lacks a
+// stop the voiceless series has is noted as a gap. Any aspirated or ejective consonant at
+// the same place and manner counts as a counterpart
+func checkVoicing(inv phonology.Inventory) []Warning {
+	ws := []Warning{}
+
+	voicedStops, voicelessStops := 0, 0
+	for _, c := range inv.Consonants {
+		if c.Manner == phonology.StopCM {
+			if isVoiced(c) {
+				voicedStops++
+			} else {
+				voicelessStops++
+			}
+		}
+	}
+	if voicedStops > 0 && voicelessStops == 0 {
+		ws = append(ws, Warning{"voicing", SevereSV, "there are voiced stops but no voiceless ones, which nearly every language has", nil})
+	}
+
+	for _, c := range inv.Consonants {
+		counterpart := c
+		if isVoiced(c) {
+			counterpart.Voiced = phonology.UnvoicedCV
+		} else {
+			counterpart.Voiced = phonology.VoicedCV
+		}
+		if hasCounterpart(inv, c) || !isPlain(c) {
+			continue
+		}
+		pair := []string{c.ToIPA(), counterpart.ToIPA()}
+
+		switch {
+		case isObstruent(c) && isVoiced(c):
+			if c.Manner == phonology.StopCM && voicelessStops == 0 {
+				continue // already reported for the whole series
+			}
+			severity := WarningSV
+			if c.Manner != phonology.StopCM || c.Place == phonology.BilabialCP {
+				severity = InfoSV // like /b/ and /d͡ʒ/ without /p/ and /t͡ʃ/ in Arabic, or /ʁ/ without /χ/ in French
+			}
+			ws = append(ws, Warning{"voicing", severity, fmt.Sprintf("has %s but not %s", slashed(pair[0]), slashed(pair[1])), pair})
+		case c.Manner == phonology.StopCM && voicedStops > 1 && c.Place <= phonology.VelarCP:
+			ws = append(ws, Warning{"voicing", InfoSV, fmt.Sprintf("the voiced stops have a gap: %s but no %s", slashed(pair[0]), slashed(pair[1])), pair})
+		case !isObstruent(c) && c.Voiced == phonology.UnvoicedCV && c.Manner != phonology.ClickCM:
+			ws = append(ws, Warning{"voicing", WarningSV, fmt.Sprintf("has voiceless %s but not voiced %s", slashed(pair[0]), slashed(pair[1])), pair})
+		}
+	}
+	return ws
+}
+
+// checkNasals checks for nasals, which nearly every language has, and that nasals at
+// other places imply a coronal nasal like /n/
+func checkNasals(inv phonology.Inventory) []Warning {
+	if len(inv.Consonants) == 0 {
+		return nil
+	}
+	nasals, coronal := 0, false
+	others := []string{}
+	for _, c := range inv.Consonants {
+		if c.Manner != phonology.NasalCM {
+			continue
+		}
+		nasals++
+		switch c.Place {
+		case phonology.DentalCP, phonology.AlveolarCP:
+			coronal = true
+		case phonology.BilabialCP, phonology.LabioDentalCP:
+		default:
+			others = append(others, c.ToIPA())
+		}
+	}
+
+	switch {
+	case nasals == 0:
+		return []Warning{{"nasals", WarningSV, "there are no nasal consonants, which only a handful of languages, like Rotokas, lack", nil}}
+	case !coronal && len(others) > 0:
+		return []Warning{{"nasals", WarningSV, fmt.Sprintf("has %s but no coronal nasal like /n/", slashed(others...)), others}}
+	}
+	return nil
+}
+
+// checkSecondarySeries checks that aspirated, ejective, implosive, coarticulated, and geminate
+// consonants have plain counterparts, since marked series usually build on a plain one
+func checkSecondarySeries(inv phonology.Inventory) []Warning {
+	ws := []Warning{}
+	has := ipaSet(inv)
+	for _, c := range inv.Consonants {
+		if c.Manner == phonology.ClickCM || c.NonPulmonic == phonology.VelaricCNP {
+			continue
+		}
+		plain := c
+		plain.Aspirated = phonology.UnaspiratedCA
+		plain.NonPulmonic = phonology.PulmonicCNP
+		plain.Coarticulation = phonology.NoneCC
+		plain.Geminate = phonology.SingletonCG
+		if plain == c || has[plain.ToIPA()] {
+			continue
+		}
+
+		pair := []string{c.ToIPA(), plain.ToIPA()}
+		severity := InfoSV
+		if c.Geminate == phonology.GeminateCG {
+			severity = WarningSV // geminates nearly always contrast with singletons
+		}
+		ws = append(ws, Warning{"secondary-series", severity, fmt.Sprintf("has %s but not plain %s", slashed(pair[0]), slashed(pair[1])), pair})
+	}
+	return ws
+}
+
+// checkVowelContrasts checks that marked vowels have unmarked counterparts: nasal vowels
+// oral ones, long vowels short ones, front rounded vowels front unrounded ones, and so on
+func checkVowelContrasts(inv phonology.Inventory) []Warning {
+	ws := []Warning{}
+	has := ipaSet(inv)
+	for _, v := range inv.Vowels {
+		type contrast struct {
+			marked   bool
+			plain    phonology.Vowel
+			severity Severity
+			name     string
+		}
+		oral, short, unrounded, modal, plain := v, v, v, v, v
+		oral.Nasal = phonology.OralVN
+		short.Length = phonology.ShortVL
+		unrounded.Rounding = phonology.UnroundedVR
+		modal.Phonation = phonology.ModalVP
+		plain.Rhoticity = phonology.NonrhoticVRH
+		plain.Pharyngealization = phonology.PlainVPH
+
+		for _, c := range []contrast{
+			{v.Nasal == phonology.NasalVN, oral, WarningSV, "oral"},
+			{v.Length > phonology.ShortVL, short, WarningSV, "short"},
+			{v.Frontness == phonology.FrontVF && v.Rounding == phonology.RoundedVR, unrounded, WarningSV, "unrounded"},
+			{v.Phonation > phonology.ModalVP, modal, InfoSV, "modal"},
+			{v.Rhoticity == phonology.RhoticVRH || v.Pharyngealization == phonology.PharyngealizedVPH, plain, InfoSV, "plain"},
+		} {
+			if !c.marked || has[c.plain.ToIPA()] {
+				continue
+			}
+			pair := []string{v.ToIPA(), c.plain.ToIPA()}
+			ws = append(ws, Warning{"vowel-contrasts", c.severity, fmt.Sprintf("has %s but not %s %s", slashed(pair[0]), c.name, slashed(pair[1])), pair})
+		}
+	}
+	return ws
+}
+
+// checkObstruents checks for stops, which every known language has, coronal stops, which
+// nearly every one has, and a sibilant among the fricatives
+func checkObstruents(inv phonology.Inventory) []Warning {
+	if len(inv.Consonants) == 0 {
+		return nil
+	}
+	var stops, coronalStops, fricatives, sibilants int
+	for _, c := range inv.Consonants {
+		switch {
+		case c.Manner == phonology.StopCM:
+			stops++
+			if c.Place >= phonology.DentalCP && c.Place <= phonology.RetroflexCP {
+				coronalStops++
+			}
+		case c.Manner == phonology.FricativeCM && c.Place != phonology.GlottalCP:
+			fricatives++
+			if c.Sibilant == phonology.SibilantCS {
+				sibilants++
+			}
+		}
+	}
+
+	ws := []Warning{}
+	switch {
+	case stops == 0:
+		ws = append(ws, Warning{"obstruents", SevereSV, "there are no stops, which every known language has", nil})
+	case coronalStops == 0:
+		ws = append(ws, Warning{"obstruents", InfoSV, "there is no coronal stop like /t/, as in Hawaiian", []string{"t"}})
+	}
+	if fricatives > 0 && sibilants == 0 {
+		ws = append(ws, Warning{"obstruents", InfoSV, "there are fricatives but no sibilant like /s/, which most languages with fricatives have", []string{"s"}})
+	}
+	return ws
+}
+
+// checkRareSegments lists the segments that are rare across the world's languages
+func checkRareSegments(inv phonology.Inventory) []Warning {
+	rare := []string{}
+	for _, p := range inv.Phonemes() {
+		if phonology.Markedness(p) >= rareMarkedness {
+			rare = append(rare, p.ToIPA())
+		}
+	}
+	if len(rare) == 0 {
+		return nil
+	}
+	verb := "are"
+	if len(rare) == 1 {
+		verb = "is"
+	}
+	return []Warning{{"rare-segments", InfoSV, fmt.Sprintf("%s %s rare across the world's languages", slashed(rare...), verb), rare}}
+}
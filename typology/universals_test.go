@@ -0,0 +1,65 @@
+package typology
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jheredos/langgen/phonology"
+	"github.com/jheredos/langgen/presets"
+)
+
+func consonants(t *testing.T, ipa ...string) phonology.Inventory {
+	t.Helper()
+	inv := phonology.Inventory{}
+	for _, s := range ipa {
+		c, err := phonology.NewConsonantFromIPA(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		inv.Consonants = append(inv.Consonants, c)
+	}
+	return inv
+}
+
+func TestCheckVoicing(t *testing.T) {
+	for _, tc := range []struct {
+		consonants []string
+		want       []string // the messages of the warnings, in order
+	}{
+		{[]string{"p", "b", "t", "d", "k", "g"}, nil},
+		{[]string{"p", "t", "d", "k", "g", "q"}, []string{"the voiced stops have a gap: /p/ but no /b/"}},
+		{[]string{"p", "b", "t", "k", "g"}, []string{"the voiced stops have a gap: /t/ but no /d/"}},
+		{[]string{"p", "k", "d"}, []string{"has /d/ but not /t/"}},
+		// aspirated and ejective stops are counterparts to voiced ones, as in Georgian
+		{[]string{"b", "pʰ", "pʼ", "d", "tʰ", "tʼ", "g", "kʰ", "kʼ"}, nil},
+		{[]string{"d", "tʼ", "g", "kʰ"}, nil},
+		{[]string{"d͡z", "t͡sʰ", "d͡ʒ", "t͡ʃʼ"}, nil},
+		{[]string{"p", "t", "k", "z"}, []string{"has /z/ but not /s/"}},
+		{[]string{"b", "d"}, []string{"there are voiced stops but no voiceless ones, which nearly every language has"}},
+		{[]string{"p", "t", "k", "m̥"}, []string{"has voiceless /m̥/ but not voiced /m/"}},
+	} {
+		got := []string{}
+		for _, w := range checkVoicing(consonants(t, tc.consonants...)) {
+			got = append(got, w.Message)
+		}
+		if strings.Join(got, "; ") != strings.Join(tc.want, "; ") {
+			t.Errorf("%s: got %q, want %q", slashed(tc.consonants...), got, tc.want)
+		}
+	}
+}
+
+// every shipped preset is modelled on a real language, so none should break a strong
+// tendency of voicing
+func TestPresetVoicing(t *testing.T) {
+	for _, p := range presets.All() {
+		lang, err := p.Language(p.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, w := range checkVoicing(lang.Inventory()) {
+			if w.Severity >= WarningSV {
+				t.Errorf("%s: %s", p.ID, w.Message)
+			}
+		}
+	}
+}